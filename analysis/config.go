@@ -0,0 +1,51 @@
+package analysis
+
+import "policy-backend/llm"
+
+// Config 分析模块配置
+type Config struct {
+	MaxIntelligences int `koanf:"analysis_max_intelligences"`  // 单份报告最多引用的情报数
+	MaxDocumentChars int `koanf:"analysis_max_document_chars"` // 每篇情报发送给大模型的正文最大字符数
+	MaxOutputTokens  int `koanf:"analysis_max_output_tokens"`  // 报告正文的最大输出 token 数
+
+	// 模型计价：每生成一次报告的费用，生成失败不扣费
+	PriceBasic    int64 `koanf:"analysis_price_basic"`
+	PriceAdvanced int64 `koanf:"analysis_price_advanced"`
+	PricePro      int64 `koanf:"analysis_price_pro"`
+}
+
+// DefaultConfig 返回分析模块的默认配置
+func DefaultConfig() Config {
+	return Config{
+		MaxIntelligences: 20,
+		MaxDocumentChars: 4000,
+		MaxOutputTokens:  4000,
+
+		PriceBasic:    10,
+		PriceAdvanced: 30,
+		PricePro:      60,
+	}
+}
+
+// ModelPrice 某个模型档位生成一次报告的价格
+type ModelPrice struct {
+	Model string `json:"model"`
+	Price int64  `json:"price"`
+}
+
+// Price 返回模型档位的价格，未知档位按 basic 计价
+func (c Config) Price(model string) ModelPrice {
+	switch model {
+	case llm.TierAdvanced:
+		return ModelPrice{Model: llm.TierAdvanced, Price: c.PriceAdvanced}
+	case llm.TierPro:
+		return ModelPrice{Model: llm.TierPro, Price: c.PricePro}
+	default:
+		return ModelPrice{Model: llm.TierBasic, Price: c.PriceBasic}
+	}
+}
+
+// Pricing 返回全部模型档位的价格表
+func (c Config) Pricing() []ModelPrice {
+	return []ModelPrice{c.Price(llm.TierBasic), c.Price(llm.TierAdvanced), c.Price(llm.TierPro)}
+}
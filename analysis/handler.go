@@ -0,0 +1,517 @@
+package analysis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"policy-backend/intelligence"
+	"policy-backend/llm"
+	"policy-backend/team"
+	"policy-backend/user"
+	"policy-backend/utils"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultReportPageSize 报告列表默认每页数量
+const defaultReportPageSize = 20
+
+// Handler 分析处理器
+type Handler struct {
+	db            *gorm.DB
+	cfg           Config
+	pointsService *user.PointsTransactionService
+	llm           *llm.Client
+	jobs          chan reportJob
+}
+
+// NewHandler 创建新的分析处理器，并启动后台生成工作池
+// llmClient 按报告的 model 档位调用大模型，档位未配置时拒绝生成
+func NewHandler(db *gorm.DB, pointsService *user.PointsTransactionService, cfg *Config, llmClient *llm.Client) *Handler {
+	h := &Handler{
+		db:            db,
+		cfg:           *cfg,
+		pointsService: pointsService,
+		llm:           llmClient,
+		jobs:          make(chan reportJob, reportQueueSize),
+	}
+	h.startWorkers()
+	return h
+}
+
+// CreateSummary 生成综述报告
+// POST /api/analysis/summary
+// 立即创建报告并返回，生成在后台执行；生成成功后才结算积分，失败时全额退回
+func (h *Handler) CreateSummary(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var req SummaryRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid parameters")
+	}
+	if err := utils.ValidateRequest(c, &req); err != nil {
+		return err
+	}
+	if req.Model == "" {
+		req.Model = llm.TierBasic
+	}
+	if !h.modelAvailable(req.Model) {
+		return utils.Fail(c, http.StatusServiceUnavailable, "Model tier is not available")
+	}
+
+	ids := uniqueIDs(req.IntelligenceIDs)
+	if len(ids) > h.cfg.MaxIntelligences {
+		return utils.Fail(c, http.StatusBadRequest,
+			fmt.Sprintf("Too many intelligences, at most %d per report", h.cfg.MaxIntelligences))
+	}
+	docs, err := h.loadSources(ids)
+	if err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to fetch intelligences")
+	}
+	if missing := missingIDs(ids, docs); len(missing) > 0 {
+		return utils.FailWithData(c, http.StatusNotFound, "Intelligence not found", map[string]interface{}{
+			"missing_ids": missing,
+		})
+	}
+
+	inputs, err := json.Marshal(ids)
+	if err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to create report")
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = defaultTitle(docs)
+	}
+	report := Report{
+		UserID:          currentUser.ID,
+		Title:           title,
+		IntelligenceIDs: inputs,
+		PromptTemplate:  req.PromptTemplate,
+		Model:           req.Model,
+		Status:          ReportStatusQueued,
+		Citations:       json.RawMessage("[]"),
+		Generation:      1,
+	}
+	if err := h.db.Create(&report).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to create report")
+	}
+
+	if err := h.startGeneration(&report); err != nil {
+		// 未能冻结积分的报告不保留
+		if errors.Is(err, errReservePoints) {
+			h.db.Unscoped().Delete(&report)
+		}
+		return failStartGeneration(c, err)
+	}
+
+	return utils.Success(c, report)
+}
+
+// ListReports 分页获取报告列表
+// GET /api/analysis/reports
+// 指定 team_id 时返回共享到该团队的报告（须为团队成员），否则返回自己创建的报告
+func (h *Handler) ListReports(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var query ReportQuery
+	if err := c.Bind(&query); err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid parameters")
+	}
+	if err := utils.ValidateRequest(c, &query); err != nil {
+		return err
+	}
+	query.normalize(defaultReportPageSize)
+
+	db := h.db.Model(&Report{})
+	if query.TeamID != 0 {
+		if !h.isTeamMember(query.TeamID, currentUser.ID) {
+			return utils.Fail(c, http.StatusForbidden, "You are not a member of this team")
+		}
+		db = db.Where("id IN (?)", h.db.Model(&intelligence.Permission{}).
+			Select("resource_id").
+			Where("resource_type = ? AND subject_type = ? AND subject_id = ?",
+				intelligence.ResourceTypeReport, intelligence.SubjectTypeTeam, query.TeamID))
+	} else {
+		db = db.Where("user_id = ?", currentUser.ID)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	var total int64
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to count reports")
+	}
+
+	// 列表不返回正文，通过详情接口获取
+	var reports []Report
+	if err := db.Omit("content").
+		Order("created_at DESC, id DESC").
+		Offset(query.offset()).
+		Limit(query.PageSize).
+		Find(&reports).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to get reports")
+	}
+
+	return utils.Success(c, map[string]interface{}{
+		"total":     total,
+		"page":      query.Page,
+		"page_size": query.PageSize,
+		"count":     len(reports),
+		"reports":   reports,
+	})
+}
+
+// GetReport 获取报告详情，包含来源情报（标注是否被正文引用）与已共享的团队
+// GET /api/analysis/reports/:id
+// 创建者与报告所共享团队的成员可以查看
+func (h *Handler) GetReport(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var report Report
+	if err := h.db.First(&report, "id = ?", c.Param("id")).Error; err != nil ||
+		(report.UserID != currentUser.ID && !h.sharedWithUser(report.ID, currentUser.ID)) {
+		return utils.Fail(c, http.StatusNotFound, "Report not found")
+	}
+
+	docs, err := h.loadSources(report.InputIDs())
+	if err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to fetch intelligences")
+	}
+	cited := make(map[uint]bool)
+	for _, id := range report.CitedIDs() {
+		cited[id] = true
+	}
+	sources := make([]ReportSource, 0, len(docs))
+	for _, doc := range docs {
+		sources = append(sources, ReportSource{
+			ID:          doc.ID,
+			Title:       doc.Title,
+			Source:      doc.Source,
+			URL:         doc.URL,
+			PublishDate: doc.PublishDate,
+			Cited:       cited[doc.ID],
+		})
+	}
+
+	teamIDs, err := h.sharedTeams(report.ID)
+	if err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to fetch shared teams")
+	}
+
+	return utils.Success(c, ReportDetail{Report: report, Sources: sources, TeamIDs: teamIDs})
+}
+
+// RegenerateReport 重新生成报告，可更换提示词模板与模型档位
+// POST /api/analysis/reports/:id/regenerate
+// 按新档位重新计费；新结果生成前保留原正文，生成失败时原正文不变
+func (h *Handler) RegenerateReport(c echo.Context) error {
+	report, err := h.findUserReport(c)
+	if report == nil {
+		return err
+	}
+
+	var req RegenerateRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid parameters")
+	}
+	if err := utils.ValidateRequest(c, &req); err != nil {
+		return err
+	}
+	if !report.IsFinished() {
+		return utils.Fail(c, http.StatusConflict, "Report is still being generated")
+	}
+
+	model := report.Model
+	if req.Model != "" {
+		model = req.Model
+	}
+	if !h.modelAvailable(model) {
+		return utils.Fail(c, http.StatusServiceUnavailable, "Model tier is not available")
+	}
+	promptTemplate := report.PromptTemplate
+	if req.PromptTemplate != nil {
+		promptTemplate = *req.PromptTemplate
+	}
+
+	// 并发的重新生成请求只有一个能从已结束状态转入排队
+	result := h.db.Model(&Report{}).
+		Where("id = ? AND status IN ?", report.ID, []string{ReportStatusDone, ReportStatusFailed}).
+		Updates(map[string]interface{}{
+			"status":          ReportStatusQueued,
+			"model":           model,
+			"prompt_template": promptTemplate,
+			"generation":      gorm.Expr("generation + 1"),
+		})
+	if result.Error != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to update report")
+	}
+	if result.RowsAffected == 0 {
+		return utils.Fail(c, http.StatusConflict, "Report is still being generated")
+	}
+	previous := *report
+	if err := h.db.First(report, report.ID).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to fetch report")
+	}
+
+	if err := h.startGeneration(report); err != nil {
+		// 未能冻结积分时恢复原报告，不计入生成次数
+		if errors.Is(err, errReservePoints) {
+			h.updateReport(report.ID, map[string]interface{}{
+				"status":          previous.Status,
+				"error":           previous.Error,
+				"model":           previous.Model,
+				"prompt_template": previous.PromptTemplate,
+				"generation":      previous.Generation,
+			})
+		}
+		return failStartGeneration(c, err)
+	}
+
+	return utils.Success(c, report)
+}
+
+// DeleteReport 删除报告及其团队共享记录
+// DELETE /api/analysis/reports/:id
+func (h *Handler) DeleteReport(c echo.Context) error {
+	report, err := h.findUserReport(c)
+	if report == nil {
+		return err
+	}
+	if report.Status == ReportStatusRunning {
+		return utils.Fail(c, http.StatusConflict, "Report is still being generated")
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("resource_type = ? AND resource_id = ?", intelligence.ResourceTypeReport, report.ID).
+			Delete(&intelligence.Permission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(report).Error
+	})
+	if err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to delete report")
+	}
+
+	return utils.Success(c, nil)
+}
+
+// ShareReport 共享报告到团队，团队成员可查看
+// POST /api/analysis/reports/:id/share
+// 只有报告创建者可以共享，且须为目标团队的成员
+func (h *Handler) ShareReport(c echo.Context) error {
+	report, err := h.findUserReport(c)
+	if report == nil {
+		return err
+	}
+
+	var req ShareRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid parameters")
+	}
+	if err := utils.ValidateRequest(c, &req); err != nil {
+		return err
+	}
+
+	if err := team.CheckContributor(h.db, req.TeamID, report.UserID); err != nil {
+		switch {
+		case errors.Is(err, team.ErrTeamNotFound):
+			return utils.Fail(c, http.StatusNotFound, "Team not found")
+		case errors.Is(err, team.ErrNotTeamMember):
+			return utils.Fail(c, http.StatusForbidden, "You are not a member of this team")
+		case errors.Is(err, team.ErrTeamRoleRejected):
+			return utils.Fail(c, http.StatusForbidden, "Your team role cannot share reports")
+		default:
+			return utils.Error(c, http.StatusInternalServerError, "Failed to check team membership")
+		}
+	}
+
+	permission := intelligence.Permission{
+		ResourceType: intelligence.ResourceTypeReport,
+		ResourceID:   report.ID,
+		SubjectType:  intelligence.SubjectTypeTeam,
+		SubjectID:    req.TeamID,
+		Action:       intelligence.PermissionView,
+		GrantedBy:    report.UserID,
+	}
+	if err := h.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&permission).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to share report")
+	}
+
+	teamIDs, err := h.sharedTeams(report.ID)
+	if err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to fetch shared teams")
+	}
+	return utils.Success(c, map[string]interface{}{
+		"report_id": report.ID,
+		"team_ids":  teamIDs,
+	})
+}
+
+// UnshareReport 取消共享到团队
+// DELETE /api/analysis/reports/:id/share/:team_id
+func (h *Handler) UnshareReport(c echo.Context) error {
+	report, err := h.findUserReport(c)
+	if report == nil {
+		return err
+	}
+
+	teamID, err := strconv.ParseUint(c.Param("team_id"), 10, 64)
+	if err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid team ID")
+	}
+
+	if err := h.db.Where("resource_type = ? AND resource_id = ? AND subject_type = ? AND subject_id = ?",
+		intelligence.ResourceTypeReport, report.ID, intelligence.SubjectTypeTeam, teamID).
+		Delete(&intelligence.Permission{}).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to unshare report")
+	}
+
+	teamIDs, err := h.sharedTeams(report.ID)
+	if err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to fetch shared teams")
+	}
+	return utils.Success(c, map[string]interface{}{
+		"report_id": report.ID,
+		"team_ids":  teamIDs,
+	})
+}
+
+// GetPricing 获取各模型档位生成一次报告的价格及可用状态
+// GET /api/analysis/pricing
+func (h *Handler) GetPricing(c echo.Context) error {
+	models := make([]map[string]interface{}, 0, len(llm.Tiers))
+	for _, price := range h.cfg.Pricing() {
+		models = append(models, map[string]interface{}{
+			"model":     price.Model,
+			"price":     price.Price,
+			"available": h.modelAvailable(price.Model),
+		})
+	}
+	return utils.Success(c, map[string]interface{}{
+		"max_intelligences": h.cfg.MaxIntelligences,
+		"models":            models,
+	})
+}
+
+// findUserReport 查找属于当前用户的报告
+func (h *Handler) findUserReport(c echo.Context) (*Report, error) {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return nil, utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var report Report
+	if err := h.db.Where("id = ? AND user_id = ?", c.Param("id"), currentUser.ID).First(&report).Error; err != nil {
+		return nil, utils.Fail(c, http.StatusNotFound, "Report not found")
+	}
+	return &report, nil
+}
+
+// modelAvailable 模型档位是否已配置大模型
+func (h *Handler) modelAvailable(model string) bool {
+	return h.llm != nil && h.llm.Configured(model)
+}
+
+// isTeamMember 用户是否为团队成员
+func (h *Handler) isTeamMember(teamID, userID uint) bool {
+	var count int64
+	h.db.Model(&user.TeamMember{}).Where("team_id = ? AND user_id = ?", teamID, userID).Count(&count)
+	return count > 0
+}
+
+// sharedWithUser 报告是否共享到了用户所在的任一团队
+func (h *Handler) sharedWithUser(reportID, userID uint) bool {
+	var count int64
+	h.db.Model(&intelligence.Permission{}).
+		Where("resource_type = ? AND resource_id = ? AND subject_type = ?",
+			intelligence.ResourceTypeReport, reportID, intelligence.SubjectTypeTeam).
+		Where("subject_id IN (?)", h.db.Model(&user.TeamMember{}).Select("team_id").Where("user_id = ?", userID)).
+		Count(&count)
+	return count > 0
+}
+
+// sharedTeams 报告已共享的团队ID
+func (h *Handler) sharedTeams(reportID uint) ([]uint, error) {
+	teamIDs := []uint{}
+	err := h.db.Model(&intelligence.Permission{}).
+		Where("resource_type = ? AND resource_id = ? AND subject_type = ?",
+			intelligence.ResourceTypeReport, reportID, intelligence.SubjectTypeTeam).
+		Order("subject_id").
+		Pluck("subject_id", &teamIDs).Error
+	return teamIDs, err
+}
+
+// defaultTitle 未指定标题时以首篇情报的标题生成报告标题
+func defaultTitle(docs []intelligence.Intelligence) string {
+	title := "综述报告"
+	if len(docs) > 0 {
+		first := []rune(docs[0].Title)
+		if len(first) > 60 {
+			first = append(first[:60], '…')
+		}
+		title = string(first)
+		if len(docs) > 1 {
+			title = fmt.Sprintf("%s 等 %d 篇情报综述", title, len(docs))
+		} else {
+			title += " 综述"
+		}
+	}
+	return title
+}
+
+// missingIDs 返回不存在的情报ID
+func missingIDs(ids []uint, docs []intelligence.Intelligence) []uint {
+	found := make(map[uint]bool, len(docs))
+	for _, doc := range docs {
+		found[doc.ID] = true
+	}
+	missing := []uint{}
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+// uniqueIDs 去重并保持原有顺序
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	out := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// failStartGeneration 发起生成失败时返回对应的错误响应
+func failStartGeneration(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, user.ErrInsufficientPoints):
+		return utils.Fail(c, http.StatusPaymentRequired, "Insufficient points")
+	case errors.Is(err, errReservePoints):
+		return utils.Error(c, http.StatusInternalServerError, "Failed to reserve points")
+	case errors.Is(err, errReportQueueFull):
+		return utils.Error(c, http.StatusServiceUnavailable, "Report queue is full, please retry later")
+	default:
+		return utils.Error(c, http.StatusInternalServerError, "Failed to start report generation")
+	}
+}
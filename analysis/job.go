@@ -0,0 +1,303 @@
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"policy-backend/intelligence"
+	"policy-backend/llm"
+	"policy-backend/user"
+
+	"gorm.io/gorm"
+)
+
+// 后台生成任务参数
+const (
+	reportWorkerCount = 2               // 并发生成的报告数
+	reportQueueSize   = 100             // 排队中的生成任务上限
+	reportJobTimeout  = 5 * time.Minute // 单次生成的超时时间
+)
+
+var (
+	// errReportQueueFull 生成队列已满
+	errReportQueueFull = errors.New("report queue is full")
+	// errReservePoints 冻结积分失败（含余额不足）
+	errReservePoints = errors.New("failed to reserve points")
+	// errNoSources 输入的情报均已被删除
+	errNoSources = errors.New("no source intelligence available")
+	// errEmptyOutput 大模型没有返回报告正文
+	errEmptyOutput = errors.New("model returned empty report")
+)
+
+// reportJob 后台生成任务
+type reportJob struct {
+	ReportID      uint
+	UserID        uint
+	Model         string
+	ReservationID uint // 积分预授权ID，0 表示免费生成
+}
+
+// reportReference 积分预授权的业务关联标识
+func reportReference(reportID uint) string {
+	return fmt.Sprintf("report:%d", reportID)
+}
+
+// reportMetadata 报告扣费的结构化附加信息
+func reportMetadata(report *Report, price ModelPrice) user.PointsMetadata {
+	return user.PointsMetadata{
+		"report_id":     report.ID,
+		"model":         price.Model,
+		"price":         price.Price,
+		"generation":    report.Generation,
+		"intelligences": len(report.InputIDs()),
+	}
+}
+
+// startWorkers 启动后台生成工作池
+func (h *Handler) startWorkers() {
+	for i := 0; i < reportWorkerCount; i++ {
+		go func() {
+			for job := range h.jobs {
+				h.runReportJob(job)
+			}
+		}()
+	}
+}
+
+// startGeneration 冻结积分并将报告交给后台工作池生成
+// 调用前报告须已保存，且 Generation 已递增；失败时报告被标记为失败并退回积分
+func (h *Handler) startGeneration(report *Report) error {
+	job := reportJob{ReportID: report.ID, UserID: report.UserID, Model: report.Model}
+
+	// 1. 按档位价格冻结积分，余额不足时直接拒绝
+	price := h.cfg.Price(report.Model)
+	fields := map[string]interface{}{
+		"status":          ReportStatusQueued,
+		"error":           "",
+		"reservation_id":  nil,
+		"reserved_points": 0,
+		"finished_at":     nil,
+	}
+	if price.Price > 0 {
+		reservation, err := h.pointsService.Reserve(report.UserID, price.Price, reportReference(report.ID),
+			"综述报告预授权（"+price.Model+"）", reportMetadata(report, price))
+		if err != nil {
+			return fmt.Errorf("%w: %w", errReservePoints, err)
+		}
+		fields["reservation_id"] = reservation.ID
+		fields["reserved_points"] = reservation.Amount
+		job.ReservationID = reservation.ID
+	}
+
+	if err := h.db.Model(&Report{}).Where("id = ?", report.ID).Updates(fields).Error; err != nil {
+		if job.ReservationID != 0 {
+			h.releaseReservation(job.ReservationID, report.ID, err.Error())
+		}
+		return err
+	}
+	if err := h.db.First(report, report.ID).Error; err != nil {
+		return err
+	}
+
+	// 2. 交给后台工作池生成，结果通过报告详情接口获取
+	if err := h.enqueueReport(job); err != nil {
+		h.failReport(job, err)
+		return err
+	}
+	return nil
+}
+
+// enqueueReport 将生成任务放入队列，队列已满时立即返回错误
+func (h *Handler) enqueueReport(job reportJob) error {
+	select {
+	case h.jobs <- job:
+		return nil
+	default:
+		return errReportQueueFull
+	}
+}
+
+// runReportJob 执行一次后台生成：读取情报、调用大模型、保存正文与引用并结算积分
+func (h *Handler) runReportJob(job reportJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), reportJobTimeout)
+	defer cancel()
+
+	var report Report
+	if err := h.db.First(&report, job.ReportID).Error; err != nil {
+		// 报告在排队期间被删除
+		if job.ReservationID != 0 {
+			h.releaseReservation(job.ReservationID, job.ReportID, "report deleted")
+		}
+		return
+	}
+	h.updateReport(report.ID, map[string]interface{}{"status": ReportStatusRunning})
+
+	docs, err := h.loadSources(report.InputIDs())
+	if err != nil {
+		h.failReport(job, err)
+		return
+	}
+	if len(docs) == 0 {
+		h.failReport(job, errNoSources)
+		return
+	}
+
+	temperature := 0.3
+	resp, err := h.llm.Chat(ctx, job.Model, llm.ChatRequest{
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: systemPrompt},
+			{Role: llm.RoleUser, Content: buildPrompt(report.PromptTemplate, docs, h.cfg.MaxDocumentChars)},
+		},
+		Temperature: &temperature,
+		MaxTokens:   h.cfg.MaxOutputTokens,
+	})
+	if err != nil {
+		h.failReport(job, err)
+		return
+	}
+	content := strings.TrimSpace(resp.Content)
+	if content == "" {
+		h.failReport(job, errEmptyOutput)
+		return
+	}
+
+	inputs := make([]uint, 0, len(docs))
+	for _, doc := range docs {
+		inputs = append(inputs, doc.ID)
+	}
+	citations, err := json.Marshal(extractCitations(content, inputs))
+	if err != nil {
+		h.failReport(job, err)
+		return
+	}
+
+	// 生成成功后才结算积分
+	cost := h.captureReport(job, &report)
+
+	now := time.Now()
+	h.updateReport(report.ID, map[string]interface{}{
+		"status":            ReportStatusDone,
+		"content":           content,
+		"citations":         citations,
+		"llm_model":         resp.Model,
+		"prompt_tokens":     resp.Usage.PromptTokens,
+		"completion_tokens": resp.Usage.CompletionTokens,
+		"tokens_used":       resp.Usage.TotalTokens,
+		"cost":              cost,
+		"finished_at":       &now,
+	})
+}
+
+// loadSources 按输入顺序读取情报，已删除的情报跳过
+func (h *Handler) loadSources(ids []uint) ([]intelligence.Intelligence, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var records []intelligence.Intelligence
+	if err := h.db.Where("id IN ?", ids).Find(&records).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]intelligence.Intelligence, len(records))
+	for _, r := range records {
+		byID[r.ID] = r
+	}
+
+	docs := make([]intelligence.Intelligence, 0, len(records))
+	for _, id := range ids {
+		if r, ok := byID[id]; ok {
+			docs = append(docs, r)
+		}
+	}
+	return docs, nil
+}
+
+// captureReport 生成成功后按档位价格结算预授权，返回实际扣除的积分
+func (h *Handler) captureReport(job reportJob, report *Report) int64 {
+	if job.ReservationID == 0 {
+		return 0
+	}
+	price := h.cfg.Price(job.Model)
+	if err := h.pointsService.Capture(job.ReservationID, price.Price, "综述报告结算退回（"+price.Model+"）",
+		reportMetadata(report, price)); err != nil {
+		log.Printf("Failed to capture points for report %d: %v\n", report.ID, err)
+		return 0
+	}
+	return price.Price
+}
+
+// releaseReservation 释放预授权，全额退回冻结的积分
+func (h *Handler) releaseReservation(reservationID, reportID uint, reason string) {
+	err := h.pointsService.Release(reservationID, "综述报告生成失败退回", user.PointsMetadata{
+		"report_id": reportID,
+		"reason":    reason,
+	})
+	if err != nil && !errors.Is(err, user.ErrReservationSettled) {
+		log.Printf("Failed to release points for report %d: %v\n", reportID, err)
+	}
+}
+
+// failReport 将报告标记为失败并全额退回冻结的积分，保留上一次生成的正文
+func (h *Handler) failReport(job reportJob, cause error) {
+	log.Printf("Report %d generation failed: %v\n", job.ReportID, cause)
+
+	if job.ReservationID != 0 {
+		h.releaseReservation(job.ReservationID, job.ReportID, truncateError(cause))
+	}
+
+	now := time.Now()
+	h.updateReport(job.ReportID, map[string]interface{}{
+		"status":      ReportStatusFailed,
+		"error":       truncateError(cause),
+		"cost":        0,
+		"finished_at": &now,
+	})
+}
+
+// updateReport 更新报告字段
+func (h *Handler) updateReport(reportID uint, fields map[string]interface{}) {
+	if err := h.db.Model(&Report{}).Where("id = ?", reportID).Updates(fields).Error; err != nil {
+		log.Printf("Failed to update report %d: %v\n", reportID, err)
+	}
+}
+
+// truncateError 截断错误信息以适配数据库字段长度
+func truncateError(err error) string {
+	msg := []rune(err.Error())
+	if len(msg) > 500 {
+		msg = msg[:500]
+	}
+	return string(msg)
+}
+
+// FailInterruptedReports 将服务重启前未完成的报告标记为失败，并退回冻结的积分
+// 应在服务启动时调用一次
+func FailInterruptedReports(db *gorm.DB, pointsService *user.PointsTransactionService) (int64, error) {
+	unfinished := []string{ReportStatusQueued, ReportStatusRunning}
+
+	var interrupted []Report
+	if err := db.Select("id, reservation_id").
+		Where("status IN ? AND reservation_id IS NOT NULL", unfinished).
+		Find(&interrupted).Error; err != nil {
+		return 0, err
+	}
+	h := &Handler{db: db, pointsService: pointsService}
+	for _, report := range interrupted {
+		h.releaseReservation(*report.ReservationID, report.ID, "interrupted by server restart")
+	}
+
+	now := time.Now()
+	result := db.Model(&Report{}).
+		Where("status IN ?", unfinished).
+		Updates(map[string]interface{}{
+			"status":      ReportStatusFailed,
+			"error":       "interrupted by server restart",
+			"cost":        0,
+			"finished_at": &now,
+		})
+	return result.RowsAffected, result.Error
+}
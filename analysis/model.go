@@ -0,0 +1,140 @@
+package analysis
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 报告状态
+const (
+	ReportStatusQueued  = "queued"  // 已排队，等待后台生成
+	ReportStatusRunning = "running" // 正在调用大模型
+	ReportStatusDone    = "done"    // 生成成功
+	ReportStatusFailed  = "failed"  // 生成失败
+)
+
+// Report 多篇情报的综述报告
+// 由大模型根据所选情报与提示词在后台生成，正文中以 [#情报ID] 标注引用来源
+type Report struct {
+	ID              uint            `gorm:"primaryKey" json:"id"`
+	UserID          uint            `gorm:"index;comment:创建者ID" json:"user_id"`
+	Title           string          `gorm:"type:varchar(200);comment:报告标题" json:"title"`
+	IntelligenceIDs json.RawMessage `gorm:"type:json;comment:输入的情报ID列表" json:"intelligence_ids"`
+	PromptTemplate  string          `gorm:"type:text;comment:提示词模板" json:"prompt_template"`
+	Model           string          `gorm:"type:varchar(20);comment:模型档位:basic,advanced,pro" json:"model"`
+	Status          string          `gorm:"type:varchar(20);index;default:'queued';comment:状态:queued排队中,running生成中,done已完成,failed失败" json:"status"`
+	Error           string          `gorm:"type:varchar(500)" json:"error,omitempty"`
+
+	// 生成结果：重新生成失败时保留上一次的结果
+	Content   string          `gorm:"type:text;comment:报告正文(Markdown)" json:"content"`
+	Citations json.RawMessage `gorm:"type:json;comment:正文中引用的情报ID列表" json:"citations"`
+	LLMModel  string          `gorm:"column:llm_model;type:varchar(100);comment:实际使用的模型" json:"llm_model,omitempty"`
+
+	// 用量与计费
+	PromptTokens     int   `gorm:"default:0" json:"prompt_tokens"`
+	CompletionTokens int   `gorm:"default:0" json:"completion_tokens"`
+	TokensUsed       int   `gorm:"default:0;comment:最近一次生成消耗的 token 数" json:"tokens_used"`
+	ReservationID    *uint `gorm:"comment:积分预授权ID" json:"-"`
+	ReservedPoints   int64 `gorm:"default:0;comment:冻结的积分" json:"reserved_points"`
+	Cost             int64 `gorm:"default:0;comment:最近一次生成扣除的积分" json:"cost"`
+	Generation       int   `gorm:"default:0;comment:已发起的生成次数" json:"generation"`
+
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName 指定表名
+func (Report) TableName() string {
+	return "analysis_reports"
+}
+
+// InputIDs 解析输入的情报ID列表
+func (r *Report) InputIDs() []uint {
+	var ids []uint
+	if len(r.IntelligenceIDs) > 0 {
+		_ = json.Unmarshal(r.IntelligenceIDs, &ids)
+	}
+	return ids
+}
+
+// CitedIDs 解析正文引用的情报ID列表
+func (r *Report) CitedIDs() []uint {
+	var ids []uint
+	if len(r.Citations) > 0 {
+		_ = json.Unmarshal(r.Citations, &ids)
+	}
+	return ids
+}
+
+// IsFinished 报告是否已结束生成（完成或失败）
+func (r *Report) IsFinished() bool {
+	return r.Status == ReportStatusDone || r.Status == ReportStatusFailed
+}
+
+// ReportSource 报告的来源情报
+type ReportSource struct {
+	ID          uint      `json:"id"`
+	Title       string    `json:"title"`
+	Source      string    `json:"source"`
+	URL         string    `json:"url"`
+	PublishDate time.Time `json:"publish_date"`
+	Cited       bool      `json:"cited"` // 正文中是否引用了该情报
+}
+
+// ReportDetail 报告详情，包含来源情报与共享的团队
+type ReportDetail struct {
+	Report
+	Sources []ReportSource `json:"sources"`
+	TeamIDs []uint         `json:"team_ids"` // 已共享的团队
+}
+
+// SummaryRequest 生成综述报告请求
+type SummaryRequest struct {
+	IntelligenceIDs []uint `json:"intelligence_ids" validate:"required,min=1,dive,min=1"`
+	PromptTemplate  string `json:"prompt_template" validate:"max=4000"`
+	Model           string `json:"model" validate:"omitempty,oneof=basic advanced pro"`
+	Title           string `json:"title" validate:"max=200"`
+}
+
+// RegenerateRequest 重新生成报告请求，字段为空时沿用原报告的设置
+type RegenerateRequest struct {
+	PromptTemplate *string `json:"prompt_template" validate:"omitempty,max=4000"`
+	Model          string  `json:"model" validate:"omitempty,oneof=basic advanced pro"`
+}
+
+// ShareRequest 共享报告到团队请求
+type ShareRequest struct {
+	TeamID uint `json:"team_id" validate:"required"`
+}
+
+// PageQuery 通用分页参数
+type PageQuery struct {
+	Page     int `query:"page" validate:"omitempty,min=1"`
+	PageSize int `query:"page_size" validate:"omitempty,min=1,max=100"`
+}
+
+// normalize 填充默认页码与每页数量
+func (q *PageQuery) normalize(defaultSize int) {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 {
+		q.PageSize = defaultSize
+	}
+}
+
+// offset 计算偏移量
+func (q *PageQuery) offset() int {
+	return (q.Page - 1) * q.PageSize
+}
+
+// ReportQuery 报告列表查询参数
+type ReportQuery struct {
+	PageQuery
+	TeamID uint   `query:"team_id"`                                                      // 查看共享到某个团队的报告，为空时查看自己的报告
+	Status string `query:"status" validate:"omitempty,oneof=queued running done failed"` // 按状态过滤
+}
@@ -0,0 +1,106 @@
+package analysis
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"policy-backend/intelligence"
+)
+
+// documentsPlaceholder 提示词模板中情报正文的占位符，模板中没有占位符时情报附在模板之后
+const documentsPlaceholder = "{{documents}}"
+
+// defaultPromptTemplate 未指定提示词模板时使用的默认模板
+const defaultPromptTemplate = `请根据以下情报撰写一份综述报告，包括：
+1. 主要政策动向与关键举措；
+2. 各来源之间的共同点与差异；
+3. 对后续走势的判断与建议。
+
+` + documentsPlaceholder
+
+// systemPrompt 报告生成的系统提示词，要求以 [#情报ID] 标注引用
+const systemPrompt = `你是政策情报分析师，根据用户提供的多篇情报撰写综述报告。
+要求：
+- 使用简体中文，以 Markdown 格式输出报告正文；
+- 引用某篇情报的内容时，在句末用 [#情报ID] 标注来源，例如 [#12]，同时引用多篇时写作 [#12][#15]；
+- 只能引用给出的情报，不得编造情报中没有的事实或来源。`
+
+// citationPattern 匹配正文中的引用标注，如 [#12]、[#12, #15]
+var citationPattern = regexp.MustCompile(`\[#\d+(?:\s*[,，、]\s*#?\d+)*\]`)
+
+// citationIDPattern 从引用标注中取出情报ID
+var citationIDPattern = regexp.MustCompile(`\d+`)
+
+// buildPrompt 将情报按输入顺序渲染后填入提示词模板
+func buildPrompt(template string, docs []intelligence.Intelligence, maxChars int) string {
+	if strings.TrimSpace(template) == "" {
+		template = defaultPromptTemplate
+	}
+
+	var b strings.Builder
+	for i, doc := range docs {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(formatDocument(doc, maxChars))
+	}
+	documents := b.String()
+
+	if strings.Contains(template, documentsPlaceholder) {
+		return strings.ReplaceAll(template, documentsPlaceholder, documents)
+	}
+	return template + "\n\n" + documents
+}
+
+// formatDocument 渲染单篇情报：引用编号、标题、来源信息与截断后的正文
+func formatDocument(doc intelligence.Intelligence, maxChars int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[#%d] %s\n", doc.ID, doc.Title)
+	if doc.Source != "" {
+		fmt.Fprintf(&b, "来源：%s\n", doc.Source)
+	}
+	if !doc.PublishDate.IsZero() {
+		fmt.Fprintf(&b, "发布日期：%s\n", doc.PublishDate.Format("2006-01-02"))
+	}
+	if doc.URL != "" {
+		fmt.Fprintf(&b, "链接：%s\n", doc.URL)
+	}
+
+	text := strings.TrimSpace(doc.Content)
+	if text == "" {
+		text = strings.TrimSpace(doc.Summary)
+	}
+	if runes := []rune(text); maxChars > 0 && len(runes) > maxChars {
+		text = string(runes[:maxChars]) + "……"
+	}
+	b.WriteString("正文：\n")
+	b.WriteString(text)
+	return b.String()
+}
+
+// extractCitations 按首次出现的顺序取出正文引用的情报ID，只保留属于输入情报的ID
+func extractCitations(content string, inputs []uint) []uint {
+	allowed := make(map[uint]bool, len(inputs))
+	for _, id := range inputs {
+		allowed[id] = true
+	}
+
+	seen := make(map[uint]bool)
+	cited := []uint{}
+	for _, mark := range citationPattern.FindAllString(content, -1) {
+		for _, digits := range citationIDPattern.FindAllString(mark, -1) {
+			n, err := strconv.ParseUint(digits, 10, 64)
+			if err != nil {
+				continue
+			}
+			id := uint(n)
+			if allowed[id] && !seen[id] {
+				seen[id] = true
+				cited = append(cited, id)
+			}
+		}
+	}
+	return cited
+}
@@ -0,0 +1,20 @@
+package analysis
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterRoutes 注册分析模块路由
+// 基础路径: /api/analysis
+func RegisterRoutes(g *echo.Group, h *Handler) {
+	g.POST("/summary", h.CreateSummary) // 生成综述报告（后台执行，成功后扣积分）
+	g.GET("/pricing", h.GetPricing)     // 各模型档位的报告价格
+
+	// 报告管理
+	g.GET("/reports", h.ListReports)                         // 分页获取我的报告或团队共享的报告
+	g.GET("/reports/:id", h.GetReport)                       // 报告详情（含来源情报与引用标注）
+	g.DELETE("/reports/:id", h.DeleteReport)                 // 删除报告
+	g.POST("/reports/:id/regenerate", h.RegenerateReport)    // 重新生成（可更换提示词与模型档位）
+	g.POST("/reports/:id/share", h.ShareReport)              // 共享到团队
+	g.DELETE("/reports/:id/share/:team_id", h.UnshareReport) // 取消共享
+}
@@ -11,8 +11,14 @@ import (
 	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/v2"
 
+	"policy-backend/analysis"
 	"policy-backend/auth"
 	"policy-backend/database"
+	"policy-backend/intelligence"
+	"policy-backend/keyword"
+	"policy-backend/llm"
+	"policy-backend/search"
+	"policy-backend/storage"
 	"policy-backend/utils"
 )
 
@@ -34,14 +40,96 @@ type AppConfig struct {
 	// Log
 	LogLevel string `koanf:"log_level"`
 	LogFile  string `koanf:"log_file"`
+
+	// Search
+	SearchBufferTTLHours    int `koanf:"search_buffer_ttl_hours"`
+	SearchBufferMaxTTLHours int `koanf:"search_buffer_max_ttl_hours"`
+	SearchArchiveLimit      int `koanf:"search_archive_limit"`
+	SearchMonitorLimit      int `koanf:"search_monitor_limit"`
+
+	SearchImportMaxSizeMB int `koanf:"search_import_max_size_mb"`
+	SearchImportMaxRows   int `koanf:"search_import_max_rows"`
+
+	SearchPageFetchEnabled        bool `koanf:"search_page_fetch_enabled"`
+	SearchPageFetchTimeoutSeconds int  `koanf:"search_page_fetch_timeout_seconds"`
+	SearchPageFetchConcurrency    int  `koanf:"search_page_fetch_concurrency"`
+	SearchPageMaxSizeKB           int  `koanf:"search_page_max_size_kb"`
+
+	SearchPriceBasic             int64 `koanf:"search_price_basic"`
+	SearchPriceAdvanced          int64 `koanf:"search_price_advanced"`
+	SearchPricePro               int64 `koanf:"search_price_pro"`
+	SearchPricePerResultBasic    int64 `koanf:"search_price_per_result_basic"`
+	SearchPricePerResultAdvanced int64 `koanf:"search_price_per_result_advanced"`
+	SearchPricePerResultPro      int64 `koanf:"search_price_per_result_pro"`
+
+	// Intelligence
+	IntelligencePDFMaxSizeMB           int    `koanf:"intelligence_pdf_max_size_mb"`
+	IntelligencePDFFetchTimeoutSeconds int    `koanf:"intelligence_pdf_fetch_timeout_seconds"`
+	IntelligencePDFFetchConcurrency    int    `koanf:"intelligence_pdf_fetch_concurrency"`
+	IntelligenceExportSyncLimit        int    `koanf:"intelligence_export_sync_limit"`
+	IntelligenceExportMaxRows          int    `koanf:"intelligence_export_max_rows"`
+	IntelligenceExportTTLHours         int    `koanf:"intelligence_export_ttl_hours"`
+	IntelligenceExportConcurrency      int    `koanf:"intelligence_export_concurrency"`
+	IntelligenceTagAdmins              string `koanf:"intelligence_tag_admins"`
+
+	// Analysis
+	AnalysisMaxIntelligences int   `koanf:"analysis_max_intelligences"`
+	AnalysisMaxDocumentChars int   `koanf:"analysis_max_document_chars"`
+	AnalysisMaxOutputTokens  int   `koanf:"analysis_max_output_tokens"`
+	AnalysisPriceBasic       int64 `koanf:"analysis_price_basic"`
+	AnalysisPriceAdvanced    int64 `koanf:"analysis_price_advanced"`
+	AnalysisPricePro         int64 `koanf:"analysis_price_pro"`
+
+	// Storage
+	StorageBackend     string `koanf:"storage_backend"`
+	StorageLocalDir    string `koanf:"storage_local_dir"`
+	StorageS3Endpoint  string `koanf:"storage_s3_endpoint"`
+	StorageS3Region    string `koanf:"storage_s3_region"`
+	StorageS3Bucket    string `koanf:"storage_s3_bucket"`
+	StorageS3AccessKey string `koanf:"storage_s3_access_key"`
+	StorageS3SecretKey string `koanf:"storage_s3_secret_key"`
+	StorageS3PathStyle bool   `koanf:"storage_s3_path_style"`
+
+	// LLM（按档位配置）
+	LLMTimeoutSeconds int `koanf:"llm_timeout_seconds"`
+
+	LLMBasicProvider       string `koanf:"llm_basic_provider"`
+	LLMBasicEndpoint       string `koanf:"llm_basic_endpoint"`
+	LLMBasicAPIKey         string `koanf:"llm_basic_api_key"`
+	LLMBasicModel          string `koanf:"llm_basic_model"`
+	LLMBasicEmbeddingModel string `koanf:"llm_basic_embedding_model"`
+
+	LLMAdvancedProvider       string `koanf:"llm_advanced_provider"`
+	LLMAdvancedEndpoint       string `koanf:"llm_advanced_endpoint"`
+	LLMAdvancedAPIKey         string `koanf:"llm_advanced_api_key"`
+	LLMAdvancedModel          string `koanf:"llm_advanced_model"`
+	LLMAdvancedEmbeddingModel string `koanf:"llm_advanced_embedding_model"`
+
+	LLMProProvider       string `koanf:"llm_pro_provider"`
+	LLMProEndpoint       string `koanf:"llm_pro_endpoint"`
+	LLMProAPIKey         string `koanf:"llm_pro_api_key"`
+	LLMProModel          string `koanf:"llm_pro_model"`
+	LLMProEmbeddingModel string `koanf:"llm_pro_embedding_model"`
+
+	// Keyword
+	KeywordMaxKeywords int    `koanf:"keyword_max_keywords"`
+	KeywordTitleWeight int    `koanf:"keyword_title_weight"`
+	KeywordUserDict    string `koanf:"keyword_user_dict"`
+	KeywordStopWords   string `koanf:"keyword_stop_words"`
 }
 
 // Config 对外暴露的配置结构，包含各模块独立的配置
 type Config struct {
-	Server   ServerConfig
-	Database database.Config
-	Auth     auth.Config
-	Log      utils.LogConfig
+	Server       ServerConfig
+	Database     database.Config
+	Auth         auth.Config
+	Log          utils.LogConfig
+	Search       search.Config
+	Intelligence intelligence.Config
+	Analysis     analysis.Config
+	Storage      storage.Config
+	LLM          llm.Config
+	Keyword      keyword.Config
 }
 
 // defaultAppConfig 聚合所有模块的默认配置
@@ -50,6 +138,12 @@ func defaultAppConfig() AppConfig {
 	dbDef := database.DefaultConfig()
 	authDef := auth.DefaultConfig()
 	logDef := utils.DefaultLogConfig()
+	searchDef := search.DefaultConfig()
+	intelligenceDef := intelligence.DefaultConfig()
+	analysisDef := analysis.DefaultConfig()
+	storageDef := storage.DefaultConfig()
+	llmDef := llm.DefaultConfig()
+	keywordDef := keyword.DefaultConfig()
 
 	return AppConfig{
 		// Server
@@ -68,6 +162,82 @@ func defaultAppConfig() AppConfig {
 		// Log
 		LogLevel: logDef.LogLevel,
 		LogFile:  logDef.LogFile,
+
+		// Search
+		SearchBufferTTLHours:    searchDef.BufferTTLHours,
+		SearchBufferMaxTTLHours: searchDef.BufferMaxTTLHours,
+		SearchArchiveLimit:      searchDef.ArchiveLimit,
+		SearchMonitorLimit:      searchDef.MonitorLimit,
+
+		SearchImportMaxSizeMB: searchDef.ImportMaxSizeMB,
+		SearchImportMaxRows:   searchDef.ImportMaxRows,
+
+		SearchPageFetchEnabled:        searchDef.PageFetchEnabled,
+		SearchPageFetchTimeoutSeconds: searchDef.PageFetchTimeoutSeconds,
+		SearchPageFetchConcurrency:    searchDef.PageFetchConcurrency,
+		SearchPageMaxSizeKB:           searchDef.PageMaxSizeKB,
+
+		SearchPriceBasic:             searchDef.PriceBasic,
+		SearchPriceAdvanced:          searchDef.PriceAdvanced,
+		SearchPricePro:               searchDef.PricePro,
+		SearchPricePerResultBasic:    searchDef.PricePerResultBasic,
+		SearchPricePerResultAdvanced: searchDef.PricePerResultAdvanced,
+		SearchPricePerResultPro:      searchDef.PricePerResultPro,
+
+		// Intelligence
+		IntelligencePDFMaxSizeMB:           intelligenceDef.PDFMaxSizeMB,
+		IntelligencePDFFetchTimeoutSeconds: intelligenceDef.PDFFetchTimeoutSeconds,
+		IntelligencePDFFetchConcurrency:    intelligenceDef.PDFFetchConcurrency,
+		IntelligenceExportSyncLimit:        intelligenceDef.ExportSyncLimit,
+		IntelligenceExportMaxRows:          intelligenceDef.ExportMaxRows,
+		IntelligenceExportTTLHours:         intelligenceDef.ExportTTLHours,
+		IntelligenceExportConcurrency:      intelligenceDef.ExportConcurrency,
+		IntelligenceTagAdmins:              intelligenceDef.TagAdmins,
+
+		// Analysis
+		AnalysisMaxIntelligences: analysisDef.MaxIntelligences,
+		AnalysisMaxDocumentChars: analysisDef.MaxDocumentChars,
+		AnalysisMaxOutputTokens:  analysisDef.MaxOutputTokens,
+		AnalysisPriceBasic:       analysisDef.PriceBasic,
+		AnalysisPriceAdvanced:    analysisDef.PriceAdvanced,
+		AnalysisPricePro:         analysisDef.PricePro,
+
+		// Storage
+		StorageBackend:     storageDef.Backend,
+		StorageLocalDir:    storageDef.LocalDir,
+		StorageS3Endpoint:  storageDef.S3Endpoint,
+		StorageS3Region:    storageDef.S3Region,
+		StorageS3Bucket:    storageDef.S3Bucket,
+		StorageS3AccessKey: storageDef.S3AccessKey,
+		StorageS3SecretKey: storageDef.S3SecretKey,
+		StorageS3PathStyle: storageDef.S3PathStyle,
+
+		// LLM
+		LLMTimeoutSeconds: llmDef.TimeoutSeconds,
+
+		LLMBasicProvider:       llmDef.Basic.Provider,
+		LLMBasicEndpoint:       llmDef.Basic.Endpoint,
+		LLMBasicAPIKey:         llmDef.Basic.APIKey,
+		LLMBasicModel:          llmDef.Basic.Model,
+		LLMBasicEmbeddingModel: llmDef.Basic.EmbeddingModel,
+
+		LLMAdvancedProvider:       llmDef.Advanced.Provider,
+		LLMAdvancedEndpoint:       llmDef.Advanced.Endpoint,
+		LLMAdvancedAPIKey:         llmDef.Advanced.APIKey,
+		LLMAdvancedModel:          llmDef.Advanced.Model,
+		LLMAdvancedEmbeddingModel: llmDef.Advanced.EmbeddingModel,
+
+		LLMProProvider:       llmDef.Pro.Provider,
+		LLMProEndpoint:       llmDef.Pro.Endpoint,
+		LLMProAPIKey:         llmDef.Pro.APIKey,
+		LLMProModel:          llmDef.Pro.Model,
+		LLMProEmbeddingModel: llmDef.Pro.EmbeddingModel,
+
+		// Keyword
+		KeywordMaxKeywords: keywordDef.MaxKeywords,
+		KeywordTitleWeight: keywordDef.TitleWeight,
+		KeywordUserDict:    keywordDef.UserDictPath,
+		KeywordStopWords:   keywordDef.StopWordsPath,
 	}
 }
 
@@ -130,6 +300,85 @@ func LoadConfig() *Config {
 			LogLevel: app.LogLevel,
 			LogFile:  app.LogFile,
 		},
+		Search: search.Config{
+			BufferTTLHours:    app.SearchBufferTTLHours,
+			BufferMaxTTLHours: app.SearchBufferMaxTTLHours,
+			ArchiveLimit:      app.SearchArchiveLimit,
+			MonitorLimit:      app.SearchMonitorLimit,
+
+			ImportMaxSizeMB: app.SearchImportMaxSizeMB,
+			ImportMaxRows:   app.SearchImportMaxRows,
+
+			PageFetchEnabled:        app.SearchPageFetchEnabled,
+			PageFetchTimeoutSeconds: app.SearchPageFetchTimeoutSeconds,
+			PageFetchConcurrency:    app.SearchPageFetchConcurrency,
+			PageMaxSizeKB:           app.SearchPageMaxSizeKB,
+
+			PriceBasic:             app.SearchPriceBasic,
+			PriceAdvanced:          app.SearchPriceAdvanced,
+			PricePro:               app.SearchPricePro,
+			PricePerResultBasic:    app.SearchPricePerResultBasic,
+			PricePerResultAdvanced: app.SearchPricePerResultAdvanced,
+			PricePerResultPro:      app.SearchPricePerResultPro,
+		},
+		Intelligence: intelligence.Config{
+			PDFMaxSizeMB:           app.IntelligencePDFMaxSizeMB,
+			PDFFetchTimeoutSeconds: app.IntelligencePDFFetchTimeoutSeconds,
+			PDFFetchConcurrency:    app.IntelligencePDFFetchConcurrency,
+			ExportSyncLimit:        app.IntelligenceExportSyncLimit,
+			ExportMaxRows:          app.IntelligenceExportMaxRows,
+			ExportTTLHours:         app.IntelligenceExportTTLHours,
+			ExportConcurrency:      app.IntelligenceExportConcurrency,
+			TagAdmins:              app.IntelligenceTagAdmins,
+		},
+		Analysis: analysis.Config{
+			MaxIntelligences: app.AnalysisMaxIntelligences,
+			MaxDocumentChars: app.AnalysisMaxDocumentChars,
+			MaxOutputTokens:  app.AnalysisMaxOutputTokens,
+			PriceBasic:       app.AnalysisPriceBasic,
+			PriceAdvanced:    app.AnalysisPriceAdvanced,
+			PricePro:         app.AnalysisPricePro,
+		},
+		Storage: storage.Config{
+			Backend:     app.StorageBackend,
+			LocalDir:    app.StorageLocalDir,
+			S3Endpoint:  app.StorageS3Endpoint,
+			S3Region:    app.StorageS3Region,
+			S3Bucket:    app.StorageS3Bucket,
+			S3AccessKey: app.StorageS3AccessKey,
+			S3SecretKey: app.StorageS3SecretKey,
+			S3PathStyle: app.StorageS3PathStyle,
+		},
+		LLM: llm.Config{
+			Basic: llm.TierConfig{
+				Provider:       app.LLMBasicProvider,
+				Endpoint:       app.LLMBasicEndpoint,
+				APIKey:         app.LLMBasicAPIKey,
+				Model:          app.LLMBasicModel,
+				EmbeddingModel: app.LLMBasicEmbeddingModel,
+			},
+			Advanced: llm.TierConfig{
+				Provider:       app.LLMAdvancedProvider,
+				Endpoint:       app.LLMAdvancedEndpoint,
+				APIKey:         app.LLMAdvancedAPIKey,
+				Model:          app.LLMAdvancedModel,
+				EmbeddingModel: app.LLMAdvancedEmbeddingModel,
+			},
+			Pro: llm.TierConfig{
+				Provider:       app.LLMProProvider,
+				Endpoint:       app.LLMProEndpoint,
+				APIKey:         app.LLMProAPIKey,
+				Model:          app.LLMProModel,
+				EmbeddingModel: app.LLMProEmbeddingModel,
+			},
+			TimeoutSeconds: app.LLMTimeoutSeconds,
+		},
+		Keyword: keyword.Config{
+			MaxKeywords:   app.KeywordMaxKeywords,
+			TitleWeight:   app.KeywordTitleWeight,
+			UserDictPath:  app.KeywordUserDict,
+			StopWordsPath: app.KeywordStopWords,
+		},
 	}
 }
 
@@ -172,6 +421,7 @@ func isSensitiveKey(key string) bool {
 		"apikey",
 		"token_key", // 避免误杀 TokenDuration
 		"access_key",
+		"accesskey", // 对象存储访问密钥 ID
 	}
 
 	for _, pattern := range sensitivePatterns {
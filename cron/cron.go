@@ -3,6 +3,7 @@ package cron
 import (
 	"context"
 	"log"
+	"policy-backend/intelligence"
 	"policy-backend/search"
 	"time"
 
@@ -11,20 +12,22 @@ import (
 
 // CronJob 定时任务管理器
 type CronJob struct {
-	db          *gorm.DB
-	searchH     *search.Handler
-	ctx         context.Context
-	cancelFunc  context.CancelFunc
+	db              *gorm.DB
+	searchH         *search.Handler
+	intelligenceSvc *intelligence.Service
+	ctx             context.Context
+	cancelFunc      context.CancelFunc
 }
 
 // NewCronJob 创建新的定时任务管理器
-func NewCronJob(db *gorm.DB, searchH *search.Handler) *CronJob {
+func NewCronJob(db *gorm.DB, searchH *search.Handler, intelligenceSvc *intelligence.Service) *CronJob {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &CronJob{
-		db:         db,
-		searchH:    searchH,
-		ctx:        ctx,
-		cancelFunc: cancel,
+		db:              db,
+		searchH:         searchH,
+		intelligenceSvc: intelligenceSvc,
+		ctx:             ctx,
+		cancelFunc:      cancel,
 	}
 }
 
@@ -35,6 +38,18 @@ func (c *CronJob) Start() {
 	// 启动清理过期缓冲区数据的定时任务（每小时执行一次）
 	go c.startBufferCleanupJob()
 
+	// 启动清理过期导出文件的定时任务（每小时执行一次）
+	go c.startExportCleanupJob()
+
+	// 启动时补算历史数据的规范 URL 与哈希（仅处理尚未规范化的记录）
+	go c.migrateDataHashes()
+
+	// 启动关键词语料重建与回填（启动时执行一次，之后每天一次）
+	go c.startKeywordJob()
+
+	// 启动监听任务调度（每分钟检查一次到期的监听任务）
+	go c.startMonitorJob()
+
 	log.Println("Cron jobs started successfully")
 }
 
@@ -77,3 +92,112 @@ func (c *CronJob) cleanupExpiredBuffers() {
 
 	log.Printf("Buffer cleanup completed. Deleted %d expired records.\n", rowsAffected)
 }
+
+// startExportCleanupJob 启动清理过期导出文件的定时任务
+func (c *CronJob) startExportCleanupJob() {
+	c.cleanupExpiredExports()
+
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.cleanupExpiredExports()
+		case <-c.ctx.Done():
+			log.Println("Export cleanup job stopped")
+			return
+		}
+	}
+}
+
+// cleanupExpiredExports 清理过期的导出文件与任务记录
+func (c *CronJob) cleanupExpiredExports() {
+	cleaned, err := c.intelligenceSvc.CleanupExpiredExports(c.ctx)
+	if err != nil {
+		log.Printf("Failed to cleanup expired exports: %v\n", err)
+		return
+	}
+
+	if cleaned > 0 {
+		log.Printf("Export cleanup completed. Deleted %d expired exports.\n", cleaned)
+	}
+}
+
+// migrateDataHashes 重算历史情报与缓冲区的规范 URL、DataHash 及内容指纹
+func (c *CronJob) migrateDataHashes() {
+	log.Println("Starting data hash migration...")
+
+	result, err := c.searchH.RecomputeDataHashes(false)
+	if err != nil {
+		log.Printf("Failed to migrate data hashes: %v\n", err)
+		return
+	}
+
+	log.Printf("Data hash migration completed. Updated %d intelligences and %d buffers.\n",
+		result.Intelligences, result.Buffers)
+}
+
+// startMonitorJob 启动监听任务调度
+func (c *CronJob) startMonitorJob() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.runDueMonitors()
+		case <-c.ctx.Done():
+			log.Println("Monitor job stopped")
+			return
+		}
+	}
+}
+
+// runDueMonitors 运行已到期的监听任务
+func (c *CronJob) runDueMonitors() {
+	started, err := c.searchH.RunDueMonitors()
+	if err != nil {
+		log.Printf("Failed to run due monitors: %v\n", err)
+		return
+	}
+
+	if started > 0 {
+		log.Printf("Started %d monitor runs.\n", started)
+	}
+}
+
+// startKeywordJob 启动关键词语料重建与回填的定时任务
+func (c *CronJob) startKeywordJob() {
+	c.refreshKeywords()
+
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.refreshKeywords()
+		case <-c.ctx.Done():
+			log.Println("Keyword job stopped")
+			return
+		}
+	}
+}
+
+// refreshKeywords 由情报库重建关键词语料统计，再为没有关键词的情报补充关键词
+func (c *CronJob) refreshKeywords() {
+	docs, err := c.intelligenceSvc.RebuildKeywordCorpus(c.ctx)
+	if err != nil {
+		log.Printf("Failed to rebuild keyword corpus: %v\n", err)
+		return
+	}
+
+	updated, err := c.intelligenceSvc.BackfillKeywords(c.ctx, false)
+	if err != nil {
+		log.Printf("Failed to backfill keywords: %v\n", err)
+		return
+	}
+
+	log.Printf("Keyword refresh completed. Corpus has %d documents, backfilled %d intelligences.\n", docs, updated)
+}
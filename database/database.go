@@ -1,9 +1,11 @@
 package database
 
 import (
+	"policy-backend/analysis"
 	"policy-backend/intelligence"
 	"policy-backend/org"
 	"policy-backend/search"
+	"policy-backend/team"
 	"policy-backend/user"
 	"strings"
 
@@ -53,6 +55,11 @@ func InitDB(cfg *Config) error {
 		return err
 	}
 
+	// 初始化全文索引（SQLite FTS5 / MySQL FULLTEXT）
+	if _, err := intelligence.InitFullText(DB); err != nil {
+		return err
+	}
+
 	// 初始化样例数据
 	if err := org.SeedData(DB); err != nil {
 		return err
@@ -68,19 +75,45 @@ func AutoMigrate() error {
 		return gorm.ErrInvalidDB
 	}
 
-	return DB.AutoMigrate(
+	// 标签表首次创建时将已有情报的关键词迁移为标签
+	migrateKeywords := !DB.Migrator().HasTable(&intelligence.IntelligenceTag{})
+
+	err := DB.AutoMigrate(
 		&intelligence.Intelligence{},
 		&intelligence.IntelligenceShared{},
 		&intelligence.Rating{},
+		&intelligence.Permission{},
+		&intelligence.ExportJob{},
+		&intelligence.Tag{},
+		&intelligence.TagSynonym{},
+		&intelligence.IntelligenceTag{},
+		&intelligence.Collection{},
+		&intelligence.CollectionItem{},
 		&user.Team{},
 		&user.User{},
 		&user.TeamMember{},
+		&team.Activity{},
 		&user.RefreshToken{},
 		&user.PointsTransaction{},
+		&user.PointsReservation{},
 		&search.SearchHistory{},
 		&search.SearchBuffer{},
 		&search.SearchSession{},
+		&search.Monitor{},
+		&search.MonitorHit{},
+		&search.ImportColumnMap{},
+		&analysis.Report{},
 		&org.Agency{},
 		&org.Country{},
 	)
+	if err != nil {
+		return err
+	}
+
+	if migrateKeywords {
+		if _, err := intelligence.MigrateKeywordsToTags(DB); err != nil {
+			return err
+		}
+	}
+	return nil
 }
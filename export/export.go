@@ -0,0 +1,101 @@
+// Package export 表格数据导出
+//
+// 按行写出二维表数据，支持 XLSX、CSV、TXT 与 JSON Lines 四种格式。
+// 写入器以流的方式输出，调用方逐行写入，无需把全部数据放在内存中；
+// XLSX 只使用标准库生成（zip + SpreadsheetML），不依赖第三方组件。
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// 导出格式
+const (
+	FormatXLSX  = "xlsx"
+	FormatCSV   = "csv"
+	FormatTXT   = "txt"
+	FormatJSONL = "jsonl"
+)
+
+// Formats 全部导出格式
+var Formats = []string{FormatXLSX, FormatCSV, FormatTXT, FormatJSONL}
+
+// ErrUnknownFormat 未知的导出格式
+var ErrUnknownFormat = errors.New("export: unknown format")
+
+// Column 导出列
+type Column struct {
+	Key   string  // JSON Lines 中的字段名
+	Title string  // 表头（XLSX、CSV）与 TXT 中的字段标签
+	Width float64 // XLSX 列宽（字符数），0 表示默认宽度
+}
+
+// Writer 按行写出数据的写入器
+type Writer interface {
+	// WriteRow 写入一行，values 与列一一对应
+	WriteRow(values []string) error
+	// Close 写入结尾并刷新缓冲，不关闭底层的 io.Writer
+	Close() error
+}
+
+// NewWriter 创建指定格式的写入器，XLSX 与 CSV 会立即写出表头
+func NewWriter(format string, w io.Writer, columns []Column) (Writer, error) {
+	switch format {
+	case FormatXLSX:
+		return newXLSXWriter(w, columns)
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatTXT:
+		return newTXTWriter(w, columns), nil
+	case FormatJSONL:
+		return newJSONLWriter(w, columns), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
+
+// ParseFormat 规范化格式名称（不区分大小写，excel 等同于 xlsx，json 等同于 jsonl），为空时返回 xlsx
+func ParseFormat(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", FormatXLSX, "excel":
+		return FormatXLSX, nil
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatTXT, "text":
+		return FormatTXT, nil
+	case FormatJSONL, "json", "ndjson":
+		return FormatJSONL, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownFormat, s)
+	}
+}
+
+// ContentType 格式对应的 MIME 类型
+func ContentType(format string) string {
+	switch format {
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSONL:
+		return "application/x-ndjson; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Extension 格式对应的文件扩展名（含点号）
+func Extension(format string) string {
+	return "." + format
+}
+
+// checkRow 校验一行的列数
+func checkRow(values []string, columns []Column) error {
+	if len(values) != len(columns) {
+		return fmt.Errorf("export: expected %d values, got %d", len(columns), len(values))
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// utf8BOM 写在 CSV 开头，Excel 据此按 UTF-8 识别中文
+const utf8BOM = "\ufeff"
+
+// csvWriter CSV 写入器：UTF-8 带 BOM，首行为表头
+type csvWriter struct {
+	columns []Column
+	w       *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return nil, err
+	}
+	cw := &csvWriter{columns: columns, w: csv.NewWriter(w)}
+	// Excel 打开 CSV 时按 CRLF 换行
+	cw.w.UseCRLF = true
+
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Title
+	}
+	if err := cw.w.Write(header); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+// WriteRow 实现 Writer 接口
+func (cw *csvWriter) WriteRow(values []string) error {
+	if err := checkRow(values, cw.columns); err != nil {
+		return err
+	}
+	return cw.w.Write(values)
+}
+
+// Close 实现 Writer 接口
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// txtWriter 纯文本写入器：每条记录为若干“标签：值”行，记录之间以分隔线隔开，空值省略
+type txtWriter struct {
+	columns []Column
+	w       *bufio.Writer
+	rows    int
+}
+
+func newTXTWriter(w io.Writer, columns []Column) *txtWriter {
+	return &txtWriter{columns: columns, w: bufio.NewWriter(w)}
+}
+
+// txtSeparator 记录之间的分隔线
+var txtSeparator = strings.Repeat("-", 40)
+
+// WriteRow 实现 Writer 接口
+func (tw *txtWriter) WriteRow(values []string) error {
+	if err := checkRow(values, tw.columns); err != nil {
+		return err
+	}
+	if tw.rows > 0 {
+		tw.w.WriteString("\n" + txtSeparator + "\n\n")
+	}
+	tw.rows++
+	for i, col := range tw.columns {
+		v := strings.TrimSpace(values[i])
+		if v == "" {
+			continue
+		}
+		tw.w.WriteString(col.Title)
+		tw.w.WriteString("：")
+		// 多行的值另起一行
+		if strings.Contains(v, "\n") {
+			tw.w.WriteString("\n")
+		}
+		tw.w.WriteString(v)
+		tw.w.WriteString("\n")
+	}
+	return nil
+}
+
+// Close 实现 Writer 接口
+func (tw *txtWriter) Close() error {
+	return tw.w.Flush()
+}
+
+// jsonlWriter JSON Lines 写入器：每行一个对象，字段名为列的 Key
+type jsonlWriter struct {
+	columns []Column
+	w       *bufio.Writer
+}
+
+func newJSONLWriter(w io.Writer, columns []Column) *jsonlWriter {
+	return &jsonlWriter{columns: columns, w: bufio.NewWriter(w)}
+}
+
+// WriteRow 实现 Writer 接口，字段按列顺序输出
+func (jw *jsonlWriter) WriteRow(values []string) error {
+	if err := checkRow(values, jw.columns); err != nil {
+		return err
+	}
+	jw.w.WriteByte('{')
+	for i, col := range jw.columns {
+		if i > 0 {
+			jw.w.WriteByte(',')
+		}
+		jw.w.Write(marshalString(col.Key))
+		jw.w.WriteByte(':')
+		jw.w.Write(marshalString(values[i]))
+	}
+	_, err := jw.w.WriteString("}\n")
+	return err
+}
+
+// Close 实现 Writer 接口
+func (jw *jsonlWriter) Close() error {
+	return jw.w.Flush()
+}
+
+// marshalString 序列化字符串，不转义 HTML 字符
+func marshalString(s string) []byte {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s) // 字符串序列化不会失败
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n"))
+}
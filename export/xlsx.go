@@ -0,0 +1,189 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	xlsxMaxRows      = 1048576 // 单个工作表的最大行数（含表头）
+	xlsxMaxCellChars = 32767   // 单元格最多字符数，超出部分截断
+)
+
+// ErrTooManyRows 超出 XLSX 单个工作表的行数上限
+var ErrTooManyRows = errors.New("export: too many rows for xlsx")
+
+// xlsx 包中固定不变的部件
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`
+
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`
+
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`
+
+	// 样式 0 为默认样式，样式 1 为加粗的表头
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+		`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+		`</styleSheet>`
+)
+
+// xlsxWriter XLSX 写入器：单个工作表，首行为加粗并冻结的表头，单元格均为内联字符串
+type xlsxWriter struct {
+	columns []Column
+	zw      *zip.Writer
+	sheet   *bufio.Writer
+	rows    int
+}
+
+func newXLSXWriter(w io.Writer, columns []Column) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, p.body); err != nil {
+			return nil, err
+		}
+	}
+
+	// 工作表最后写入，行数据以流的方式追加
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	xw := &xlsxWriter{columns: columns, zw: zw, sheet: bufio.NewWriter(f)}
+	xw.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	xw.sheet.WriteString(`<sheetViews><sheetView workbookViewId="0">` +
+		`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	xw.writeCols()
+	xw.sheet.WriteString(`<sheetData>`)
+
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Title
+	}
+	if err := xw.writeRow(header, 1); err != nil {
+		return nil, err
+	}
+	return xw, nil
+}
+
+// writeCols 写入设置了宽度的列
+func (xw *xlsxWriter) writeCols() {
+	hasWidth := false
+	for _, col := range xw.columns {
+		if col.Width > 0 {
+			hasWidth = true
+			break
+		}
+	}
+	if !hasWidth {
+		return
+	}
+	xw.sheet.WriteString(`<cols>`)
+	for i, col := range xw.columns {
+		if col.Width > 0 {
+			fmt.Fprintf(xw.sheet, `<col min="%d" max="%d" width="%s" customWidth="1"/>`,
+				i+1, i+1, strconv.FormatFloat(col.Width, 'f', -1, 64))
+		}
+	}
+	xw.sheet.WriteString(`</cols>`)
+}
+
+// WriteRow 实现 Writer 接口
+func (xw *xlsxWriter) WriteRow(values []string) error {
+	if err := checkRow(values, xw.columns); err != nil {
+		return err
+	}
+	return xw.writeRow(values, 0)
+}
+
+// writeRow 写入一行，style 为单元格样式序号
+func (xw *xlsxWriter) writeRow(values []string, style int) error {
+	if xw.rows >= xlsxMaxRows {
+		return ErrTooManyRows
+	}
+	xw.rows++
+
+	row := strconv.Itoa(xw.rows)
+	fmt.Fprintf(xw.sheet, `<row r="%s">`, row)
+	for i, v := range values {
+		if v == "" {
+			continue
+		}
+		if runes := []rune(v); len(runes) > xlsxMaxCellChars {
+			v = string(runes[:xlsxMaxCellChars])
+		}
+		xw.sheet.WriteString(`<c r="` + columnName(i) + row + `" t="inlineStr"`)
+		if style != 0 {
+			fmt.Fprintf(xw.sheet, ` s="%d"`, style)
+		}
+		xw.sheet.WriteString(`><is><t xml:space="preserve">`)
+		// EscapeText 会把 XML 不允许的控制字符替换为 U+FFFD
+		if err := xml.EscapeText(xw.sheet, []byte(v)); err != nil {
+			return err
+		}
+		xw.sheet.WriteString(`</t></is></c>`)
+	}
+	_, err := xw.sheet.WriteString(`</row>`)
+	return err
+}
+
+// Close 实现 Writer 接口
+func (xw *xlsxWriter) Close() error {
+	xw.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := xw.sheet.Flush(); err != nil {
+		return err
+	}
+	return xw.zw.Close()
+}
+
+// columnName 列序号（从 0 开始）转换为 A、B、…、Z、AA 形式的列名
+func columnName(i int) string {
+	name := ""
+	for i >= 0 {
+		name = string(rune('A'+i%26)) + name
+		i = i/26 - 1
+	}
+	return name
+}
@@ -0,0 +1,264 @@
+package extract
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// noiseTags 不含正文的元素，打分前整体移除
+var noiseTags = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true, "iframe": true,
+	"object": true, "embed": true, "svg": true, "canvas": true, "link": true, "meta": true,
+	"form": true, "button": true, "input": true, "select": true, "textarea": true,
+	"nav": true, "header": true, "footer": true, "aside": true,
+}
+
+var (
+	// unlikelyHint class/id 表明是导航、页脚、分享栏等非正文区域（含政府网站常见的拼音命名）
+	unlikelyHint = regexp.MustCompile(`(?i)banner|breadcrumb|crumb|combx|comment|community|disqus|footer|header|menu|nav|related|remark|rss|share|shoutbox|sidebar|sponsor|popup|pager|pagination|copyright|toolbar|position|location|weizhi|daohang|fenxiang|erweima|qrcode|bottom`)
+	// maybeHint 命中 unlikelyHint 但同时命中此规则的元素保留，如 “main-nav-content”
+	maybeHint = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow|zoom|trs_editor|detail`)
+	// positiveHint 正文区域的 class/id，TRS_Editor、zoom 为政府网站常见的正文容器
+	positiveHint = regexp.MustCompile(`(?i)article|body|content|entry|hentry|main|page|post|text|blog|story|zoom|trs_editor|detail|zhengwen|wenzhang`)
+	// negativeHint 非正文区域的 class/id
+	negativeHint = regexp.MustCompile(`(?i)hidden|banner|combx|comment|com-|contact|foot|footnote|masthead|media|meta|promo|related|scroll|share|shoutbox|sidebar|sponsor|tags|tool|widget|nav|menu`)
+)
+
+// minParagraphLen 参与打分的段落最少字符数
+const minParagraphLen = 25
+
+// removeNoise 移除脚本、导航、隐藏元素等噪声节点
+func removeNoise(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.CommentNode || (c.Type == html.ElementNode && isNoise(c)) {
+			n.RemoveChild(c)
+		} else {
+			removeNoise(c)
+		}
+		c = next
+	}
+}
+
+// isNoise 元素是否为噪声
+func isNoise(n *html.Node) bool {
+	if noiseTags[n.Data] {
+		return true
+	}
+	if _, hidden := attrValue(n, "hidden"); hidden {
+		return true
+	}
+	style := strings.ReplaceAll(strings.ToLower(attr(n, "style")), " ", "")
+	if strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden") {
+		return true
+	}
+	switch n.Data {
+	case "body", "article", "main":
+		return false
+	}
+	hint := attr(n, "class") + " " + attr(n, "id")
+	return unlikelyHint.MatchString(hint) && !maybeHint.MatchString(hint)
+}
+
+// attrValue 读取属性，第二个返回值表示属性是否存在
+func attrValue(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// topCandidate 为段落的父节点与祖父节点累计得分，返回按链接密度修正后得分最高的节点
+func topCandidate(body *html.Node) *html.Node {
+	scores := make(map[*html.Node]float64)
+	var order []*html.Node
+	add := func(n *html.Node, score float64) {
+		if n == nil || n.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = initialScore(n)
+			order = append(order, n)
+		}
+		scores[n] += score
+	}
+
+	for _, p := range paragraphs(body) {
+		text := collapse(nodeText(p))
+		length := utf8.RuneCountInString(text)
+		if length < minParagraphLen {
+			continue
+		}
+		// 基础分 1，每个逗号/句号加 1，每 100 字加 1（最多 3）
+		score := 1 + float64(countPunctuation(text)) + math.Min(float64(length/100), 3)
+		add(p.Parent, score)
+		if p.Parent != nil {
+			add(p.Parent.Parent, score/2)
+		}
+	}
+
+	var (
+		top      *html.Node
+		topScore float64
+	)
+	for _, n := range order {
+		score := scores[n] * (1 - linkDensity(n))
+		scores[n] = score
+		if top == nil || score > topScore {
+			top, topScore = n, score
+		}
+	}
+	if top == nil {
+		return nil
+	}
+	return mergeSiblings(top, topScore, scores)
+}
+
+// mergeSiblings 正文被拆分到多个相邻容器时，把得分接近的兄弟节点与最佳节点合并到同一父节点下返回
+func mergeSiblings(top *html.Node, topScore float64, scores map[*html.Node]float64) *html.Node {
+	parent := top.Parent
+	if parent == nil {
+		return top
+	}
+	threshold := math.Max(10, topScore*0.2)
+
+	var keep []*html.Node
+	for s := parent.FirstChild; s != nil; s = s.NextSibling {
+		if s == top {
+			keep = append(keep, s)
+			continue
+		}
+		if s.Type != html.ElementNode {
+			continue
+		}
+		if score, ok := scores[s]; ok && score >= threshold {
+			keep = append(keep, s)
+			continue
+		}
+		if s.Data == "p" {
+			text := collapse(nodeText(s))
+			length := utf8.RuneCountInString(text)
+			density := linkDensity(s)
+			if (length >= 80 && density < 0.25) || (length > 0 && length < 80 && density == 0 && endsSentence(text)) {
+				keep = append(keep, s)
+			}
+		}
+	}
+	if len(keep) == 1 {
+		return top
+	}
+
+	wrapper := &html.Node{Type: html.ElementNode, Data: "div"}
+	for _, n := range keep {
+		parent.RemoveChild(n)
+		wrapper.AppendChild(n)
+	}
+	return wrapper
+}
+
+// paragraphs 收集段落级元素：p、pre、td，以及不含块级子元素的 div（政府网站常用 div + br 排版）
+func paragraphs(n *html.Node) []*html.Node {
+	var list []*html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "p", "pre", "td":
+				list = append(list, n)
+			case "div", "section":
+				if !hasBlockChild(n) {
+					list = append(list, n)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return list
+}
+
+// hasBlockChild 是否含块级子元素
+func hasBlockChild(n *html.Node) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && (blockTags[c.Data] || hasBlockChild(c)) {
+			return true
+		}
+	}
+	return false
+}
+
+// initialScore 节点的初始得分：按标签类型与 class/id 加减分
+func initialScore(n *html.Node) float64 {
+	var score float64
+	switch n.Data {
+	case "article":
+		score = 10
+	case "div", "section", "main":
+		score = 5
+	case "pre", "td", "blockquote":
+		score = 3
+	case "address", "ol", "ul", "dl", "dd", "dt", "li", "form":
+		score = -3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		score = -5
+	}
+	for _, hint := range []string{attr(n, "class"), attr(n, "id")} {
+		if hint == "" {
+			continue
+		}
+		if negativeHint.MatchString(hint) {
+			score -= 25
+		}
+		if positiveHint.MatchString(hint) {
+			score += 25
+		}
+	}
+	return score
+}
+
+// linkDensity 链接文字占全部文字的比例
+func linkDensity(n *html.Node) float64 {
+	total := utf8.RuneCountInString(collapse(nodeText(n)))
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "a" {
+			links += utf8.RuneCountInString(collapse(nodeText(n)))
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return math.Min(float64(links)/float64(total), 1)
+}
+
+// countPunctuation 统计逗号、句号等分句标点（含中文标点）
+func countPunctuation(s string) int {
+	count := 0
+	for _, r := range s {
+		switch r {
+		case ',', '，', '、', '。', '；', ';':
+			count++
+		}
+	}
+	return count
+}
+
+// endsSentence 文本是否以句末标点结尾
+func endsSentence(s string) bool {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r == '.' || r == '。' || r == '！' || r == '？' || r == '!' || r == '?'
+}
@@ -0,0 +1,122 @@
+// Package extract 网页正文抽取
+//
+// 参考 Readability 的做法：先去掉脚本、导航、页脚等噪声节点，再按文本长度、标点数量与链接密度
+// 为块级节点打分，取得分最高的节点作为正文；标题、发布日期、作者与语言优先读取 meta 标签，
+// 缺失时再从页面文本中识别。针对政府网站常见的模板（TRS 编辑器正文区、“发布时间：”字样、
+// 字体大小与打印按钮等）和中文页面（GBK 编码、全角空格缩进、中文标点）做了处理。
+package extract
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// ErrNoContent 页面中没有可识别的正文
+var ErrNoContent = errors.New("extract: no readable content")
+
+// excerptLen 摘录的最大字符数
+const excerptLen = 200
+
+// Article 抽取结果
+type Article struct {
+	Title       string    `json:"title"`
+	Content     string    `json:"content"`      // 正文纯文本，段落之间以换行分隔
+	Excerpt     string    `json:"excerpt"`      // 正文开头的摘录，用作摘要
+	PublishDate time.Time `json:"publish_date"` // 未识别到时为零值
+	Author      string    `json:"author"`
+	Language    string    `json:"language"` // 主语言标签，如 zh、en；无法判断时为空
+}
+
+// Extract 解析 HTML 并抽取正文
+// contentType 为响应头的 Content-Type，用于识别 GBK 等非 UTF-8 编码，缺失时按 meta 声明或内容推断
+func Extract(r io.Reader, contentType string) (*Article, error) {
+	reader, err := charset.NewReader(r, contentType)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := html.Parse(reader)
+	if err != nil {
+		return nil, err
+	}
+	return FromNode(doc)
+}
+
+// FromNode 从已解析的文档抽取正文，会修改传入的节点树
+func FromNode(doc *html.Node) (*Article, error) {
+	meta := collectMeta(doc)
+
+	body := findFirst(doc, "body")
+	if body == nil {
+		return nil, ErrNoContent
+	}
+	// 日期、作者常位于正文区之外（如标题下方的信息栏），在清理节点前从整页文本中识别
+	pageText := renderText(body)
+
+	removeNoise(body)
+	top := topCandidate(body)
+	if top == nil {
+		top = body
+	}
+	content := renderText(top)
+	if content == "" {
+		return nil, ErrNoContent
+	}
+
+	article := &Article{
+		Title:       meta.title(),
+		Content:     content,
+		Excerpt:     excerpt(content),
+		PublishDate: meta.publishDate(),
+		Author:      meta.author(),
+		Language:    meta.language(),
+	}
+	if article.PublishDate.IsZero() {
+		article.PublishDate = dateFromText(pageText)
+	}
+	if article.Author == "" {
+		article.Author = authorFromText(pageText)
+	}
+	if article.Language == "" {
+		article.Language = detectLanguage(content)
+	}
+	return article, nil
+}
+
+// excerpt 取正文开头的若干字符，段落换行替换为空格
+func excerpt(content string) string {
+	text := strings.Join(strings.Fields(content), " ")
+	if utf8.RuneCountInString(text) <= excerptLen {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:excerptLen])) + "…"
+}
+
+// findFirst 深度优先查找第一个指定标签的元素
+func findFirst(n *html.Node, tag string) *html.Node {
+	if n.Type == html.ElementNode && n.Data == tag {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findFirst(c, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// attr 读取元素属性，不存在时返回空字符串
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
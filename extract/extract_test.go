@@ -0,0 +1,120 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtractFixtures(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		contentType string
+		title       string
+		date        time.Time
+		author      string
+		language    string
+		contains    []string
+		excludes    []string
+	}{
+		{
+			// TRS 模板：正文在 TRS_Editor 中，日期只出现在“发布时间：”信息栏
+			name:     "trs editor",
+			file:     "gov_trs.html",
+			title:    "科技部关于印发《量子信息科技发展规划》的通知",
+			date:     time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC),
+			language: "zh",
+			contains: []string{
+				"各省、自治区、直辖市及计划单列市科技厅",
+				// 源码换行处的中文不应出现空格
+				"加快推进量子信息科技发展，科技部组织编制了",
+				"2024年3月12日",
+			},
+			excludes: []string{"当前位置", "字体", "分享到", "打印本页", "隐藏的统计文字", "相关文件", "京ICP备", "统计代码", "首页", "　"},
+		},
+		{
+			// GBK 页面，编码来自 meta 声明
+			name:     "gbk meta charset",
+			file:     "gov_gbk.html",
+			title:    "关于支持集成电路产业高质量发展的若干措施",
+			date:     time.Date(2023, 11, 8, 0, 0, 0, 0, time.UTC),
+			author:   "市发展改革委",
+			language: "zh",
+			contains: []string{"一、加大研发支持力度。", "\n二、支持重大项目建设。", "\n三、强化人才引进培养。"},
+			excludes: []string{"网站首页", "网站标识码", "【打印】"},
+		},
+		{
+			name:        "gbk header charset",
+			file:        "gov_gbk.html",
+			contentType: "text/html; charset=GBK",
+			title:       "关于支持集成电路产业高质量发展的若干措施",
+			date:        time.Date(2023, 11, 8, 0, 0, 0, 0, time.UTC),
+			author:      "市发展改革委",
+			language:    "zh",
+			contains:    []string{"单个企业每年最高不超过一千万元。"},
+		},
+		{
+			name:     "english press release",
+			file:     "press_release.html",
+			title:    "NSF invests $45 million in quantum testbeds",
+			date:     time.Date(2024, 5, 20, 14, 0, 0, 0, time.UTC),
+			author:   "Office of Public Affairs",
+			language: "en",
+			contains: []string{"announced today an investment of $45 million", "neutral atoms."},
+			excludes: []string{"Related news", "Eisenhower Avenue", "Funding"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			article, err := Extract(f, tt.contentType)
+			if err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+			if article.Title != tt.title {
+				t.Errorf("Title = %q, want %q", article.Title, tt.title)
+			}
+			if !article.PublishDate.Equal(tt.date) {
+				t.Errorf("PublishDate = %v, want %v", article.PublishDate, tt.date)
+			}
+			if article.Author != tt.author {
+				t.Errorf("Author = %q, want %q", article.Author, tt.author)
+			}
+			if article.Language != tt.language {
+				t.Errorf("Language = %q, want %q", article.Language, tt.language)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(article.Content, s) {
+					t.Errorf("Content does not contain %q:\n%s", s, article.Content)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(article.Content, s) {
+					t.Errorf("Content contains noise %q:\n%s", s, article.Content)
+				}
+			}
+			if article.Excerpt == "" || !strings.HasPrefix(article.Content, strings.TrimSuffix(strings.SplitN(article.Excerpt, " ", 2)[0], "…")) {
+				t.Errorf("Excerpt = %q", article.Excerpt)
+			}
+		})
+	}
+}
+
+func TestExtractNoContent(t *testing.T) {
+	for _, page := range []string{
+		"<html><head><title>空页面</title></head><body><script>x()</script></body></html>",
+		"<html><body><div class=\"footer\">版权所有</div><nav>首页</nav></body></html>",
+	} {
+		if _, err := Extract(strings.NewReader(page), "text/html"); err != ErrNoContent {
+			t.Errorf("Extract(%q) error = %v, want ErrNoContent", page, err)
+		}
+	}
+}
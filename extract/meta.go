@@ -0,0 +1,299 @@
+package extract
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// pageMeta 页面元信息：meta 标签、<title>、<h1>、<time> 与署名节点
+type pageMeta struct {
+	values   map[string]string // meta 标签，键为小写的 name / property / itemprop / http-equiv
+	lang     string            // <html lang>
+	titleTag string            // <title>
+	h1s      []string
+	times    []string // <time datetime> 与 itemprop=datePublished 的值
+	bylines  []string // class/itemprop/rel 含 author、byline 的短文本
+}
+
+// 读取标题、日期、作者时依次尝试的 meta 键
+// ArticleTitle、PubDate 为政府网站常用 TRS 内容管理系统输出的标签
+var (
+	titleMetaKeys = []string{"articletitle", "og:title", "twitter:title", "dc.title"}
+	dateMetaKeys  = []string{
+		"article:published_time", "og:published_time", "datepublished", "pubdate", "publishdate",
+		"publish_date", "publication_date", "firstpublishedtime", "dc.date.issued", "dc.date",
+		"dcterms.issued", "dcterms.created", "sailthru.date", "parsely-pub-date", "date",
+	}
+	authorMetaKeys = []string{"author", "article:author", "dc.creator", "byl", "sailthru.author", "parsely-author"}
+	langMetaKeys   = []string{"content-language", "dc.language", "language", "og:locale"}
+)
+
+// maxBylineLen 署名节点文本的最大长度，超过时认为不是署名
+const maxBylineLen = 60
+
+// collectMeta 遍历文档收集元信息
+func collectMeta(doc *html.Node) *pageMeta {
+	m := &pageMeta{values: make(map[string]string)}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "html":
+				m.lang = attr(n, "lang")
+			case "meta":
+				m.addMeta(n)
+			case "title":
+				if m.titleTag == "" {
+					m.titleTag = collapse(nodeText(n))
+				}
+			case "h1":
+				if text := collapse(nodeText(n)); text != "" {
+					m.h1s = append(m.h1s, text)
+				}
+			case "time":
+				if v := attr(n, "datetime"); v != "" {
+					m.times = append(m.times, v)
+				}
+			}
+			if strings.EqualFold(attr(n, "itemprop"), "datePublished") {
+				if v := attr(n, "content"); v != "" {
+					m.times = append(m.times, v)
+				} else if v := attr(n, "datetime"); v != "" {
+					m.times = append(m.times, v)
+				}
+			}
+			if isByline(n) {
+				if text := collapse(nodeText(n)); text != "" && utf8.RuneCountInString(text) <= maxBylineLen {
+					m.bylines = append(m.bylines, text)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return m
+}
+
+// addMeta 记录 meta 标签，同名标签保留第一个
+func (m *pageMeta) addMeta(n *html.Node) {
+	content := strings.TrimSpace(attr(n, "content"))
+	if content == "" {
+		return
+	}
+	for _, key := range []string{"name", "property", "itemprop", "http-equiv"} {
+		if k := strings.ToLower(strings.TrimSpace(attr(n, key))); k != "" {
+			if _, exists := m.values[k]; !exists {
+				m.values[k] = content
+			}
+		}
+	}
+}
+
+// first 返回 keys 中第一个存在的 meta 值
+func (m *pageMeta) first(keys []string) string {
+	for _, k := range keys {
+		if v := m.values[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// isByline 元素是否为署名节点
+func isByline(n *html.Node) bool {
+	if strings.EqualFold(attr(n, "itemprop"), "author") || strings.EqualFold(attr(n, "rel"), "author") {
+		return true
+	}
+	hint := strings.ToLower(attr(n, "class") + " " + attr(n, "id"))
+	return strings.Contains(hint, "byline") || strings.Contains(hint, "author")
+}
+
+// title 文章标题：meta 标签 > 与 <title> 一致的 <h1> > 去掉站点名的 <title>
+func (m *pageMeta) title() string {
+	if v := collapse(m.first(titleMetaKeys)); v != "" {
+		return v
+	}
+	stripped := stripSiteName(m.titleTag)
+	// <title> 本身含分隔符时按分隔符截断会丢失内容，此时 <h1> 更完整
+	for _, h := range m.h1s {
+		if strings.Contains(m.titleTag, h) && utf8.RuneCountInString(h) > utf8.RuneCountInString(stripped) {
+			return h
+		}
+	}
+	if stripped == "" && len(m.h1s) > 0 {
+		return m.h1s[0]
+	}
+	return stripped
+}
+
+// titleSeparators <title> 中常见的标题与站点名分隔符
+var titleSeparators = regexp.MustCompile(`\s+[|\-–—]\s+|\s*[_|｜]\s*|\s*——\s*|\s*－\s*`)
+
+// stripSiteName 去掉 <title> 中的站点名
+// 标题通常在前，如 “关于印发……的通知_国务院文件_中国政府网”、“Press Release | Ministry of Finance”，取第一段；
+// 后面某段明显更长时（站点名在前，如 “财政部 - 关于……的通知”）取最长的一段
+func stripSiteName(title string) string {
+	var parts []string
+	for _, p := range titleSeparators.Split(title, -1) {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 {
+		return strings.TrimSpace(title)
+	}
+	best := parts[0]
+	for _, p := range parts[1:] {
+		if utf8.RuneCountInString(p) > 2*utf8.RuneCountInString(parts[0]) && utf8.RuneCountInString(p) > utf8.RuneCountInString(best) {
+			best = p
+		}
+	}
+	return best
+}
+
+// publishDate 从 meta 标签与 <time> 元素读取发布日期
+func (m *pageMeta) publishDate() time.Time {
+	for _, k := range dateMetaKeys {
+		if t := parseDate(m.values[k]); !t.IsZero() {
+			return t
+		}
+	}
+	for _, v := range m.times {
+		if t := parseDate(v); !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// author 从 meta 标签与署名节点读取作者
+func (m *pageMeta) author() string {
+	for _, k := range authorMetaKeys {
+		// article:author 等可能是作者主页地址
+		if v := collapse(m.values[k]); v != "" && !strings.HasPrefix(v, "http") {
+			return v
+		}
+	}
+	for _, b := range m.bylines {
+		if v := cleanByline(b); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// bylinePrefix 署名前缀，如 “By ”、“作者：”
+var bylinePrefix = regexp.MustCompile(`(?i)^(by\s+|作者\s*[:：]\s*|撰稿\s*[:：]\s*)`)
+
+// cleanByline 去掉署名前缀
+func cleanByline(s string) string {
+	return strings.TrimSpace(bylinePrefix.ReplaceAllString(s, ""))
+}
+
+// language 从 <html lang> 与 meta 标签读取语言
+func (m *pageMeta) language() string {
+	if v := normalizeLang(m.lang); v != "" {
+		return v
+	}
+	return normalizeLang(m.first(langMetaKeys))
+}
+
+// normalizeLang 取语言标签的主标签并转为小写，如 zh-CN、zh_CN 均为 zh
+func normalizeLang(tag string) string {
+	tag = strings.TrimSpace(tag)
+	if i := strings.IndexAny(tag, "-_,; "); i >= 0 {
+		tag = tag[:i]
+	}
+	tag = strings.ToLower(tag)
+	if len(tag) < 2 || len(tag) > 3 {
+		return ""
+	}
+	for _, r := range tag {
+		if r < 'a' || r > 'z' {
+			return ""
+		}
+	}
+	return tag
+}
+
+// datePattern 文本中的日期：2024-01-02、2024/1/2、2024.01.02、2024年1月2日（可带时分秒），以及英文月份写法
+const datePattern = `\d{4}\s*[-/.年]\s*\d{1,2}\s*[-/.月]\s*\d{1,2}\s*日?(?:\s*\d{1,2}:\d{2}(?::\d{2})?)?` +
+	`|(?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)[a-z]*\.?\s+\d{1,2},?\s+\d{4}` +
+	`|\d{1,2}\s+(?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)[a-z]*\.?\s+\d{4}`
+
+// labeledDate 带标签的日期，如 “发布时间：2024-01-02”、“Published on March 3, 2024”
+// 只识别带标签的日期，避免误取页眉中的当天日期或版权年份
+var labeledDate = regexp.MustCompile(`(?i)(?:发布时间|发布日期|发文日期|成文日期|印发日期|日期|时间|published|publication date|release date|date|posted|released)` +
+	`(?:\s*[:：]\s*|\s+on\s+)(` + datePattern + `)`)
+
+// dateFromText 从页面文本中识别发布日期
+func dateFromText(text string) time.Time {
+	for _, match := range labeledDate.FindAllStringSubmatch(text, -1) {
+		if t := parseDate(match[1]); !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// authorInText 页面文本中的作者署名
+// 署名后常紧跟“【打印】”等功能按钮，压缩空白后与署名相连，因此方括号也作为结束符
+var authorInText = regexp.MustCompile(`(?:作者|撰稿人?|记者)\s*[:：]\s*([^\s|｜/,，;；()（）【】\[\]]{2,20})`)
+
+// authorFromText 从页面文本中识别作者
+func authorFromText(text string) string {
+	if match := authorInText.FindStringSubmatch(text); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// dateLayouts 规范化（年月日替换为 -）后尝试的日期格式
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-1-2 15:04:05",
+	"2006-1-2 15:04",
+	"2006-1-2",
+	time.RFC1123Z,
+	time.RFC1123,
+	"January 2, 2006",
+	"January 2 2006",
+	"Jan 2, 2006",
+	"Jan 2 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+}
+
+var (
+	dateSpaces    = regexp.MustCompile(`\s+`)
+	dateSeparator = regexp.MustCompile(`^(\d{4})\s*[/.年]\s*(\d{1,2})\s*[/.月]\s*(\d{1,2})\s*日?`)
+	monthAbbrDot  = regexp.MustCompile(`([A-Za-z])\.`) // “Jan. 2, 2006”
+)
+
+// parseDate 解析各种常见写法的日期，无法解析或年份明显不合理时返回零值
+func parseDate(s string) time.Time {
+	s = dateSeparator.ReplaceAllString(strings.TrimSpace(s), "$1-$2-$3 ")
+	s = strings.Replace(s, "Sept", "Sep", 1)
+	s = monthAbbrDot.ReplaceAllString(s, "$1")
+	s = strings.TrimSpace(dateSpaces.ReplaceAllString(s, " "))
+	if s == "" {
+		return time.Time{}
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			if t.Year() < 1990 || t.After(time.Now().AddDate(1, 0, 0)) {
+				return time.Time{}
+			}
+			return t
+		}
+	}
+	return time.Time{}
+}
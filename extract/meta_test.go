@@ -0,0 +1,95 @@
+package extract
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2024-03-15", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"2024/3/5", time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"2024.03.15", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"2024年3月15日", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"2024 年 3 月 15 日 09:05", time.Date(2024, 3, 15, 9, 5, 0, 0, time.UTC)},
+		{"2024-03-15 09:05:30", time.Date(2024, 3, 15, 9, 5, 30, 0, time.UTC)},
+		{"2024-03-15T09:05:30+08:00", time.Date(2024, 3, 15, 1, 5, 30, 0, time.UTC)},
+		{"March 3, 2024", time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)},
+		{"Sept. 3, 2024", time.Date(2024, 9, 3, 0, 0, 0, 0, time.UTC)},
+		{"3 Mar 2024", time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)},
+		{"1985-01-01", time.Time{}},
+		{"2024-13-01", time.Time{}},
+		{"昨天", time.Time{}},
+		{"", time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := parseDate(tt.in); !got.Equal(tt.want) {
+			t.Errorf("parseDate(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDateFromText(t *testing.T) {
+	tests := []struct {
+		text string
+		want time.Time
+	}{
+		{"来源：科技部 发布时间：2024-03-15 10:30 浏览次数：123", time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)},
+		{"发布日期： 2023年11月8日", time.Date(2023, 11, 8, 0, 0, 0, 0, time.UTC)},
+		{"成文日期：2023年10月30日\n发布日期：2023年11月8日", time.Date(2023, 10, 30, 0, 0, 0, 0, time.UTC)},
+		{"Published on March 3, 2024 by the press office", time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)},
+		// 不带标签的日期（页眉中的当天日期、版权年份）不识别
+		{"今天是2024年5月1日 星期三 ©2024", time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := dateFromText(tt.text); !got.Equal(tt.want) {
+			t.Errorf("dateFromText(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestAuthorFromText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"日期：2023-11-08 作者：市发展改革委 【打印】", "市发展改革委"},
+		{"作者：市发展改革委【打印】【关闭】", "市发展改革委"},
+		{"记者：张三 李四", "张三"},
+		{"来源：新华社", ""},
+	}
+	for _, tt := range tests {
+		if got := authorFromText(tt.text); got != tt.want {
+			t.Errorf("authorFromText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestStripSiteName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"关于印发的通知_国务院文件_中国政府网", "关于印发的通知"},
+		{"Press Release | Ministry of Finance", "Press Release"},
+		{"财政部 - 关于进一步加强财政科研项目经费管理的通知", "关于进一步加强财政科研项目经费管理的通知"},
+		{"量子规划——科技部", "量子规划"},
+		{"没有站点名的标题", "没有站点名的标题"},
+	}
+	for _, tt := range tests {
+		if got := stripSiteName(tt.in); got != tt.want {
+			t.Errorf("stripSiteName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeLang(t *testing.T) {
+	for in, want := range map[string]string{"zh-CN": "zh", "zh_CN": "zh", "EN": "en", "en-US,en;q=0.9": "en", "x": "", "中文": ""} {
+		if got := normalizeLang(in); got != want {
+			t.Errorf("normalizeLang(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
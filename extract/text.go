@@ -0,0 +1,173 @@
+package extract
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// blockTags 块级元素，输出文本时前后换行
+var blockTags = map[string]bool{
+	"address": true, "article": true, "blockquote": true, "dd": true, "div": true, "dl": true,
+	"dt": true, "figcaption": true, "figure": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "hr": true, "li": true, "main": true, "ol": true,
+	"p": true, "pre": true, "section": true, "table": true, "tr": true, "ul": true,
+}
+
+// skipTags 不输出文本的元素
+var skipTags = map[string]bool{"script": true, "style": true, "noscript": true, "template": true}
+
+// noiseLine 正文中的页面功能文字，如 “【字体：大 中 小】”、“【打印】【关闭】”、“分享到：”
+var noiseLine = regexp.MustCompile(`^(?:【\s*字体\s*[:：].*|字号\s*[:：].*|分享到.*|扫一扫.*|(?:当前位置|您现在的位置|您的位置)\s*[:：].*|(?:\s*[【\[]\s*[^】\]]{1,6}\s*[】\]]\s*)+|打印本页|关闭窗口)$`)
+
+// sourceNewlines HTML 源码中的换行，输出时视为空白
+var sourceNewlines = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+// nodeText 拼接节点下的全部文本，不含脚本与样式
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+		case html.ElementNode:
+			if skipTags[n.Data] {
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// renderText 输出节点的纯文本：块级元素与 <br> 分段，段内压缩空白，并去掉页面功能文字
+// 源码中的换行不分段，<pre> 内除外
+func renderText(n *html.Node) string {
+	var b strings.Builder
+	pre := 0
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			if pre > 0 {
+				b.WriteString(n.Data)
+			} else {
+				b.WriteString(sourceNewlines.Replace(n.Data))
+			}
+			return
+		case html.ElementNode:
+			if skipTags[n.Data] {
+				return
+			}
+			switch {
+			case n.Data == "br":
+				b.WriteByte('\n')
+				return
+			case n.Data == "td" || n.Data == "th":
+				b.WriteByte(' ')
+			case blockTags[n.Data]:
+				b.WriteByte('\n')
+				defer b.WriteByte('\n')
+			}
+			if n.Data == "pre" {
+				pre++
+				defer func() { pre-- }()
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+
+	var lines []string
+	for _, line := range strings.Split(b.String(), "\n") {
+		line = collapse(line)
+		if line == "" || noiseLine.MatchString(line) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// collapse 压缩空白（含 &nbsp; 与全角空格），并去掉中文字符之间因源码换行产生的空格
+func collapse(s string) string {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(fields[0])
+	for i := 1; i < len(fields); i++ {
+		last, _ := utf8.DecodeLastRuneInString(fields[i-1])
+		first, _ := utf8.DecodeRuneInString(fields[i])
+		if !isCJK(last) || !isCJK(first) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(fields[i])
+	}
+	return b.String()
+}
+
+// isCJK 是否为中日韩文字或全角标点
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		(r >= 0x3000 && r <= 0x303F) || (r >= 0xFF00 && r <= 0xFFEF)
+}
+
+// englishStopWords 用于判断拉丁字母文本是否为英文
+var englishStopWords = map[string]bool{
+	"the": true, "and": true, "of": true, "to": true, "in": true, "is": true,
+	"for": true, "that": true, "on": true, "with": true, "by": true, "are": true,
+}
+
+// detectLanguage 按文字分布推断语言：中文、日文、韩文按字符集判断，英文按常用词比例判断
+func detectLanguage(text string) string {
+	var han, kana, hangul, latin int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+	cjk := han + kana + hangul
+	if cjk > 0 && cjk*5 >= latin {
+		switch {
+		case hangul > han+kana:
+			return "ko"
+		case kana*10 > han:
+			return "ja"
+		default:
+			return "zh"
+		}
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) })
+	if len(words) == 0 {
+		return ""
+	}
+	stop := 0
+	for _, w := range words {
+		if englishStopWords[w] {
+			stop++
+		}
+	}
+	if stop*20 >= len(words) {
+		return "en"
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package extract
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestRenderTextNoise(t *testing.T) {
+	page := `<div>
+<p>当前位置：首页 &gt; 政策</p>
+<p>【字体：大 中 小】</p>
+<p>　　第一段正文，
+内容跨行。</p>
+<p>分享到：微信 微博</p>
+<p>第二段<br>换行 text</p>
+<pre>  代码
+  保留</pre>
+<p>【打印】【关闭】</p>
+<p>打印本页</p>
+<script>ignored()</script>
+</div>`
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "第一段正文，内容跨行。\n第二段\n换行 text\n代码\n保留"
+	if got := renderText(doc); got != want {
+		t.Errorf("renderText() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestCollapse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"  a   b\t c  ", "a b c"},
+		{"量子\n  计算", "量子计算"},
+		{"量子 NSF 计算", "量子 NSF 计算"},
+		{"　　全角缩进 段落", "全角缩进段落"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := collapse(tt.in); got != tt.want {
+			t.Errorf("collapse(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"关于支持集成电路产业高质量发展的若干措施", "zh"},
+		{"量子计算 quantum computing 研究进展", "zh"},
+		{"量子コンピュータの研究開発について", "ja"},
+		{"양자 컴퓨팅 연구 개발", "ko"},
+		{"The foundation announced an investment in quantum testbeds for the research community.", "en"},
+		{"Die Stiftung gibt eine Investition bekannt", ""},
+		{"12345", ""},
+	}
+	for _, tt := range tests {
+		if got := detectLanguage(tt.text); got != tt.want {
+			t.Errorf("detectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
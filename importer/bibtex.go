@@ -0,0 +1,325 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// bibtexMonths BibTeX 月份宏
+var bibtexMonths = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+// bibtexParser BibTeX 解析状态
+type bibtexParser struct {
+	src     []rune
+	pos     int
+	strings map[string]string // @string 定义的宏
+}
+
+// parseBibTeX 解析 BibTeX 题录：字段名转为小写，值去除外层括号或引号并做简单的 LaTeX 反转义；
+// 条目类型与引用键记为 entrytype、citekey 两列；没有 date 字段时由 year、month 合成
+func parseBibTeX(data []byte, maxRecords int) (*Table, error) {
+	data = bytes.TrimPrefix(data, []byte(utf8BOM))
+	p := &bibtexParser{src: []rune(string(data)), strings: make(map[string]string)}
+	table := &Table{}
+
+	entry := 0
+	for {
+		// 条目以 @ 开始，其间的文字均为注释
+		at := p.indexFrom('@')
+		if at < 0 {
+			break
+		}
+		p.pos = at + 1
+		typ := strings.ToLower(p.readIdent())
+		p.skipSpace()
+		if p.pos >= len(p.src) || (p.src[p.pos] != '{' && p.src[p.pos] != '(') {
+			continue
+		}
+		open, end := p.src[p.pos], '}'
+		if open == '(' {
+			end = ')'
+		}
+		p.pos++
+
+		switch typ {
+		case "comment", "preamble":
+			p.skipBalanced(open, end)
+			continue
+		case "string":
+			fields, err := p.readFields(end)
+			if err != nil {
+				return nil, err
+			}
+			for k, v := range fields.values {
+				p.strings[k] = v
+			}
+			continue
+		}
+
+		entry++
+		if err := checkLimit(len(table.Records), maxRecords); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		key := strings.TrimSpace(p.readUntil(',', end))
+		if p.pos < len(p.src) && p.src[p.pos] == ',' {
+			p.pos++
+		}
+		fields, err := p.readFields(end)
+		if err != nil {
+			return nil, fmt.Errorf("importer: invalid bibtex entry %d: %w", entry, err)
+		}
+
+		rec := Record{"entrytype": typ}
+		table.addColumn("entrytype")
+		if key != "" {
+			rec["citekey"] = key
+			table.addColumn("citekey")
+		}
+		for _, name := range fields.order {
+			if v := cleanField(name, fields.values[name]); v != "" {
+				rec[name] = v
+				table.addColumn(name)
+			}
+		}
+		if rec["date"] == "" {
+			if date := bibtexDate(rec["year"], rec["month"]); date != "" {
+				rec["date"] = date
+				table.addColumn("date")
+			}
+		}
+		table.add(rec, entry)
+	}
+	return table, nil
+}
+
+// bibtexFields 条目的字段，保持出现顺序
+type bibtexFields struct {
+	order  []string
+	values map[string]string
+}
+
+// readFields 读取 name = value 形式的字段直到条目结束
+// 值保持原样，@string 宏以原值参与 # 连接，条目的字段值由 cleanField 清理
+func (p *bibtexParser) readFields(end rune) (*bibtexFields, error) {
+	fields := &bibtexFields{values: make(map[string]string)}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, fmt.Errorf("unexpected end of input")
+		}
+		if p.src[p.pos] == end {
+			p.pos++
+			return fields, nil
+		}
+		if p.src[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+
+		name := strings.ToLower(p.readIdent())
+		if name == "" {
+			return nil, fmt.Errorf("expected field name at offset %d", p.pos)
+		}
+		p.skipSpace()
+		if p.pos >= len(p.src) || p.src[p.pos] != '=' {
+			return nil, fmt.Errorf("expected '=' after field %q", name)
+		}
+		p.pos++
+		value, err := p.readValue()
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", name, err)
+		}
+		if _, ok := fields.values[name]; !ok {
+			fields.order = append(fields.order, name)
+		}
+		fields.values[name] = value
+	}
+}
+
+// readValue 读取字段值：由 # 连接的若干部分，每部分为 {…}、"…"、数字或宏名
+func (p *bibtexParser) readValue() (string, error) {
+	var b strings.Builder
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return "", fmt.Errorf("unexpected end of input")
+		}
+		switch ch := p.src[p.pos]; {
+		case ch == '{':
+			p.pos++
+			b.WriteString(p.skipBalanced('{', '}'))
+		case ch == '"':
+			p.pos++
+			b.WriteString(p.readQuoted())
+		default:
+			word := p.readIdent()
+			if word == "" {
+				return "", fmt.Errorf("unexpected %q", ch)
+			}
+			if v, ok := p.strings[strings.ToLower(word)]; ok {
+				b.WriteString(v)
+			} else if m, ok := bibtexMonths[strings.ToLower(word)]; ok {
+				b.WriteString(strconv.Itoa(m))
+			} else {
+				b.WriteString(word)
+			}
+		}
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == '#' {
+			p.pos++
+			continue
+		}
+		return b.String(), nil
+	}
+}
+
+// skipBalanced 读取到与已读入的 open 配对的 end 为止，返回其间的内容（不含最外层括号）
+func (p *bibtexParser) skipBalanced(open, end rune) string {
+	start := p.pos
+	depth := 1
+	for p.pos < len(p.src) {
+		ch := p.src[p.pos]
+		p.pos++
+		switch {
+		case ch == '\\':
+			p.pos++ // 转义字符不参与配对
+		case ch == open:
+			depth++
+		case ch == end:
+			depth--
+			if depth == 0 {
+				return string(p.src[start : p.pos-1])
+			}
+		}
+	}
+	return string(p.src[start:])
+}
+
+// readQuoted 读取双引号内的值，花括号内的引号不结束字符串
+func (p *bibtexParser) readQuoted() string {
+	start := p.pos
+	depth := 0
+	for p.pos < len(p.src) {
+		ch := p.src[p.pos]
+		p.pos++
+		switch ch {
+		case '\\':
+			p.pos++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '"':
+			if depth <= 0 {
+				return string(p.src[start : p.pos-1])
+			}
+		}
+	}
+	return string(p.src[start:])
+}
+
+// readIdent 读取标识符（字段名、条目类型、宏名或数字）
+func (p *bibtexParser) readIdent() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		ch := p.src[p.pos]
+		if unicode.IsLetter(ch) || unicode.IsDigit(ch) || strings.ContainsRune("_-:.+/", ch) {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return string(p.src[start:p.pos])
+}
+
+// readUntil 读取到任一结束符之前
+func (p *bibtexParser) readUntil(stops ...rune) string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		for _, stop := range stops {
+			if p.src[p.pos] == stop {
+				return string(p.src[start:p.pos])
+			}
+		}
+		p.pos++
+	}
+	return string(p.src[start:])
+}
+
+// skipSpace 跳过空白
+func (p *bibtexParser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+// indexFrom 从当前位置查找字符
+func (p *bibtexParser) indexFrom(ch rune) int {
+	for i := p.pos; i < len(p.src); i++ {
+		if p.src[i] == ch {
+			return i
+		}
+	}
+	return -1
+}
+
+// latexReplacer 常见的 LaTeX 转义与连字符
+var latexReplacer = strings.NewReplacer(
+	`\&`, "&", `\%`, "%", `\_`, "_", `\$`, "$", `\#`, "#",
+	`\textendash`, "–", `\textemdash`, "—",
+	"---", "—", "--", "–", "~", " ",
+	"{", "", "}", "",
+)
+
+// cleanField 按字段类型清理值：链接类字段保持原样，其余做 LaTeX 反转义
+func cleanField(name, value string) string {
+	if bibtexVerbatim[name] {
+		return cleanVerbatim(value)
+	}
+	return cleanLaTeX(value)
+}
+
+// cleanLaTeX 去除值中的花括号与常见转义，并合并空白
+func cleanLaTeX(s string) string {
+	return strings.Join(strings.Fields(latexReplacer.Replace(s)), " ")
+}
+
+// bibtexVerbatim 链接类字段，其中的 ~、-- 等保持原样
+var bibtexVerbatim = map[string]bool{"url": true, "doi": true, "file": true, "pdf": true, "eprint": true}
+
+// verbatimReplacer 链接类字段只去除转义与 \url 命令
+var verbatimReplacer = strings.NewReplacer(
+	`\url`, "", `\_`, "_", `\%`, "%", `\&`, "&", `\#`, "#", "{", "", "}", "",
+)
+
+// cleanVerbatim 清理链接类字段
+func cleanVerbatim(s string) string {
+	return strings.TrimSpace(verbatimReplacer.Replace(s))
+}
+
+// bibtexDate 由 year、month 合成日期（如 2024-03），缺少年份时返回空
+func bibtexDate(year, month string) string {
+	year = strings.TrimSpace(year)
+	if len(year) != 4 {
+		return ""
+	}
+	if _, err := strconv.Atoi(year); err != nil {
+		return ""
+	}
+	month = strings.ToLower(strings.TrimSpace(month))
+	m, err := strconv.Atoi(month)
+	if err != nil && len(month) >= 3 {
+		m = bibtexMonths[month[:3]] // 兼容 March、mar. 等写法
+	}
+	if m < 1 || m > 12 {
+		return year
+	}
+	return fmt.Sprintf("%s-%02d", year, m)
+}
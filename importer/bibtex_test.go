@@ -0,0 +1,81 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBibTeX(t *testing.T) {
+	table, err := Parse(FormatBibTeX, readFixture(t, "macros.bib"), 0)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// @string、@comment 不计为条目，其中嵌套的 @ 也不会被当作条目
+	if want := []int{1, 2}; !reflect.DeepEqual(table.Rows, want) {
+		t.Errorf("Rows = %v, want %v", table.Rows, want)
+	}
+
+	want := []Record{
+		{
+			"entrytype":   "techreport",
+			"citekey":     "nsf2024",
+			"title":       "The NSF Strategy for Quantum Information Science",
+			"institution": "National Science Foundation",
+			"note":        "Report of the Board",
+			"year":        "2024",
+			"month":       "3",
+			"date":        "2024-03",
+			"url":         "https://example.gov/a_b~c",
+			"keywords":    "quantum, AI",
+			"abstract":    "Pages 1–5 & more",
+		},
+		{
+			"entrytype": "article",
+			"citekey":   "doe2023",
+			"title":     `A "quoted" title`,
+			"doi":       "10.1000/xyz.1",
+			"date":      "2023-05-01",
+		},
+	}
+	if !reflect.DeepEqual(table.Records, want) {
+		t.Errorf("Records = %q, want %q", table.Records, want)
+	}
+}
+
+func TestParseBibTeXInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"unterminated entry", "@article{a, title = {x}"},
+		{"missing equals", "@article{a, title {x}}"},
+		{"bad value", "@article{a, title = ,}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(FormatBibTeX, []byte(tt.data), 0); err == nil {
+				t.Error("Parse() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestBibTeXDate(t *testing.T) {
+	tests := []struct {
+		year, month, want string
+	}{
+		{"2024", "3", "2024-03"},
+		{"2024", "March", "2024-03"},
+		{"2024", "sep.", "2024-09"},
+		{"2024", "", "2024"},
+		{"2024", "13", "2024"},
+		{"24", "3", ""},
+		{"", "3", ""},
+	}
+	for _, tt := range tests {
+		if got := bibtexDate(tt.year, tt.month); got != tt.want {
+			t.Errorf("bibtexDate(%q, %q) = %q, want %q", tt.year, tt.month, got, tt.want)
+		}
+	}
+}
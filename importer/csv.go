@@ -0,0 +1,137 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// utf8BOM Excel 另存为 “CSV UTF-8” 时写在开头的 BOM
+const utf8BOM = "\ufeff"
+
+// parseCSV 解析 CSV：首个非空行为表头，分隔符从表头行中识别（逗号、分号或制表符）
+// 内容不是合法 UTF-8 时按 GB18030 解码（中文版 Excel 默认的 “CSV” 编码）
+func parseCSV(data []byte, maxRecords int) (*Table, error) {
+	data = bytes.TrimPrefix(data, []byte(utf8BOM))
+	if !utf8.Valid(data) {
+		decoded, err := simplifiedchinese.GB18030.NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("importer: csv is neither UTF-8 nor GB18030: %w", err)
+		}
+		data = decoded
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = detectDelimiter(data)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	t := &tabular{table: &Table{}}
+	for {
+		values, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("importer: invalid csv: %w", err)
+		}
+		line, _ := r.FieldPos(0)
+		if err := t.addRow(values, line, maxRecords); err != nil {
+			return nil, err
+		}
+	}
+	return t.table, nil
+}
+
+// detectDelimiter 按首行中出现次数最多的候选分隔符确定分隔符，默认逗号
+func detectDelimiter(data []byte) rune {
+	first := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		first = data[:i]
+	}
+	best, bestCount := ',', 0
+	for _, d := range []rune{',', ';', '\t'} {
+		if n := strings.Count(string(first), string(d)); n > bestCount {
+			best, bestCount = d, n
+		}
+	}
+	return best
+}
+
+// tabular 按 “表头 + 数据行” 组织的表格记录构造器，CSV 与 XLSX 共用
+type tabular struct {
+	table   *Table
+	headers []string // 为 nil 表示尚未读到表头
+}
+
+// addRow 添加一行：首个非空行作为表头，之后的非空行作为记录；row 为行号
+func (t *tabular) addRow(values []string, row, maxRecords int) error {
+	if isBlankRow(values) {
+		return nil
+	}
+	if t.headers == nil {
+		t.setHeaders(values)
+		return nil
+	}
+	if err := checkLimit(len(t.table.Records), maxRecords); err != nil {
+		return err
+	}
+
+	rec := make(Record, len(values))
+	for i, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if i >= len(t.headers) {
+			// 超出表头的列按列序号命名
+			t.headers = append(t.headers, columnLabel(len(t.headers)))
+			t.table.addColumn(t.headers[len(t.headers)-1])
+		}
+		rec[t.headers[i]] = v
+	}
+	t.table.add(rec, row)
+	return nil
+}
+
+// setHeaders 设置表头：空表头按列序号命名，重复的表头追加序号
+func (t *tabular) setHeaders(values []string) {
+	t.headers = make([]string, len(values))
+	used := make(map[string]int)
+	for i, v := range values {
+		name := strings.TrimSpace(v)
+		if name == "" {
+			name = columnLabel(i)
+		}
+		if n := used[name]; n > 0 {
+			used[name] = n + 1
+			name = name + "_" + strconv.Itoa(n+1)
+		} else {
+			used[name] = 1
+		}
+		t.headers[i] = name
+		t.table.addColumn(name)
+	}
+}
+
+// columnLabel 没有表头的列的名称，如 “列3”
+func columnLabel(i int) string {
+	return "列" + strconv.Itoa(i+1)
+}
+
+// isBlankRow 是否为空行
+func isBlankRow(values []string) bool {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package importer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseCSV(t *testing.T) {
+	table, err := Parse(FormatCSV, readFixture(t, "bom_quoted.csv"), 0)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// BOM 不进入首列列名，超出表头的列按列序号命名
+	wantColumns := []string{"标题", "链接", "发布机构", "发布日期", "关键词", "摘要", "列7"}
+	if !reflect.DeepEqual(table.Columns, wantColumns) {
+		t.Errorf("Columns = %q, want %q", table.Columns, wantColumns)
+	}
+	// 行号含表头，跨行的引号字段按起始行计，末尾的空行跳过
+	if want := []int{2, 3}; !reflect.DeepEqual(table.Rows, want) {
+		t.Errorf("Rows = %v, want %v", table.Rows, want)
+	}
+
+	want := []Record{
+		{
+			"标题":   "Quantum, AI and chips",
+			"链接":   "https://example.gov/a",
+			"发布机构": `Office of "Science"`,
+			"发布日期": "2024-03-15",
+			"关键词":  "量子；人工智能、芯片",
+		},
+		{
+			"标题":   "Multi-line\ntitle",
+			"链接":   "10.1234/abc.5",
+			"发布机构": "NSF",
+			"发布日期": "2024年1月2日",
+			"关键词":  "quantum; Quantum; AI",
+			"摘要":   "摘要，含逗号, 和换行",
+			"列7":   "extra",
+		},
+	}
+	if !reflect.DeepEqual(table.Records, want) {
+		t.Errorf("Records = %q, want %q", table.Records, want)
+	}
+}
+
+func TestParseCSVDelimiterAndEncoding(t *testing.T) {
+	gbk, err := simplifiedchinese.GB18030.NewEncoder().Bytes([]byte("标题;链接\n量子信息;https://example.gov/q\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want Record
+	}{
+		{"semicolon", []byte("title;url\nA, B;https://example.gov/a\n"), Record{"title": "A, B", "url": "https://example.gov/a"}},
+		{"tab", []byte("title\turl\nA; B\thttps://example.gov/a\n"), Record{"title": "A; B", "url": "https://example.gov/a"}},
+		{"duplicate headers", []byte("title,title,\nA,B,C\n"), Record{"title": "A", "title_2": "B", "列3": "C"}},
+		{"gb18030", gbk, Record{"标题": "量子信息", "链接": "https://example.gov/q"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := Parse(FormatCSV, tt.data, 0)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(table.Records) != 1 || !reflect.DeepEqual(table.Records[0], tt.want) {
+				t.Errorf("Records = %q, want [%q]", table.Records, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCSVLimits(t *testing.T) {
+	if _, err := Parse(FormatCSV, []byte("title,url\n"), 0); !errors.Is(err, ErrNoRecords) {
+		t.Errorf("header only: error = %v, want ErrNoRecords", err)
+	}
+	if _, err := Parse(FormatCSV, readFixture(t, "bom_quoted.csv"), 1); !errors.Is(err, ErrTooManyRecords) {
+		t.Errorf("limit 1: error = %v, want ErrTooManyRecords", err)
+	}
+}
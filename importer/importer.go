@@ -0,0 +1,134 @@
+// Package importer 文献与表格文件解析
+//
+// 把分析人员手中的表格（CSV、XLSX）和文献管理软件导出的题录（RIS、BibTeX）解析为记录，
+// 每条记录是“列名 → 值”的映射：表格的列名取表头，RIS 取两字母标签（如 TI、UR），
+// BibTeX 取小写的字段名（如 title、url）。再按列映射（Mapping）转换为情报字段。
+// XLSX 只使用标准库解析（zip + SpreadsheetML），不依赖第三方组件。
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// 文件格式
+const (
+	FormatCSV    = "csv"
+	FormatXLSX   = "xlsx"
+	FormatRIS    = "ris"
+	FormatBibTeX = "bibtex"
+)
+
+// Formats 全部支持的文件格式
+var Formats = []string{FormatCSV, FormatXLSX, FormatRIS, FormatBibTeX}
+
+var (
+	// ErrUnknownFormat 未知或无法识别的文件格式
+	ErrUnknownFormat = errors.New("importer: unknown format")
+	// ErrTooManyRecords 记录数超过上限
+	ErrTooManyRecords = errors.New("importer: too many records")
+	// ErrNoRecords 文件中没有任何记录
+	ErrNoRecords = errors.New("importer: no records found")
+)
+
+// Record 一条记录，键为列名，值已去除首尾空白；同名的多个值以 "; " 连接
+type Record map[string]string
+
+// Table 解析结果
+type Table struct {
+	Format  string   `json:"format"`
+	Columns []string `json:"columns"` // 出现过的列名，按首次出现的顺序
+	Records []Record `json:"-"`
+	Rows    []int    `json:"-"` // 每条记录在文件中的位置：表格为行号（从 1 开始，含表头），题录为条目序号
+
+	seen map[string]bool
+}
+
+// addColumn 登记列名，已登记的忽略
+func (t *Table) addColumn(name string) {
+	if t.seen == nil {
+		t.seen = make(map[string]bool)
+	}
+	if !t.seen[name] {
+		t.seen[name] = true
+		t.Columns = append(t.Columns, name)
+	}
+}
+
+// add 追加一条记录
+func (t *Table) add(rec Record, row int) {
+	t.Records = append(t.Records, rec)
+	t.Rows = append(t.Rows, row)
+}
+
+// ParseFormat 规范化格式名称（不区分大小写，bib 等同于 bibtex，excel 等同于 xlsx）
+func ParseFormat(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX, "excel":
+		return FormatXLSX, nil
+	case FormatRIS:
+		return FormatRIS, nil
+	case FormatBibTeX, "bib":
+		return FormatBibTeX, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownFormat, s)
+	}
+}
+
+// DetectFormat 确定文件格式：显式指定时以指定为准，否则按文件扩展名判断
+func DetectFormat(filename, format string) (string, error) {
+	if strings.TrimSpace(format) != "" {
+		return ParseFormat(format)
+	}
+	ext := strings.TrimPrefix(strings.ToLower(path.Ext(filename)), ".")
+	switch ext {
+	case "csv", "tsv":
+		return FormatCSV, nil
+	case "bibtex", "bib":
+		return FormatBibTeX, nil
+	case "", "txt":
+		return "", fmt.Errorf("%w: cannot detect from file name %q", ErrUnknownFormat, filename)
+	default:
+		return ParseFormat(ext)
+	}
+}
+
+// Parse 解析文件内容，maxRecords 大于 0 时超过该条数返回 ErrTooManyRecords
+func Parse(format string, data []byte, maxRecords int) (*Table, error) {
+	var (
+		table *Table
+		err   error
+	)
+	switch format {
+	case FormatCSV:
+		table, err = parseCSV(data, maxRecords)
+	case FormatXLSX:
+		table, err = parseXLSX(data, maxRecords)
+	case FormatRIS:
+		table, err = parseRIS(data, maxRecords)
+	case FormatBibTeX:
+		table, err = parseBibTeX(data, maxRecords)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(table.Records) == 0 {
+		return nil, ErrNoRecords
+	}
+	table.Format = format
+	return table, nil
+}
+
+// checkLimit 判断是否已达到记录数上限
+func checkLimit(count, maxRecords int) error {
+	if maxRecords > 0 && count >= maxRecords {
+		return fmt.Errorf("%w (limit %d)", ErrTooManyRecords, maxRecords)
+	}
+	return nil
+}
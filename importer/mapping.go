@@ -0,0 +1,258 @@
+package importer
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 可映射的情报字段
+const (
+	FieldTitle       = "title"
+	FieldURL         = "url"
+	FieldSource      = "source"
+	FieldContent     = "content"
+	FieldSummary     = "summary"
+	FieldPublishDate = "publish_date"
+	FieldPDFURL      = "pdf_url"
+	FieldKeywords    = "keywords"
+)
+
+// Fields 全部可映射的字段
+var Fields = []string{FieldTitle, FieldURL, FieldSource, FieldContent, FieldSummary, FieldPublishDate, FieldPDFURL, FieldKeywords}
+
+// fieldAliases 自动识别列映射时各字段的候选列名（不区分大小写），靠前的优先
+// 同时覆盖常见的中英文表头、RIS 标签与 BibTeX 字段名
+var fieldAliases = map[string][]string{
+	FieldTitle:       {"标题", "题名", "篇名", "名称", "title", "TI", "T1", "primary title"},
+	FieldURL:         {"链接", "原文链接", "网址", "地址", "url", "link", "UR"},
+	FieldSource:      {"来源", "机构", "发布机构", "发文机构", "出版者", "source", "agency", "publisher", "institution", "organization", "journal", "booktitle", "JO", "JF", "T2", "PB"},
+	FieldContent:     {"正文", "内容", "全文", "content", "text", "body"},
+	FieldSummary:     {"摘要", "简介", "summary", "abstract", "description", "AB", "N2"},
+	FieldPublishDate: {"发布日期", "发布时间", "发文日期", "日期", "时间", "publish_date", "published", "date", "DA", "PY", "Y1", "year"},
+	FieldPDFURL:      {"PDF链接", "pdf", "pdf_url", "L1"},
+	FieldKeywords:    {"关键词", "关键字", "标签", "keywords", "tags", "KW"},
+}
+
+// doiColumns 链接为空时用于生成 https://doi.org/ 链接的列
+var doiColumns = []string{"DOI", "doi", "DO"}
+
+// Mapping 列映射：情报字段 → 文件中的列名
+type Mapping map[string]string
+
+// IsField 是否为可映射的字段
+func IsField(name string) bool {
+	_, ok := fieldAliases[name]
+	return ok
+}
+
+// Validate 校验映射的字段名
+func (m Mapping) Validate() error {
+	for field := range m {
+		if !IsField(field) {
+			return fmt.Errorf("unknown field %q, expected one of: %s", field, strings.Join(Fields, ", "))
+		}
+	}
+	return nil
+}
+
+// DetectMapping 按列名自动识别列映射
+func DetectMapping(columns []string) Mapping {
+	byName := make(map[string]string, len(columns))
+	for _, col := range columns {
+		key := strings.ToLower(strings.TrimSpace(col))
+		if _, ok := byName[key]; !ok {
+			byName[key] = col
+		}
+	}
+
+	m := Mapping{}
+	used := make(map[string]bool)
+	for _, field := range Fields {
+		for _, alias := range fieldAliases[field] {
+			if col, ok := byName[strings.ToLower(alias)]; ok && !used[col] {
+				m[field] = col
+				used[col] = true
+				break
+			}
+		}
+	}
+	return m
+}
+
+// Merge 以 override 中的映射覆盖自动识别的结果；override 中值为空的字段表示不导入该字段
+func (m Mapping) Merge(override Mapping) Mapping {
+	merged := Mapping{}
+	for field, col := range m {
+		merged[field] = col
+	}
+	for field, col := range override {
+		if col == "" {
+			delete(merged, field)
+		} else {
+			merged[field] = col
+		}
+	}
+	return merged
+}
+
+// Missing 映射中引用但文件中不存在的列
+func (m Mapping) Missing(columns []string) []string {
+	present := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		present[col] = true
+	}
+	var missing []string
+	for _, field := range Fields {
+		if col, ok := m[field]; ok && !present[col] {
+			missing = append(missing, col)
+		}
+	}
+	return missing
+}
+
+// Item 按列映射转换后的一条情报
+type Item struct {
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Source      string     `json:"source,omitempty"`
+	Content     string     `json:"content,omitempty"`
+	Summary     string     `json:"summary,omitempty"`
+	PublishDate *time.Time `json:"publish_date,omitempty"`
+	PDFURL      string     `json:"pdf_url,omitempty"`
+	Keywords    string     `json:"keywords,omitempty"` // 逗号分隔
+}
+
+// FieldError 字段转换错误
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error 实现 error 接口
+func (e *FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// Apply 按列映射转换一条记录
+// 链接为空时以 DOI 列生成 https://doi.org/ 链接；关键词统一为逗号分隔；日期支持多种常见写法
+func (m Mapping) Apply(rec Record) (Item, error) {
+	get := func(field string) string {
+		if col, ok := m[field]; ok {
+			return strings.TrimSpace(rec[col])
+		}
+		return ""
+	}
+
+	item := Item{
+		Title:    get(FieldTitle),
+		URL:      normalizeLink(get(FieldURL)),
+		Source:   get(FieldSource),
+		Content:  get(FieldContent),
+		Summary:  get(FieldSummary),
+		PDFURL:   get(FieldPDFURL),
+		Keywords: normalizeKeywords(get(FieldKeywords)),
+	}
+	if item.URL == "" {
+		for _, col := range doiColumns {
+			if doi := rec[col]; doi != "" {
+				item.URL = normalizeLink(doi)
+				break
+			}
+		}
+	}
+	if raw := get(FieldPublishDate); raw != "" {
+		t, err := ParseDate(raw)
+		if err != nil {
+			return item, &FieldError{Field: FieldPublishDate, Message: fmt.Sprintf("unrecognized date %q", raw)}
+		}
+		item.PublishDate = &t
+	}
+	return item, nil
+}
+
+// doiPattern DOI 编号
+var doiPattern = regexp.MustCompile(`^(?i:doi:\s*)?(10\.\d{4,9}/\S+)$`)
+
+// normalizeLink 把裸 DOI 转为 https://doi.org/ 链接，其他值原样返回
+// RIS 的 UR 可能包含多个以 "; " 连接的链接，只取第一个
+func normalizeLink(s string) string {
+	if i := strings.Index(s, "; "); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSpace(s)
+	if m := doiPattern.FindStringSubmatch(s); m != nil {
+		return "https://doi.org/" + m[1]
+	}
+	return s
+}
+
+// keywordSeparators 关键词分隔符
+var keywordSeparators = regexp.MustCompile(`[;,，；、]+`)
+
+// normalizeKeywords 关键词统一为逗号分隔并去重
+func normalizeKeywords(s string) string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, kw := range keywordSeparators.Split(s, -1) {
+		kw = strings.TrimSpace(kw)
+		if kw == "" || seen[strings.ToLower(kw)] {
+			continue
+		}
+		seen[strings.ToLower(kw)] = true
+		out = append(out, kw)
+	}
+	return strings.Join(out, ",")
+}
+
+// dateLayouts ParseDate 依次尝试的日期格式
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-1-2",
+	"2006/1/2 15:04:05",
+	"2006/1/2 15:04",
+	"2006/1/2",
+	"2006.1.2",
+	"2006年1月2日",
+	"2006-1",
+	"2006/1",
+	"2006年1月",
+	"2006",
+	"2 January 2006",
+	"2 Jan 2006",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"January 2006",
+	"Jan 2006",
+}
+
+// excelEpoch Excel 日期序列号的起点（1900 日期系统，已计入 1900 年 2 月 29 日的历史误差）
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// ParseDate 解析常见的日期写法，包括 Excel 日期序列号与 RIS 的 2024/03/15/ 形式
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	// RIS 日期以 / 结尾，缺失的部分留空（如 2024///）
+	if strings.HasSuffix(s, "/") {
+		s = strings.TrimRight(s, "/")
+	}
+
+	// Excel 日期序列号：1954 年至 2119 年之间
+	if f, err := strconv.ParseFloat(s, 64); err == nil && f >= 20000 && f < 80000 {
+		days := math.Floor(f)
+		secs := math.Round((f - days) * 86400)
+		return excelEpoch.AddDate(0, 0, int(days)).Add(time.Duration(secs) * time.Second), nil
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", s)
+}
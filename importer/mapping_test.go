@@ -0,0 +1,159 @@
+package importer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDetectMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []string
+		want    Mapping
+	}{
+		{
+			name:    "chinese headers",
+			columns: []string{"序号", "标题", "原文链接", "发布机构", "发布日期", "摘要", "正文", "关键词", "PDF链接"},
+			want: Mapping{
+				FieldTitle:       "标题",
+				FieldURL:         "原文链接",
+				FieldSource:      "发布机构",
+				FieldPublishDate: "发布日期",
+				FieldSummary:     "摘要",
+				FieldContent:     "正文",
+				FieldKeywords:    "关键词",
+				FieldPDFURL:      "PDF链接",
+			},
+		},
+		{
+			// 多个候选列时取靠前的别名
+			name:    "alias priority",
+			columns: []string{"机构", "来源", "时间", "日期", "名称", "标题"},
+			want:    Mapping{FieldTitle: "标题", FieldSource: "来源", FieldPublishDate: "日期"},
+		},
+		{
+			name:    "case insensitive",
+			columns: []string{" Title ", "URL", "Abstract", "PDF"},
+			want:    Mapping{FieldTitle: " Title ", FieldURL: "URL", FieldSummary: "Abstract", FieldPDFURL: "PDF"},
+		},
+		{
+			name:    "bibtex fields",
+			columns: []string{"entrytype", "citekey", "title", "institution", "year", "url", "doi"},
+			want:    Mapping{FieldTitle: "title", FieldSource: "institution", FieldPublishDate: "year", FieldURL: "url"},
+		},
+		{
+			name:    "nothing recognized",
+			columns: []string{"备注", "列2"},
+			want:    Mapping{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectMapping(tt.columns); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectMapping() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMappingMergeAndMissing(t *testing.T) {
+	detected := Mapping{FieldTitle: "标题", FieldURL: "链接", FieldSummary: "摘要"}
+	merged := detected.Merge(Mapping{FieldURL: "原文地址", FieldSummary: ""})
+
+	want := Mapping{FieldTitle: "标题", FieldURL: "原文地址"}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("Merge() = %v, want %v", merged, want)
+	}
+	if len(detected) != 3 {
+		t.Errorf("Merge() modified the receiver: %v", detected)
+	}
+	if got := merged.Missing([]string{"标题", "链接"}); !reflect.DeepEqual(got, []string{"原文地址"}) {
+		t.Errorf("Missing() = %q, want [原文地址]", got)
+	}
+	if err := (Mapping{"author": "作者"}).Validate(); err == nil {
+		t.Error("Validate() error = nil, want error for unknown field")
+	}
+}
+
+func TestMappingApply(t *testing.T) {
+	table, err := Parse(FormatCSV, readFixture(t, "bom_quoted.csv"), 0)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	m := DetectMapping(table.Columns)
+
+	items := make([]Item, len(table.Records))
+	for i, rec := range table.Records {
+		if items[i], err = m.Apply(rec); err != nil {
+			t.Fatalf("Apply(record %d) error = %v", i, err)
+		}
+	}
+
+	date := func(y int, mo time.Month, d int) *time.Time {
+		t := time.Date(y, mo, d, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+	want := []Item{
+		{
+			Title:       "Quantum, AI and chips",
+			URL:         "https://example.gov/a",
+			Source:      `Office of "Science"`,
+			PublishDate: date(2024, 3, 15),
+			Keywords:    "量子,人工智能,芯片",
+		},
+		{
+			// 裸 DOI 转为链接，关键词去重时不区分大小写
+			Title:       "Multi-line\ntitle",
+			URL:         "https://doi.org/10.1234/abc.5",
+			Source:      "NSF",
+			Summary:     "摘要，含逗号, 和换行",
+			PublishDate: date(2024, 1, 2),
+			Keywords:    "quantum,AI",
+		},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("Apply() = %+v, want %+v", items, want)
+	}
+
+	_, err = m.Apply(Record{"标题": "x", "发布日期": "上周"})
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Field != FieldPublishDate {
+		t.Errorf("Apply() error = %v, want FieldError on %s", err, FieldPublishDate)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2024-03-15", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"2024/3/5 08:30", time.Date(2024, 3, 5, 8, 30, 0, 0, time.UTC)},
+		{"2024年3月15日", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"2024年3月", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"2024/03/15/", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"2024///", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"15 March 2024", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"45292", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"45292.5", time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{"2024-03-15T08:00:00+08:00", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseDate(tt.in)
+		if err != nil {
+			t.Errorf("ParseDate(%q) error = %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseDate(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "上周", "12345"} {
+		if _, err := ParseDate(in); err == nil {
+			t.Errorf("ParseDate(%q) error = nil, want error", in)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// risLine RIS 的标签行：两位大写字母或数字的标签、两个空格、连字符，之后为值
+var risLine = regexp.MustCompile(`^([A-Z][A-Z0-9])  -(?: (.*))?$`)
+
+// parseRIS 解析 RIS 题录：TY 开始一条记录，ER 结束；同一标签出现多次（如 AU、KW）时以 "; " 连接，
+// 不符合标签格式的行视为上一个值的续行
+func parseRIS(data []byte, maxRecords int) (*Table, error) {
+	data = bytes.TrimPrefix(data, []byte(utf8BOM))
+	table := &Table{}
+
+	var (
+		rec     Record
+		lastTag string
+		entry   int
+	)
+	flush := func() {
+		if len(rec) > 0 {
+			table.add(rec, entry)
+		}
+		rec, lastTag = nil, ""
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		m := risLine.FindStringSubmatch(line)
+		if m == nil {
+			// 续行
+			if rec != nil && lastTag != "" && strings.TrimSpace(line) != "" {
+				rec[lastTag] = strings.TrimSpace(rec[lastTag] + " " + strings.TrimSpace(line))
+			}
+			continue
+		}
+
+		tag, value := m[1], strings.TrimSpace(m[2])
+		switch tag {
+		case "TY":
+			flush()
+			if err := checkLimit(len(table.Records), maxRecords); err != nil {
+				return nil, err
+			}
+			entry++
+			rec = Record{}
+		case "ER":
+			flush()
+			continue
+		}
+		if rec == nil || value == "" {
+			continue
+		}
+		table.addColumn(tag)
+		if prev, ok := rec[tag]; ok {
+			rec[tag] = prev + "; " + value
+		} else {
+			rec[tag] = value
+		}
+		lastTag = tag
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return table, nil
+}
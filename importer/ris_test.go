@@ -0,0 +1,83 @@
+package importer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseRIS(t *testing.T) {
+	table, err := Parse(FormatRIS, readFixture(t, "multiline.ris"), 0)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	wantColumns := []string{"TY", "TI", "AU", "KW", "UR", "DA", "PB", "AB", "DO", "PY"}
+	if !reflect.DeepEqual(table.Columns, wantColumns) {
+		t.Errorf("Columns = %q, want %q", table.Columns, wantColumns)
+	}
+	if want := []int{1, 2}; !reflect.DeepEqual(table.Rows, want) {
+		t.Errorf("Rows = %v, want %v", table.Rows, want)
+	}
+
+	// 续行并入上一个值，重复的标签以 "; " 连接，TY 之前的文字与空值标签忽略
+	want := []Record{
+		{
+			"TY": "RPRT",
+			"TI": "National strategy for quantum information science",
+			"AU": "Smith, John; Doe, Jane",
+			"KW": "quantum; strategy",
+			"UR": "https://example.gov/quantum.pdf; https://mirror.example.org/quantum.pdf",
+			"DA": "2024/03/15/",
+			"PB": "Office of Science and Technology Policy",
+			"AB": "A national strategy for quantum research.",
+		},
+		{
+			"TY": "JOUR",
+			"TI": "Quantum benchmarks",
+			"DO": "10.1000/xyz.1",
+			"PY": "2023///",
+		},
+	}
+	if !reflect.DeepEqual(table.Records, want) {
+		t.Errorf("Records = %q, want %q", table.Records, want)
+	}
+}
+
+func TestParseRISMapping(t *testing.T) {
+	table, err := Parse(FormatRIS, readFixture(t, "multiline.ris"), 0)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	m := DetectMapping(table.Columns)
+
+	first, err := m.Apply(table.Records[0])
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	// 多个 UR 只取第一个
+	if first.URL != "https://example.gov/quantum.pdf" || first.Keywords != "quantum,strategy" {
+		t.Errorf("Apply() = %+v", first)
+	}
+	if first.PublishDate == nil || first.PublishDate.Format("2006-01-02") != "2024-03-15" {
+		t.Errorf("PublishDate = %v, want 2024-03-15", first.PublishDate)
+	}
+
+	// 没有 UR 时由 DO 生成链接
+	second, err := m.Apply(table.Records[1])
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if second.URL != "https://doi.org/10.1000/xyz.1" {
+		t.Errorf("URL = %q, want DOI link", second.URL)
+	}
+}
+
+func TestParseRISLimits(t *testing.T) {
+	if _, err := Parse(FormatRIS, []byte("TI  - no record start\nER  - \n"), 0); !errors.Is(err, ErrNoRecords) {
+		t.Errorf("without TY: error = %v, want ErrNoRecords", err)
+	}
+	if _, err := Parse(FormatRIS, readFixture(t, "multiline.ris"), 1); !errors.Is(err, ErrTooManyRecords) {
+		t.Errorf("limit 1: error = %v, want ErrTooManyRecords", err)
+	}
+}
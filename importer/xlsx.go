@@ -0,0 +1,239 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// xlsxMaxPartSize 单个 XML 部件解压后的大小上限，防止压缩炸弹
+const xlsxMaxPartSize = 256 << 20
+
+// parseXLSX 解析 XLSX 的第一个工作表：首个非空行为表头
+// 单元格按显示前的原始值读取，日期单元格为 Excel 序列号（如 45292），由列映射按日期解析
+func parseXLSX(data []byte, maxRecords int) (*Table, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("importer: invalid xlsx: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := readSharedStrings(files)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("importer: invalid xlsx: missing %s", sheetPath)
+	}
+	rc, err := sheet.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	t := &tabular{table: &Table{}}
+	err = readSheetRows(io.LimitReader(rc, xlsxMaxPartSize), shared, func(values []string, row int) error {
+		return t.addRow(values, row, maxRecords)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t.table, nil
+}
+
+// firstSheetPath 从 workbook.xml 及其关系文件中找到第一个工作表的路径
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	var workbook struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodePart(files, "xl/workbook.xml", &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("importer: invalid xlsx: no worksheet")
+	}
+
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodePart(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].ID {
+			continue
+		}
+		// Target 通常相对于 xl/，也可能是以 / 开头的包内绝对路径
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return "", errors.New("importer: invalid xlsx: worksheet relationship not found")
+}
+
+// readSharedStrings 读取共享字符串表，文件中没有时返回空表
+func readSharedStrings(files map[string]*zip.File) ([]string, error) {
+	if _, ok := files["xl/sharedStrings.xml"]; !ok {
+		return nil, nil
+	}
+	var sst struct {
+		Items []xlsxText `xml:"si"`
+	}
+	if err := decodePart(files, "xl/sharedStrings.xml", &sst); err != nil {
+		return nil, err
+	}
+	shared := make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		shared[i] = item.String()
+	}
+	return shared, nil
+}
+
+// xlsxText 字符串内容：纯文本为单个 <t>，富文本为多个 <r><t>；<rPh> 中的注音不计入
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+// String 拼接全部文本
+func (x xlsxText) String() string {
+	if len(x.Runs) == 0 {
+		return x.T
+	}
+	var b strings.Builder
+	b.WriteString(x.T)
+	for _, r := range x.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+// xlsxCell 工作表单元格
+type xlsxCell struct {
+	Ref    string   `xml:"r,attr"`
+	Type   string   `xml:"t,attr"`
+	Value  string   `xml:"v"`
+	Inline xlsxText `xml:"is"`
+}
+
+// readSheetRows 以流的方式逐行读取工作表，fn 的参数为按列位置排列的值与行号
+func readSheetRows(r io.Reader, shared []string, fn func(values []string, row int) error) error {
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("importer: invalid xlsx worksheet: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+
+		var row struct {
+			Num   int        `xml:"r,attr"`
+			Cells []xlsxCell `xml:"c"`
+		}
+		if err := dec.DecodeElement(&row, &start); err != nil {
+			return fmt.Errorf("importer: invalid xlsx worksheet: %w", err)
+		}
+
+		var values []string
+		for i, cell := range row.Cells {
+			col := i
+			if cell.Ref != "" {
+				if c, ok := columnIndex(cell.Ref); ok {
+					col = c
+				}
+			}
+			for len(values) <= col {
+				values = append(values, "")
+			}
+			values[col] = cellValue(cell, shared)
+		}
+		if err := fn(values, row.Num); err != nil {
+			return err
+		}
+	}
+}
+
+// cellValue 单元格的文本值
+func cellValue(cell xlsxCell, shared []string) string {
+	switch cell.Type {
+	case "s":
+		i, err := strconv.Atoi(strings.TrimSpace(cell.Value))
+		if err != nil || i < 0 || i >= len(shared) {
+			return ""
+		}
+		return shared[i]
+	case "inlineStr":
+		return cell.Inline.String()
+	case "b":
+		if cell.Value == "1" {
+			return "TRUE"
+		}
+		return "FALSE"
+	default:
+		return cell.Value
+	}
+}
+
+// columnIndex 从单元格引用（如 AB12）中解析列序号（从 0 开始）
+func columnIndex(ref string) (int, bool) {
+	col := 0
+	n := 0
+	for _, ch := range ref {
+		if ch >= 'A' && ch <= 'Z' {
+			col = col*26 + int(ch-'A'+1)
+			n++
+			continue
+		}
+		break
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return col - 1, true
+}
+
+// decodePart 解析 zip 包中的 XML 部件
+func decodePart(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("importer: invalid xlsx: missing %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(io.LimitReader(rc, xlsxMaxPartSize)).Decode(v); err != nil {
+		return fmt.Errorf("importer: invalid xlsx %s: %w", name, err)
+	}
+	return nil
+}
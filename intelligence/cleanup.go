@@ -0,0 +1,73 @@
+package intelligence
+
+import (
+	"gorm.io/gorm"
+)
+
+// 删除情报时收集情报ID的回调名称与存放键
+const (
+	cleanupCallbackCollect = "intelligences:collect_deleted_ids"
+	cleanupDeletedIDsKey   = "intelligences:deleted_ids"
+)
+
+// cleanupBatchSize 清理关联行时每条语句带的情报ID数
+const cleanupBatchSize = 500
+
+// registerDeleteCleanup 注册删除情报后的清理回调：cleanup 按已删除的情报ID清理关联表（全文索引、标签、收藏等）
+// 情报ID在删除前由同一条语句收集，回调在删除所在的事务中执行
+func registerDeleteCleanup(db *gorm.DB, name string, cleanup func(db *gorm.DB, ids []uint) error) error {
+	if db.Callback().Delete().Get(cleanupCallbackCollect) == nil {
+		err := db.Callback().Delete().Before("gorm:delete").Register(cleanupCallbackCollect, collectDeletedIDs)
+		if err != nil {
+			return err
+		}
+	}
+	if db.Callback().Delete().Get(name) != nil {
+		return nil
+	}
+	return db.Callback().Delete().After("gorm:delete").Register(name, func(tx *gorm.DB) {
+		if !isIntelligenceStatement(tx) || tx.RowsAffected == 0 {
+			return
+		}
+		value, ok := tx.Statement.Settings.Load(cleanupDeletedIDsKey)
+		if !ok {
+			return
+		}
+		ids := value.([]uint)
+		session := tx.Session(&gorm.Session{NewDB: true})
+		for start := 0; start < len(ids); start += cleanupBatchSize {
+			end := min(start+cleanupBatchSize, len(ids))
+			if err := cleanup(session, ids[start:end]); err != nil {
+				_ = tx.AddError(err)
+				return
+			}
+		}
+	})
+}
+
+// collectDeletedIDs 记录将被删除的情报ID
+// 模型值带主键且没有其他条件时直接取主键，否则按语句的条件查出；须在删除前查询，删除后就查不到了
+func collectDeletedIDs(tx *gorm.DB) {
+	if !isIntelligenceStatement(tx) {
+		return
+	}
+	ids := statementIDs(tx)
+	where, ok := tx.Statement.Clauses["WHERE"]
+	if ok || (len(ids) == 0 && tx.Statement.AllowGlobalUpdate) {
+		query := tx.Session(&gorm.Session{NewDB: true}).Model(&Intelligence{})
+		if ok {
+			query = query.Clauses(where.Expression)
+		}
+		if len(ids) > 0 {
+			query = query.Where("intelligences.id IN ?", ids)
+		}
+		ids = nil
+		if err := query.Pluck("intelligences.id", &ids).Error; err != nil {
+			_ = tx.AddError(err)
+			return
+		}
+	}
+	if len(ids) > 0 {
+		tx.Statement.Settings.Store(cleanupDeletedIDsKey, ids)
+	}
+}
@@ -0,0 +1,624 @@
+package intelligence
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"policy-backend/user"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 收藏夹错误
+var (
+	ErrCollectionNotFound    = errors.New("collection not found")
+	ErrCollectionConflict    = errors.New("collection name already exists")
+	ErrCollectionLimit       = errors.New("too many collections")
+	ErrCollectionTooMany     = errors.New("too many intelligences in one request")
+	ErrCollectionDefault     = errors.New("default collection cannot be deleted")
+	ErrCollectionInvalid     = errors.New("invalid collection name or description")
+	ErrCollectionNoteTooLong = errors.New("collection note too long")
+	ErrCollectionNotMember   = errors.New("not a member of this team")
+)
+
+// 收藏夹回调名称
+const collectionCallbackDelete = "collections:delete_items"
+
+// registerCollectionCallbacks 注册 GORM 回调：删除情报后将其移出所有收藏夹
+func registerCollectionCallbacks(db *gorm.DB) error {
+	return registerDeleteCleanup(db, collectionCallbackDelete, func(db *gorm.DB, ids []uint) error {
+		return db.Where("intelligence_id IN ?", ids).Delete(&CollectionItem{}).Error
+	})
+}
+
+// normalizeCollectionName 规范收藏夹名称：去除首尾空白，不能为空或超过 100 字
+func normalizeCollectionName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > 100 {
+		return "", ErrCollectionInvalid
+	}
+	return name, nil
+}
+
+// checkCollectionNote 检查收藏备注长度
+func checkCollectionNote(note string) error {
+	if utf8.RuneCountInString(note) > collectionNoteMaxLen {
+		return ErrCollectionNoteTooLong
+	}
+	return nil
+}
+
+// collectionNameTaken 用户是否已有同名收藏夹，excludeID 的收藏夹不计
+func collectionNameTaken(tx *gorm.DB, userID uint, name string, excludeID uint) (bool, error) {
+	var count int64
+	err := tx.Model(&Collection{}).
+		Where("user_id = ? AND name = ? AND id <> ?", userID, name, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// defaultCollection 用户的默认收藏夹，不存在时创建
+// 用户已有同名的普通收藏夹时将其设为默认收藏夹
+func (s *Service) defaultCollection(userID uint) (*Collection, error) {
+	var c Collection
+	err := s.db.Where("user_id = ? AND is_default = ?", userID, true).First(&c).Error
+	if err == nil {
+		return &c, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	c = Collection{UserID: userID, Name: DefaultCollectionName, IsDefault: true}
+	if createErr := s.db.Create(&c).Error; createErr == nil {
+		return &c, nil
+	}
+	// 并发创建或名称被占用
+	c = Collection{}
+	if err := s.db.Where("user_id = ? AND name = ?", userID, DefaultCollectionName).First(&c).Error; err != nil {
+		return nil, err
+	}
+	if !c.IsDefault {
+		if err := s.db.Model(&c).Update("is_default", true).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &c, nil
+}
+
+// findOwnCollection 查找属于用户的收藏夹，不存在或不属于该用户时返回 ErrCollectionNotFound
+func findOwnCollection(tx *gorm.DB, userID, id uint) (*Collection, error) {
+	var c Collection
+	err := tx.Where("id = ? AND user_id = ?", id, userID).First(&c).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCollectionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// collectionSharedWithUser 收藏夹是否共享到了用户所在的任一团队
+func (s *Service) collectionSharedWithUser(collectionID, userID uint) (bool, error) {
+	var count int64
+	err := s.db.Model(&Permission{}).
+		Where("resource_type = ? AND resource_id = ? AND subject_type = ?",
+			ResourceTypeCollection, collectionID, SubjectTypeTeam).
+		Where("subject_id IN (?)", s.db.Model(&user.TeamMember{}).Select("team_id").Where("user_id = ?", userID)).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// collectionTeams 收藏夹已共享的团队ID
+func (s *Service) collectionTeams(collectionID uint) ([]uint, error) {
+	teamIDs := []uint{}
+	err := s.db.Model(&Permission{}).
+		Where("resource_type = ? AND resource_id = ? AND subject_type = ?",
+			ResourceTypeCollection, collectionID, SubjectTypeTeam).
+		Order("subject_id").
+		Pluck("subject_id", &teamIDs).Error
+	return teamIDs, err
+}
+
+// fillCollectionCounts 补全收藏夹的情报数
+func (s *Service) fillCollectionCounts(collections []Collection) error {
+	if len(collections) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(collections))
+	for i, c := range collections {
+		ids[i] = c.ID
+	}
+	var counts []struct {
+		CollectionID uint
+		Count        int64
+	}
+	err := s.db.Model(&CollectionItem{}).
+		Select("collection_id, COUNT(*) AS count").
+		Where("collection_id IN ?", ids).
+		Group("collection_id").
+		Scan(&counts).Error
+	if err != nil {
+		return err
+	}
+	byID := make(map[uint]int64, len(counts))
+	for _, c := range counts {
+		byID[c.CollectionID] = c.Count
+	}
+	for i := range collections {
+		collections[i].ItemCount = byID[collections[i].ID]
+	}
+	return nil
+}
+
+// ListCollections 列出收藏夹：teamID 为 0 时返回自己的收藏夹（默认收藏夹在最前），否则返回共享到该团队的收藏夹（须为团队成员）
+func (s *Service) ListCollections(userID, teamID uint) ([]Collection, error) {
+	collections := []Collection{}
+	if teamID != 0 {
+		var count int64
+		if err := s.db.Model(&user.TeamMember{}).Where("team_id = ? AND user_id = ?", teamID, userID).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, ErrCollectionNotMember
+		}
+		err := s.db.Where("id IN (?)", s.db.Model(&Permission{}).
+			Select("resource_id").
+			Where("resource_type = ? AND subject_type = ? AND subject_id = ?",
+				ResourceTypeCollection, SubjectTypeTeam, teamID)).
+			Order("updated_at DESC, id DESC").
+			Find(&collections).Error
+		if err != nil {
+			return nil, err
+		}
+		return collections, s.fillCollectionCounts(collections)
+	}
+
+	if _, err := s.defaultCollection(userID); err != nil {
+		return nil, err
+	}
+	if err := s.db.Where("user_id = ?", userID).Order("is_default DESC, created_at, id").Find(&collections).Error; err != nil {
+		return nil, err
+	}
+	if err := s.fillCollectionCounts(collections); err != nil {
+		return nil, err
+	}
+	for i := range collections {
+		teamIDs, err := s.collectionTeams(collections[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		collections[i].TeamIDs = teamIDs
+	}
+	return collections, nil
+}
+
+// CreateCollection 创建收藏夹
+func (s *Service) CreateCollection(userID uint, req *CollectionRequest) (*Collection, error) {
+	name, err := normalizeCollectionName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if utf8.RuneCountInString(req.Description) > collectionDescMaxLen {
+		return nil, ErrCollectionInvalid
+	}
+	// 保证默认收藏夹先于同名的普通收藏夹创建
+	if _, err := s.defaultCollection(userID); err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := s.db.Model(&Collection{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count >= collectionMaxPerUser {
+		return nil, ErrCollectionLimit
+	}
+	taken, err := collectionNameTaken(s.db, userID, name, 0)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrCollectionConflict
+	}
+
+	c := &Collection{UserID: userID, Name: name, Description: strings.TrimSpace(req.Description), TeamIDs: []uint{}}
+	if err := s.db.Create(c).Error; err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// UpdateCollection 修改收藏夹名称与描述
+func (s *Service) UpdateCollection(userID, id uint, req *CollectionUpdateRequest) (*Collection, error) {
+	c, err := findOwnCollection(s.db, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		name, err := normalizeCollectionName(*req.Name)
+		if err != nil {
+			return nil, err
+		}
+		taken, err := collectionNameTaken(s.db, userID, name, c.ID)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, ErrCollectionConflict
+		}
+		updates["name"] = name
+	}
+	if req.Description != nil {
+		if utf8.RuneCountInString(*req.Description) > collectionDescMaxLen {
+			return nil, ErrCollectionInvalid
+		}
+		updates["description"] = strings.TrimSpace(*req.Description)
+	}
+	if len(updates) > 0 {
+		if err := s.db.Model(c).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	collections := []Collection{*c}
+	if err := s.fillCollectionCounts(collections); err != nil {
+		return nil, err
+	}
+	if collections[0].TeamIDs, err = s.collectionTeams(c.ID); err != nil {
+		return nil, err
+	}
+	return &collections[0], nil
+}
+
+// DeleteCollection 删除收藏夹及其中的收藏记录与团队共享，默认收藏夹不能删除
+func (s *Service) DeleteCollection(userID, id uint) error {
+	c, err := findOwnCollection(s.db, userID, id)
+	if err != nil {
+		return err
+	}
+	if c.IsDefault {
+		return ErrCollectionDefault
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", c.ID).Delete(&CollectionItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("resource_type = ? AND resource_id = ?", ResourceTypeCollection, c.ID).
+			Delete(&Permission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(c).Error
+	})
+}
+
+// GetCollection 收藏夹详情与一页情报，创建者与收藏夹所共享团队的成员可以查看（后者只读）
+func (s *Service) GetCollection(userID, id uint, page, pageSize int) (*CollectionDetail, error) {
+	var c Collection
+	if err := s.db.First(&c, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, err
+	}
+	readOnly := c.UserID != userID
+	if readOnly {
+		shared, err := s.collectionSharedWithUser(c.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !shared {
+			return nil, ErrCollectionNotFound
+		}
+	} else {
+		teamIDs, err := s.collectionTeams(c.ID)
+		if err != nil {
+			return nil, err
+		}
+		c.TeamIDs = teamIDs
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultCollectionPage
+	}
+	if pageSize > maxCollectionPage {
+		pageSize = maxCollectionPage
+	}
+	detail := &CollectionDetail{Collection: c, ReadOnly: readOnly, Items: []CollectionEntry{}, Page: page, PageSize: pageSize}
+	if err := s.db.Model(&CollectionItem{}).Where("collection_id = ?", c.ID).Count(&detail.Total).Error; err != nil {
+		return nil, err
+	}
+	detail.ItemCount = detail.Total
+
+	var items []CollectionItem
+	err := s.db.Where("collection_id = ?", c.ID).
+		Order("position, created_at").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(items))
+	for i, it := range items {
+		ids[i] = it.IntelligenceID
+	}
+	var intelligences []Intelligence
+	if len(ids) > 0 {
+		if err := s.db.Where("id IN ?", ids).Find(&intelligences).Error; err != nil {
+			return nil, err
+		}
+	}
+	byID := make(map[uint]*Intelligence, len(intelligences))
+	for i := range intelligences {
+		byID[intelligences[i].ID] = &intelligences[i]
+	}
+	for _, it := range items {
+		detail.Items = append(detail.Items, CollectionEntry{CollectionItem: it, Intelligence: byID[it.IntelligenceID]})
+	}
+	return detail, nil
+}
+
+// AddCollectionItems 将情报按给出的顺序追加到收藏夹末尾，已在收藏夹中的跳过，返回新加入的条数
+// 有情报不存在时返回 gorm.ErrRecordNotFound
+func (s *Service) AddCollectionItems(userID, id uint, req *CollectionItemsRequest) (int, error) {
+	if err := checkCollectionNote(req.Note); err != nil {
+		return 0, err
+	}
+	if len(req.IntelligenceIDs) > collectionMaxItemsPerAdd {
+		return 0, ErrCollectionTooMany
+	}
+	c, err := findOwnCollection(s.db, userID, id)
+	if err != nil {
+		return 0, err
+	}
+	return s.addCollectionItems(c, req.IntelligenceIDs, req.Note)
+}
+
+// addCollectionItems 追加情报到收藏夹末尾
+func (s *Service) addCollectionItems(c *Collection, intelligenceIDs []uint, note string) (int, error) {
+	seen := make(map[uint]bool, len(intelligenceIDs))
+	ids := make([]uint, 0, len(intelligenceIDs))
+	for _, id := range intelligenceIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	var existing int64
+	if err := s.db.Model(&Intelligence{}).Where("id IN ?", ids).Count(&existing).Error; err != nil {
+		return 0, err
+	}
+	if existing != int64(len(ids)) {
+		return 0, gorm.ErrRecordNotFound
+	}
+
+	added := 0
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var collected []uint
+		if err := tx.Model(&CollectionItem{}).
+			Where("collection_id = ? AND intelligence_id IN ?", c.ID, ids).
+			Pluck("intelligence_id", &collected).Error; err != nil {
+			return err
+		}
+		inCollection := make(map[uint]bool, len(collected))
+		for _, id := range collected {
+			inCollection[id] = true
+		}
+
+		var maxPosition int
+		if err := tx.Model(&CollectionItem{}).
+			Where("collection_id = ?", c.ID).
+			Select("COALESCE(MAX(position), 0)").
+			Scan(&maxPosition).Error; err != nil {
+			return err
+		}
+
+		var items []CollectionItem
+		for _, id := range ids {
+			if inCollection[id] {
+				continue
+			}
+			maxPosition++
+			items = append(items, CollectionItem{CollectionID: c.ID, IntelligenceID: id, Position: maxPosition, Note: note})
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&items)
+		added = int(result.RowsAffected)
+		if result.Error != nil {
+			return result.Error
+		}
+		return tx.Model(c).Update("updated_at", time.Now()).Error
+	})
+	return added, err
+}
+
+// UpdateCollectionItem 修改收藏备注
+func (s *Service) UpdateCollectionItem(userID, id, intelligenceID uint, note string) (*CollectionItem, error) {
+	if err := checkCollectionNote(note); err != nil {
+		return nil, err
+	}
+	c, err := findOwnCollection(s.db, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var item CollectionItem
+	if err := s.db.Where("collection_id = ? AND intelligence_id = ?", c.ID, intelligenceID).First(&item).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(&item).Update("note", note).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// RemoveCollectionItem 将情报移出收藏夹
+func (s *Service) RemoveCollectionItem(userID, id, intelligenceID uint) error {
+	c, err := findOwnCollection(s.db, userID, id)
+	if err != nil {
+		return err
+	}
+	return s.db.Where("collection_id = ? AND intelligence_id = ?", c.ID, intelligenceID).Delete(&CollectionItem{}).Error
+}
+
+// ReorderCollection 调整收藏夹内顺序：列出的情报按给出的顺序排在最前，其余保持原有顺序；不在收藏夹中的ID忽略
+func (s *Service) ReorderCollection(userID, id uint, intelligenceIDs []uint) error {
+	c, err := findOwnCollection(s.db, userID, id)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var items []CollectionItem
+		if err := tx.Where("collection_id = ?", c.ID).Order("position, created_at").Find(&items).Error; err != nil {
+			return err
+		}
+		byID := make(map[uint]*CollectionItem, len(items))
+		for i := range items {
+			byID[items[i].IntelligenceID] = &items[i]
+		}
+
+		ordered := make([]*CollectionItem, 0, len(items))
+		placed := make(map[uint]bool, len(items))
+		for _, iid := range intelligenceIDs {
+			if item, ok := byID[iid]; ok && !placed[iid] {
+				placed[iid] = true
+				ordered = append(ordered, item)
+			}
+		}
+		for i := range items {
+			if !placed[items[i].IntelligenceID] {
+				ordered = append(ordered, &items[i])
+			}
+		}
+
+		for i, item := range ordered {
+			if item.Position == i+1 {
+				continue
+			}
+			if err := tx.Model(&CollectionItem{}).
+				Where("collection_id = ? AND intelligence_id = ?", c.ID, item.IntelligenceID).
+				UpdateColumn("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ShareCollection 将收藏夹只读共享到团队，创建者须为团队成员；返回已共享的团队
+func (s *Service) ShareCollection(userID, id, teamID uint) ([]uint, error) {
+	c, err := findOwnCollection(s.db, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	var count int64
+	if err := s.db.Model(&user.TeamMember{}).Where("team_id = ? AND user_id = ?", teamID, userID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrCollectionNotMember
+	}
+
+	permission := Permission{
+		ResourceType: ResourceTypeCollection,
+		ResourceID:   c.ID,
+		SubjectType:  SubjectTypeTeam,
+		SubjectID:    teamID,
+		Action:       PermissionView,
+		GrantedBy:    userID,
+	}
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&permission).Error; err != nil {
+		return nil, err
+	}
+	return s.collectionTeams(c.ID)
+}
+
+// UnshareCollection 取消收藏夹到团队的共享，返回仍共享的团队
+func (s *Service) UnshareCollection(userID, id, teamID uint) ([]uint, error) {
+	c, err := findOwnCollection(s.db, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Where("resource_type = ? AND resource_id = ? AND subject_type = ? AND subject_id = ?",
+		ResourceTypeCollection, c.ID, SubjectTypeTeam, teamID).
+		Delete(&Permission{}).Error; err != nil {
+		return nil, err
+	}
+	return s.collectionTeams(c.ID)
+}
+
+// FavoriteIntelligence 收藏情报：放入指定的收藏夹，未指定时放入默认收藏夹；已收藏时只更新非空的备注
+// 返回所在的收藏夹；情报不存在时返回 gorm.ErrRecordNotFound
+func (s *Service) FavoriteIntelligence(userID, intelligenceID uint, req *FavoriteRequest) (*Collection, error) {
+	if err := checkCollectionNote(req.Note); err != nil {
+		return nil, err
+	}
+	var c *Collection
+	var err error
+	if req.CollectionID == 0 {
+		c, err = s.defaultCollection(userID)
+	} else {
+		c, err = findOwnCollection(s.db, userID, req.CollectionID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	added, err := s.addCollectionItems(c, []uint{intelligenceID}, req.Note)
+	if err != nil {
+		return nil, err
+	}
+	if added == 0 && req.Note != "" {
+		if err := s.db.Model(&CollectionItem{}).
+			Where("collection_id = ? AND intelligence_id = ?", c.ID, intelligenceID).
+			Update("note", req.Note).Error; err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// UnfavoriteIntelligence 取消收藏：将情报移出用户的所有收藏夹
+func (s *Service) UnfavoriteIntelligence(userID, intelligenceID uint) error {
+	return s.db.Where("intelligence_id = ? AND collection_id IN (?)", intelligenceID,
+		s.db.Model(&Collection{}).Select("id").Where("user_id = ?", userID)).
+		Delete(&CollectionItem{}).Error
+}
+
+// favoritedIDs 情报中已被用户收藏（在其任一收藏夹中）的ID
+func (s *Service) favoritedIDs(userID uint, intelligenceIDs []uint) (map[uint]bool, error) {
+	favorited := make(map[uint]bool)
+	if userID == 0 || len(intelligenceIDs) == 0 {
+		return favorited, nil
+	}
+	var ids []uint
+	err := s.db.Model(&CollectionItem{}).
+		Where("intelligence_id IN ? AND collection_id IN (?)", intelligenceIDs,
+			s.db.Model(&Collection{}).Select("id").Where("user_id = ?", userID)).
+		Distinct().
+		Pluck("intelligence_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		favorited[id] = true
+	}
+	return favorited, nil
+}
@@ -0,0 +1,344 @@
+package intelligence
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"policy-backend/user"
+	"policy-backend/utils"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// collectionError 将收藏夹相关错误转换为响应
+func collectionError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrCollectionNotFound):
+		return utils.Error(c, http.StatusNotFound, "Collection not found")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return utils.Error(c, http.StatusNotFound, "Intelligence not found")
+	case errors.Is(err, ErrCollectionConflict):
+		return utils.Error(c, http.StatusConflict, "Collection name already exists")
+	case errors.Is(err, ErrCollectionLimit):
+		return utils.Error(c, http.StatusBadRequest, fmt.Sprintf("Too many collections, at most %d", collectionMaxPerUser))
+	case errors.Is(err, ErrCollectionTooMany):
+		return utils.Error(c, http.StatusBadRequest, fmt.Sprintf("Too many intelligences, at most %d per request", collectionMaxItemsPerAdd))
+	case errors.Is(err, ErrCollectionDefault):
+		return utils.Error(c, http.StatusBadRequest, "Default collection cannot be deleted")
+	case errors.Is(err, ErrCollectionInvalid):
+		return utils.Error(c, http.StatusBadRequest, "Invalid collection name or description")
+	case errors.Is(err, ErrCollectionNoteTooLong):
+		return utils.Error(c, http.StatusBadRequest, fmt.Sprintf("Note too long, at most %d characters", collectionNoteMaxLen))
+	case errors.Is(err, ErrCollectionNotMember):
+		return utils.Error(c, http.StatusForbidden, "You are not a member of this team")
+	}
+	zap.L().Error("Collection operation failed", zap.Error(err))
+	return utils.Error(c, http.StatusInternalServerError, "Collection operation failed")
+}
+
+// ListCollections 我的收藏夹（默认收藏夹在最前），指定 team_id 时返回共享到该团队的收藏夹
+// GET /api/intelligence/collections
+func (h *Handler) ListCollections(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	teamID, err := parseTeamScope(c)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid team_id")
+	}
+	scope := uint(0)
+	if teamID != nil {
+		scope = *teamID
+	}
+
+	collections, err := h.svc.ListCollections(currentUser.ID, scope)
+	if err != nil {
+		return collectionError(c, err)
+	}
+	return utils.Success(c, collections)
+}
+
+// CreateCollection 新建收藏夹
+// POST /api/intelligence/collections
+func (h *Handler) CreateCollection(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	var req CollectionRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	collection, err := h.svc.CreateCollection(currentUser.ID, &req)
+	if err != nil {
+		return collectionError(c, err)
+	}
+	return utils.Success(c, collection)
+}
+
+// GetCollection 收藏夹详情与其中的情报（按排序分页），共享到团队的收藏夹对成员只读
+// GET /api/intelligence/collections/:id
+func (h *Handler) GetCollection(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
+	}
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	pageSize, _ := strconv.Atoi(c.QueryParam("page_size"))
+
+	detail, err := h.svc.GetCollection(currentUser.ID, uint(id), page, pageSize)
+	if err != nil {
+		return collectionError(c, err)
+	}
+	return utils.Success(c, detail)
+}
+
+// UpdateCollection 修改收藏夹名称与描述
+// PUT /api/intelligence/collections/:id
+func (h *Handler) UpdateCollection(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
+	}
+	var req CollectionUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	collection, err := h.svc.UpdateCollection(currentUser.ID, uint(id), &req)
+	if err != nil {
+		return collectionError(c, err)
+	}
+	return utils.Success(c, collection)
+}
+
+// DeleteCollection 删除收藏夹，默认收藏夹不能删除
+// DELETE /api/intelligence/collections/:id
+func (h *Handler) DeleteCollection(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
+	}
+
+	if err := h.svc.DeleteCollection(currentUser.ID, uint(id)); err != nil {
+		return collectionError(c, err)
+	}
+	return utils.Success(c, nil)
+}
+
+// AddCollectionItems 将情报加入收藏夹末尾
+// POST /api/intelligence/collections/:id/items
+func (h *Handler) AddCollectionItems(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
+	}
+	var req CollectionItemsRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid request body")
+	}
+	if len(req.IntelligenceIDs) == 0 {
+		return utils.Error(c, http.StatusBadRequest, "intelligence_ids is required")
+	}
+
+	added, err := h.svc.AddCollectionItems(currentUser.ID, uint(id), &req)
+	if err != nil {
+		return collectionError(c, err)
+	}
+	return utils.Success(c, map[string]interface{}{
+		"collection_id": id,
+		"added":         added,
+	})
+}
+
+// ReorderCollection 调整收藏夹内情报的顺序
+// PUT /api/intelligence/collections/:id/items/order
+func (h *Handler) ReorderCollection(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
+	}
+	var req CollectionOrderRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := h.svc.ReorderCollection(currentUser.ID, uint(id), req.IntelligenceIDs); err != nil {
+		return collectionError(c, err)
+	}
+	return utils.Success(c, nil)
+}
+
+// UpdateCollectionItem 修改收藏备注
+// PUT /api/intelligence/collections/:id/items/:intelligence_id
+func (h *Handler) UpdateCollectionItem(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
+	}
+	intelligenceID, err := strconv.ParseUint(c.Param("intelligence_id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid intelligence ID")
+	}
+	var req CollectionItemRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	item, err := h.svc.UpdateCollectionItem(currentUser.ID, uint(id), uint(intelligenceID), req.Note)
+	if err != nil {
+		return collectionError(c, err)
+	}
+	return utils.Success(c, item)
+}
+
+// RemoveCollectionItem 将情报移出收藏夹
+// DELETE /api/intelligence/collections/:id/items/:intelligence_id
+func (h *Handler) RemoveCollectionItem(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
+	}
+	intelligenceID, err := strconv.ParseUint(c.Param("intelligence_id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid intelligence ID")
+	}
+
+	if err := h.svc.RemoveCollectionItem(currentUser.ID, uint(id), uint(intelligenceID)); err != nil {
+		return collectionError(c, err)
+	}
+	return utils.Success(c, nil)
+}
+
+// ShareCollection 将收藏夹只读共享到团队
+// POST /api/intelligence/collections/:id/share
+func (h *Handler) ShareCollection(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
+	}
+	var req CollectionShareRequest
+	if err := c.Bind(&req); err != nil || req.TeamID == 0 {
+		return utils.Error(c, http.StatusBadRequest, "team_id is required")
+	}
+
+	teamIDs, err := h.svc.ShareCollection(currentUser.ID, uint(id), req.TeamID)
+	if err != nil {
+		return collectionError(c, err)
+	}
+	return utils.Success(c, map[string]interface{}{
+		"collection_id": id,
+		"team_ids":      teamIDs,
+	})
+}
+
+// UnshareCollection 取消收藏夹到团队的共享
+// DELETE /api/intelligence/collections/:id/share/:team_id
+func (h *Handler) UnshareCollection(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
+	}
+	teamID, err := strconv.ParseUint(c.Param("team_id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid team ID")
+	}
+
+	teamIDs, err := h.svc.UnshareCollection(currentUser.ID, uint(id), uint(teamID))
+	if err != nil {
+		return collectionError(c, err)
+	}
+	return utils.Success(c, map[string]interface{}{
+		"collection_id": id,
+		"team_ids":      teamIDs,
+	})
+}
+
+// FavoriteIntelligence 收藏情报，默认放入默认收藏夹
+// POST /api/intelligence/:id/favorite
+func (h *Handler) FavoriteIntelligence(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
+	}
+	var req FavoriteRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	collection, err := h.svc.FavoriteIntelligence(currentUser.ID, uint(id), &req)
+	if err != nil {
+		return collectionError(c, err)
+	}
+	return utils.Success(c, map[string]interface{}{
+		"intelligence_id": id,
+		"collection_id":   collection.ID,
+		"favorited":       true,
+	})
+}
+
+// UnfavoriteIntelligence 取消收藏，将情报移出我的所有收藏夹
+// DELETE /api/intelligence/:id/favorite
+func (h *Handler) UnfavoriteIntelligence(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
+	}
+
+	if err := h.svc.UnfavoriteIntelligence(currentUser.ID, uint(id)); err != nil {
+		return collectionError(c, err)
+	}
+	return utils.Success(c, map[string]interface{}{
+		"intelligence_id": id,
+		"favorited":       false,
+	})
+}
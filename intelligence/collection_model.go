@@ -0,0 +1,104 @@
+package intelligence
+
+import "time"
+
+// Collection 用户的收藏夹
+// 每个用户有一个默认收藏夹（IsDefault），首次访问收藏时自动创建；收藏夹可只读共享给团队
+type Collection struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"not null;index;uniqueIndex:idx_collection_name"`
+	Name        string    `json:"name" gorm:"type:varchar(100);not null;uniqueIndex:idx_collection_name"`
+	Description string    `json:"description" gorm:"type:varchar(500)"`
+	IsDefault   bool      `json:"is_default" gorm:"not null;default:false"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	ItemCount int64  `json:"item_count" gorm:"-"`
+	TeamIDs   []uint `json:"team_ids,omitempty" gorm:"-"` // 已共享的团队，只对创建者返回
+}
+
+// TableName 指定表名
+func (Collection) TableName() string {
+	return "collections"
+}
+
+// CollectionItem 收藏夹中的情报，按 Position 升序排列
+type CollectionItem struct {
+	CollectionID   uint      `json:"collection_id" gorm:"primaryKey;autoIncrement:false"`
+	IntelligenceID uint      `json:"intelligence_id" gorm:"primaryKey;autoIncrement:false;index"`
+	Position       int       `json:"position" gorm:"not null;default:0"`
+	Note           string    `json:"note" gorm:"type:text"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (CollectionItem) TableName() string {
+	return "collection_items"
+}
+
+// 收藏夹限制
+const (
+	DefaultCollectionName    = "收藏夹" // 默认收藏夹的名称
+	collectionMaxPerUser     = 100   // 每个用户的收藏夹数上限（含默认收藏夹）
+	collectionMaxItemsPerAdd = 500   // 单次加入收藏夹的情报数上限
+	collectionNoteMaxLen     = 2000  // 收藏备注的最大字数
+	collectionDescMaxLen     = 500   // 收藏夹描述的最大字数
+	defaultCollectionPage    = 20    // 收藏夹内情报的默认每页数量
+	maxCollectionPage        = 100   // 收藏夹内情报的每页数量上限
+)
+
+// CollectionEntry 收藏夹中的一条情报
+type CollectionEntry struct {
+	CollectionItem
+	Intelligence *Intelligence `json:"intelligence"` // 情报已删除时为空
+}
+
+// CollectionDetail 收藏夹详情与一页情报
+type CollectionDetail struct {
+	Collection
+	ReadOnly bool              `json:"read_only"` // 通过团队共享查看时为只读
+	Items    []CollectionEntry `json:"items"`
+	Total    int64             `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
+
+// CollectionRequest 创建收藏夹请求
+type CollectionRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// CollectionUpdateRequest 修改收藏夹请求，字段为空表示不修改
+type CollectionUpdateRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+// CollectionItemsRequest 加入收藏夹请求，情报按给出的顺序追加到末尾，已在收藏夹中的跳过
+type CollectionItemsRequest struct {
+	IntelligenceIDs []uint `json:"intelligence_ids"`
+	Note            string `json:"note"`
+}
+
+// CollectionOrderRequest 调整收藏夹内顺序：列出的情报按给出的顺序排在最前，其余保持原有顺序
+type CollectionOrderRequest struct {
+	IntelligenceIDs []uint `json:"intelligence_ids"`
+}
+
+// CollectionItemRequest 修改收藏备注请求
+type CollectionItemRequest struct {
+	Note string `json:"note"`
+}
+
+// CollectionShareRequest 共享收藏夹到团队请求
+type CollectionShareRequest struct {
+	TeamID uint `json:"team_id"`
+}
+
+// FavoriteRequest 收藏情报请求，CollectionID 为 0 时放入默认收藏夹
+type FavoriteRequest struct {
+	CollectionID uint   `json:"collection_id"`
+	Note         string `json:"note"`
+}
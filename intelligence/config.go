@@ -0,0 +1,28 @@
+package intelligence
+
+// Config 情报模块配置
+type Config struct {
+	PDFMaxSizeMB           int `koanf:"intelligence_pdf_max_size_mb"`           // 单个 PDF 的大小上限（MB），超过时放弃抓取
+	PDFFetchTimeoutSeconds int `koanf:"intelligence_pdf_fetch_timeout_seconds"` // 单个 PDF 的下载超时（秒）
+	PDFFetchConcurrency    int `koanf:"intelligence_pdf_fetch_concurrency"`     // 同时下载的 PDF 数量
+
+	ExportSyncLimit   int `koanf:"intelligence_export_sync_limit"`  // 不超过该条数时直接流式下载，超过时转为后台导出任务
+	ExportMaxRows     int `koanf:"intelligence_export_max_rows"`    // 单次导出的条数上限
+	ExportTTLHours    int `koanf:"intelligence_export_ttl_hours"`   // 后台导出文件的保留时长（小时），过期后由定时任务清理
+	ExportConcurrency int `koanf:"intelligence_export_concurrency"` // 同时运行的后台导出任务数
+
+	TagAdmins string `koanf:"intelligence_tag_admins"` // 可维护全局标签体系的用户名，逗号分隔；团队标签由团队管理员维护
+}
+
+// DefaultConfig 返回情报模块的默认配置
+func DefaultConfig() Config {
+	return Config{
+		PDFMaxSizeMB:           50,
+		PDFFetchTimeoutSeconds: 60,
+		PDFFetchConcurrency:    4,
+		ExportSyncLimit:        1000,
+		ExportMaxRows:          50000,
+		ExportTTLHours:         24,
+		ExportConcurrency:      2,
+	}
+}
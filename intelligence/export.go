@@ -0,0 +1,277 @@
+package intelligence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"policy-backend/export"
+	"policy-backend/org"
+)
+
+const (
+	exportBatchSize       = 500 // 每批读取的情报数
+	exportSummaryFallback = 300 // 没有摘要时取正文开头的字符数
+)
+
+// exportColumns 情报导出的列
+var exportColumns = []export.Column{
+	{Key: "title", Title: "标题", Width: 50},
+	{Key: "agency", Title: "机构", Width: 24},
+	{Key: "country", Title: "国家", Width: 10},
+	{Key: "publish_date", Title: "发布日期", Width: 12},
+	{Key: "imported_at", Title: "入库日期", Width: 18},
+	{Key: "rating", Title: "评分", Width: 6},
+	{Key: "summary", Title: "摘要", Width: 80},
+	{Key: "url", Title: "链接", Width: 40},
+}
+
+// ExportQuery 导出参数：指定 IDs 时按给出的顺序导出这些情报，否则按与情报列表相同的检索条件导出
+// 两种方式都限定在 Scope 范围内（all 我可见的、mine 个人库、team 团队情报池，TeamID 可指定某个团队）
+type ExportQuery struct {
+	Format  string `json:"format"`
+	Scope   string `json:"scope,omitempty"`
+	TeamID  uint   `json:"team_id,omitempty"`
+	IDs     []uint `json:"ids,omitempty"`
+	Keyword string `json:"keyword,omitempty"`
+	HasPDF  bool   `json:"has_pdf,omitempty"`
+	Tags    []uint `json:"tags,omitempty"`
+}
+
+// ErrExportOutOfScope 指定导出的情报不在导出范围内
+var ErrExportOutOfScope = errors.New("intelligence out of export scope")
+
+// scopedExportIDs 返回 IDs 中在导出范围内的情报ID
+func (s *Service) scopedExportIDs(userID uint, q ExportQuery) (map[uint]bool, error) {
+	var existing []uint
+	db := ApplyLibraryScope(s.db.Model(&Intelligence{}), userID, q.Scope, q.TeamID)
+	if err := db.Where("intelligences.id IN ?", q.IDs).Pluck("intelligences.id", &existing).Error; err != nil {
+		return nil, err
+	}
+	found := make(map[uint]bool, len(existing))
+	for _, id := range existing {
+		found[id] = true
+	}
+	return found, nil
+}
+
+// exportIDs 返回待导出情报的ID，按 IDs 的顺序或入库时间倒序
+func (s *Service) exportIDs(userID uint, q ExportQuery) ([]uint, error) {
+	if len(q.IDs) > 0 {
+		found, err := s.scopedExportIDs(userID, q)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]uint, 0, len(found))
+		for _, id := range q.IDs {
+			if found[id] {
+				ids = append(ids, id)
+				delete(found, id) // 重复的ID只导出一次
+			}
+		}
+		return ids, nil
+	}
+
+	db, _, err := s.filterIntelligences(q.Keyword, q.HasPDF, q.Tags)
+	if err != nil {
+		return nil, err
+	}
+	db = ApplyLibraryScope(db, userID, q.Scope, q.TeamID)
+	var ids []uint
+	err = db.Order("intelligences.created_at DESC, intelligences.id DESC").Pluck("intelligences.id", &ids).Error
+	return ids, err
+}
+
+// CountExport 统计将要导出的情报数，检索表达式有误时返回 *query.Error
+// 指定的 IDs 中有存在但不在导出范围内的情报时返回 ErrExportOutOfScope，已删除的情报跳过
+func (s *Service) CountExport(userID uint, q ExportQuery) (int64, error) {
+	if len(q.IDs) > 0 {
+		found, err := s.scopedExportIDs(userID, q)
+		if err != nil {
+			return 0, err
+		}
+		var existing int64
+		if err := s.db.Model(&Intelligence{}).Where("id IN ?", q.IDs).Count(&existing).Error; err != nil {
+			return 0, err
+		}
+		if existing > int64(len(found)) {
+			return 0, ErrExportOutOfScope
+		}
+		return existing, nil
+	}
+
+	db, _, err := s.filterIntelligences(q.Keyword, q.HasPDF, q.Tags)
+	if err != nil {
+		return 0, err
+	}
+	db = ApplyLibraryScope(db, userID, q.Scope, q.TeamID)
+	var total int64
+	err = db.Count(&total).Error
+	return total, err
+}
+
+// WriteExport 按导出参数逐批读取情报并写出，返回写出的行数
+// 导出范围按 userID 可见的情报计算，写入器由调用方创建，本方法不关闭写入器
+func (s *Service) WriteExport(ctx context.Context, w export.Writer, userID uint, q ExportQuery) (int, error) {
+	ids, err := s.exportIDs(userID, q)
+	if err != nil {
+		return 0, err
+	}
+
+	agencies := make(map[uint]*org.Agency)
+	countries := make(map[uint]string)
+	rows := 0
+	for start := 0; start < len(ids); start += exportBatchSize {
+		if err := ctx.Err(); err != nil {
+			return rows, err
+		}
+		end := start + exportBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch := ids[start:end]
+
+		var records []Intelligence
+		if err := s.db.Where("id IN ?", batch).Find(&records).Error; err != nil {
+			return rows, err
+		}
+		byID := make(map[uint]*Intelligence, len(records))
+		for i := range records {
+			byID[records[i].ID] = &records[i]
+		}
+		if err := s.loadExportOrgs(records, agencies, countries); err != nil {
+			return rows, err
+		}
+		ratings, err := s.averageRatings(batch)
+		if err != nil {
+			return rows, err
+		}
+
+		for _, id := range batch {
+			record, ok := byID[id]
+			if !ok {
+				continue // 导出过程中被删除
+			}
+			if err := w.WriteRow(exportRow(record, agencies, countries, ratings)); err != nil {
+				return rows, err
+			}
+			rows++
+		}
+	}
+	return rows, nil
+}
+
+// loadExportOrgs 补充读取本批情报涉及的机构与国家名称，结果累积在缓存中
+func (s *Service) loadExportOrgs(records []Intelligence, agencies map[uint]*org.Agency, countries map[uint]string) error {
+	var agencyIDs []uint
+	for _, r := range records {
+		if _, ok := agencies[r.AgencyID]; r.AgencyID != 0 && !ok {
+			agencies[r.AgencyID] = nil
+			agencyIDs = append(agencyIDs, r.AgencyID)
+		}
+	}
+	if len(agencyIDs) > 0 {
+		var list []org.Agency
+		if err := s.db.Where("id IN ?", agencyIDs).Find(&list).Error; err != nil {
+			return err
+		}
+		for i := range list {
+			agencies[list[i].ID] = &list[i]
+		}
+	}
+
+	var countryIDs []uint
+	addCountry := func(id uint) {
+		if _, ok := countries[id]; id != 0 && !ok {
+			countries[id] = ""
+			countryIDs = append(countryIDs, id)
+		}
+	}
+	for _, r := range records {
+		addCountry(r.CountryID)
+		if a := agencies[r.AgencyID]; a != nil {
+			addCountry(a.CountryID)
+		}
+	}
+	if len(countryIDs) > 0 {
+		var list []org.Country
+		if err := s.db.Where("id IN ?", countryIDs).Find(&list).Error; err != nil {
+			return err
+		}
+		for _, c := range list {
+			countries[c.ID] = c.Name
+		}
+	}
+	return nil
+}
+
+// averageRatings 批量计算情报的平均评分
+func (s *Service) averageRatings(ids []uint) (map[uint]float64, error) {
+	var rows []struct {
+		IntelligenceID uint
+		AvgScore       float64
+	}
+	err := s.db.Model(&Rating{}).
+		Select("intelligence_id, AVG(score) AS avg_score").
+		Where("intelligence_id IN ?", ids).
+		Group("intelligence_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	ratings := make(map[uint]float64, len(rows))
+	for _, r := range rows {
+		ratings[r.IntelligenceID] = r.AvgScore
+	}
+	return ratings, nil
+}
+
+// exportRow 生成一条情报的导出行，与 exportColumns 一一对应
+// 国家优先取情报自身的国家，未识别时取所属机构的国家；没有评分时评分列为空
+func exportRow(r *Intelligence, agencies map[uint]*org.Agency, countries map[uint]string, ratings map[uint]float64) []string {
+	agency := ""
+	countryID := r.CountryID
+	if a := agencies[r.AgencyID]; a != nil {
+		agency = a.Name
+		if countryID == 0 {
+			countryID = a.CountryID
+		}
+	}
+	if agency == "" {
+		agency = r.Source
+	}
+
+	publishDate := ""
+	if !r.PublishDate.IsZero() {
+		publishDate = r.PublishDate.Format("2006-01-02")
+	}
+	rating := ""
+	if avg, ok := ratings[r.ID]; ok {
+		rating = fmt.Sprintf("%.1f", avg)
+	}
+	summary := strings.TrimSpace(r.Summary)
+	if summary == "" {
+		summary = strings.TrimSpace(r.Content)
+		if runes := []rune(summary); len(runes) > exportSummaryFallback {
+			summary = string(runes[:exportSummaryFallback]) + "…"
+		}
+	}
+
+	return []string{
+		r.Title,
+		agency,
+		countries[countryID],
+		publishDate,
+		r.CreatedAt.Format("2006-01-02 15:04"),
+		rating,
+		summary,
+		r.URL,
+	}
+}
+
+// newExportWriter 创建情报导出的写入器
+func newExportWriter(format string, w io.Writer) (export.Writer, error) {
+	return export.NewWriter(format, w, exportColumns)
+}
@@ -0,0 +1,258 @@
+package intelligence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"policy-backend/export"
+	"policy-backend/storage"
+)
+
+// 导出任务状态
+const (
+	ExportStatusQueued  = "queued"  // 等待执行
+	ExportStatusRunning = "running" // 正在生成文件
+	ExportStatusDone    = "done"    // 已生成，可下载
+	ExportStatusFailed  = "failed"  // 生成失败
+)
+
+// exportKeyPrefix 导出文件在文件存储中的键前缀
+const exportKeyPrefix = "exports/"
+
+// exportJobTimeout 单个后台导出任务的超时时间
+const exportJobTimeout = 30 * time.Minute
+
+// ErrExportNotReady 导出任务尚未完成、已失败或文件已过期
+var ErrExportNotReady = errors.New("export not ready")
+
+// ExportJob 后台导出任务
+// 条数超过同步导出上限时由后台生成文件并写入文件存储（键为 exports/<id>.<格式>），过期后由定时任务清理
+type ExportJob struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	UserID     uint            `gorm:"index;comment:发起人ID" json:"user_id"`
+	Format     string          `gorm:"type:varchar(10);comment:导出格式:xlsx,csv,txt,jsonl" json:"format"`
+	Params     json.RawMessage `gorm:"type:json;comment:导出参数" json:"params"`
+	Status     string          `gorm:"type:varchar(20);index;default:'queued';comment:状态:queued排队中,running生成中,done已完成,failed失败" json:"status"`
+	Error      string          `gorm:"type:varchar(500)" json:"error,omitempty"`
+	Total      int64           `gorm:"default:0;comment:发起时统计的条数" json:"total"`
+	Rows       int             `gorm:"default:0;comment:实际导出的条数" json:"rows"`
+	FileName   string          `gorm:"type:varchar(100);comment:下载文件名" json:"file_name"`
+	StorageKey string          `gorm:"type:varchar(255);comment:文件存储中的键" json:"-"`
+	Size       int64           `gorm:"default:0;comment:文件字节数" json:"size"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+	ExpireAt   *time.Time      `gorm:"index;comment:文件过期时间" json:"expire_at,omitempty"`
+}
+
+// TableName 指定表名
+func (ExportJob) TableName() string {
+	return "export_jobs"
+}
+
+// Downloadable 导出文件是否可下载
+func (j *ExportJob) Downloadable(now time.Time) bool {
+	return j.Status == ExportStatusDone && j.StorageKey != "" && (j.ExpireAt == nil || now.Before(*j.ExpireAt))
+}
+
+// ExportFileName 导出文件名，如 intelligences-20240102-150405.xlsx
+func ExportFileName(format string, t time.Time) string {
+	return "intelligences-" + t.Format("20060102-150405") + export.Extension(format)
+}
+
+// StartExportJob 创建后台导出任务并立即返回，同时运行的任务数受 ExportConcurrency 限制
+func (s *Service) StartExportJob(userID uint, q ExportQuery, total int64) (*ExportJob, error) {
+	params, err := json.Marshal(q)
+	if err != nil {
+		return nil, err
+	}
+	job := &ExportJob{
+		UserID:   userID,
+		Format:   q.Format,
+		Params:   params,
+		Status:   ExportStatusQueued,
+		Total:    total,
+		FileName: ExportFileName(q.Format, time.Now()),
+	}
+	if err := s.db.Create(job).Error; err != nil {
+		return nil, err
+	}
+
+	go func() {
+		s.exportWorkers <- struct{}{}
+		defer func() { <-s.exportWorkers }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), exportJobTimeout)
+		defer cancel()
+		if err := s.runExportJob(ctx, job.ID, userID, q); err != nil {
+			zap.L().Warn("Export job failed", zap.Uint("job_id", job.ID), zap.Error(err))
+		}
+	}()
+	return job, nil
+}
+
+// runExportJob 生成导出文件并上传，结果写回任务记录
+func (s *Service) runExportJob(ctx context.Context, jobID, userID uint, q ExportQuery) error {
+	s.updateExportJob(jobID, map[string]interface{}{"status": ExportStatusRunning})
+
+	key := exportKeyPrefix + strconv.FormatUint(uint64(jobID), 10) + export.Extension(q.Format)
+	rows, size, err := s.writeExportFile(ctx, key, userID, q)
+	now := time.Now()
+	expireAt := now.Add(s.exportTTL())
+	if err != nil {
+		msg := err.Error()
+		if len(msg) > 500 {
+			msg = msg[:500]
+		}
+		s.updateExportJob(jobID, map[string]interface{}{
+			"status":      ExportStatusFailed,
+			"error":       msg,
+			"rows":        rows,
+			"finished_at": now,
+			"expire_at":   expireAt,
+		})
+		return err
+	}
+
+	return s.updateExportJob(jobID, map[string]interface{}{
+		"status":      ExportStatusDone,
+		"rows":        rows,
+		"storage_key": key,
+		"size":        size,
+		"finished_at": now,
+		"expire_at":   expireAt,
+	})
+}
+
+// writeExportFile 先写入本地临时文件，完整生成后再上传，避免存储中留下不完整的文件
+func (s *Service) writeExportFile(ctx context.Context, key string, userID uint, q ExportQuery) (int, int64, error) {
+	tmp, err := os.CreateTemp("", "export-*.tmp")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	w, err := newExportWriter(q.Format, tmp)
+	if err != nil {
+		return 0, 0, err
+	}
+	rows, err := s.WriteExport(ctx, w, userID, q)
+	if err != nil {
+		return rows, 0, err
+	}
+	if err := w.Close(); err != nil {
+		return rows, 0, err
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return rows, 0, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return rows, 0, err
+	}
+	if err := s.files.Put(ctx, key, tmp, size, export.ContentType(q.Format)); err != nil {
+		return rows, 0, err
+	}
+	return rows, size, nil
+}
+
+// exportTTL 导出任务的保留时长，失败的任务同样在到期后清理
+func (s *Service) exportTTL() time.Duration {
+	return time.Duration(s.cfg.ExportTTLHours) * time.Hour
+}
+
+// updateExportJob 更新导出任务字段
+func (s *Service) updateExportJob(jobID uint, fields map[string]interface{}) error {
+	return s.db.Model(&ExportJob{}).Where("id = ?", jobID).Updates(fields).Error
+}
+
+// ListExportJobs 分页获取用户的导出任务，按创建时间倒序
+func (s *Service) ListExportJobs(userID uint, page, pageSize int) ([]ExportJob, int64, error) {
+	var jobs []ExportJob
+	var total int64
+
+	db := s.db.Model(&ExportJob{}).Where("user_id = ?", userID)
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := db.Order("created_at DESC, id DESC").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&jobs).Error
+	return jobs, total, err
+}
+
+// GetExportJob 获取用户的导出任务，不存在或不属于该用户时返回 gorm.ErrRecordNotFound
+func (s *Service) GetExportJob(userID, jobID uint) (*ExportJob, error) {
+	var job ExportJob
+	if err := s.db.Where("id = ? AND user_id = ?", jobID, userID).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// OpenExport 打开导出任务生成的文件，调用方负责关闭
+// 任务不存在时返回 gorm.ErrRecordNotFound，未完成或已过期时返回 ErrExportNotReady
+func (s *Service) OpenExport(ctx context.Context, userID, jobID uint) (*ExportJob, storage.Object, error) {
+	job, err := s.GetExportJob(userID, jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !job.Downloadable(time.Now()) {
+		return job, nil, ErrExportNotReady
+	}
+	obj, err := s.files.Get(ctx, job.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return job, nil, ErrExportNotReady
+	}
+	if err != nil {
+		return job, nil, err
+	}
+	return job, obj, nil
+}
+
+// CleanupExpiredExports 删除过期的导出文件与任务记录，返回清理的任务数
+func (s *Service) CleanupExpiredExports(ctx context.Context) (int64, error) {
+	var jobs []ExportJob
+	if err := s.db.Where("expire_at IS NOT NULL AND expire_at < ?", time.Now()).Find(&jobs).Error; err != nil {
+		return 0, err
+	}
+
+	var cleaned int64
+	for _, job := range jobs {
+		if job.StorageKey != "" {
+			if err := s.files.Delete(ctx, job.StorageKey); err != nil {
+				zap.L().Warn("Failed to delete export file", zap.Uint("job_id", job.ID), zap.Error(err))
+				continue
+			}
+		}
+		if err := s.db.Delete(&ExportJob{}, job.ID).Error; err != nil {
+			return cleaned, err
+		}
+		cleaned++
+	}
+	return cleaned, nil
+}
+
+// FailInterruptedExports 将服务重启前未完成的导出任务标记为失败，返回受影响的任务数
+func (s *Service) FailInterruptedExports() (int64, error) {
+	now := time.Now()
+	result := s.db.Model(&ExportJob{}).
+		Where("status IN ?", []string{ExportStatusQueued, ExportStatusRunning}).
+		Updates(map[string]interface{}{
+			"status":      ExportStatusFailed,
+			"error":       "interrupted by server restart",
+			"finished_at": now,
+			"expire_at":   now.Add(s.exportTTL()),
+		})
+	return result.RowsAffected, result.Error
+}
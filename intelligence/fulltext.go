@@ -0,0 +1,129 @@
+package intelligence
+
+import (
+	"strings"
+
+	"policy-backend/query"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 全文索引后端名称
+const (
+	FullTextBackendFTS5  = "sqlite_fts5"
+	FullTextBackendMySQL = "mysql_fulltext"
+	FullTextBackendLike  = "like"
+)
+
+// FullTextIndex 情报全文索引
+// 不同数据库使用不同实现：SQLite 使用 FTS5 虚拟表，MySQL 使用 ngram FULLTEXT 索引，
+// 两者都不可用时退化为 LIKE 匹配并在应用层估算相关度。
+type FullTextIndex interface {
+	// Backend 返回后端名称
+	Backend() string
+	// Rank 返回命中任一检索词的情报及其相关度（id, score），用于布尔检索的排序
+	Rank(db *gorm.DB, terms []string) *gorm.DB
+	// Rebuild 全量重建索引
+	Rebuild(db *gorm.DB) error
+
+	// condition 将纯文本的检索子表达式编译为 intelligences 表上的全文检索条件
+	// 无法用该索引的语法表达时返回 false，由调用方拆分子表达式后逐个编译
+	condition(node query.Node) (clause.Expr, bool)
+}
+
+// InitFullText 初始化全文索引：按数据库类型建立索引结构、注册同步回调，并在首次建立时回填已有数据
+// 应在 AutoMigrate 之后调用一次
+func InitFullText(db *gorm.DB) (FullTextIndex, error) {
+	switch db.Dialector.Name() {
+	case "sqlite":
+		created, err := ensureFTS5Table(db)
+		if err != nil {
+			// 未以 sqlite_fts5 标签编译时 FTS5 不可用
+			zap.L().Warn("SQLite FTS5 unavailable, falling back to LIKE search", zap.Error(err))
+			return &likeIndex{}, nil
+		}
+		ix := &sqliteFTSIndex{}
+		if err := ix.registerCallbacks(db); err != nil {
+			return nil, err
+		}
+		if created {
+			if err := ix.Rebuild(db); err != nil {
+				return nil, err
+			}
+		}
+		return ix, nil
+	case "mysql":
+		if err := ensureMySQLFullText(db); err != nil {
+			zap.L().Warn("MySQL FULLTEXT index unavailable, falling back to LIKE search", zap.Error(err))
+			return &likeIndex{}, nil
+		}
+		return &mysqlFullTextIndex{}, nil
+	default:
+		return &likeIndex{}, nil
+	}
+}
+
+// DetectFullTextIndex 根据数据库中已建立的索引结构选择全文索引实现
+// 不会建表或注册回调，可在任意位置多次调用
+func DetectFullTextIndex(db *gorm.DB) FullTextIndex {
+	switch db.Dialector.Name() {
+	case "sqlite":
+		if db.Migrator().HasTable(ftsTableName) {
+			return &sqliteFTSIndex{}
+		}
+	case "mysql":
+		if db.Migrator().HasIndex(&Intelligence{}, mysqlFullTextIndexName) {
+			return &mysqlFullTextIndex{}
+		}
+	}
+	return &likeIndex{}
+}
+
+// likeIndex 基于 LIKE 的降级实现
+// 标题命中权重最高，其次摘要、关键词，正文最低
+type likeIndex struct{}
+
+// Backend 实现 FullTextIndex 接口
+func (likeIndex) Backend() string {
+	return FullTextBackendLike
+}
+
+// Rank 实现 FullTextIndex 接口
+func (likeIndex) Rank(db *gorm.DB, terms []string) *gorm.DB {
+	sub := db.Session(&gorm.Session{NewDB: true}).Table("intelligences")
+
+	scoreParts := []string{}
+	scoreVars := []interface{}{}
+	matchParts := []string{}
+	matchVars := []interface{}{}
+	for _, t := range terms {
+		kw := "%" + t + "%"
+		scoreParts = append(scoreParts,
+			"CASE WHEN title LIKE ? THEN 4 ELSE 0 END",
+			"CASE WHEN summary LIKE ? THEN 2 ELSE 0 END",
+			"CASE WHEN keywords LIKE ? THEN 2 ELSE 0 END",
+			"CASE WHEN content LIKE ? THEN 1 ELSE 0 END",
+		)
+		scoreVars = append(scoreVars, kw, kw, kw, kw)
+		matchParts = append(matchParts, "title LIKE ? OR summary LIKE ? OR keywords LIKE ? OR content LIKE ?")
+		matchVars = append(matchVars, kw, kw, kw, kw)
+	}
+
+	return sub.Select("id, ("+strings.Join(scoreParts, " + ")+") AS score", scoreVars...).
+		Where(strings.Join(matchParts, " OR "), matchVars...)
+}
+
+// condition 实现 FullTextIndex 接口，LIKE 模式只编译单个检索词
+func (likeIndex) condition(node query.Node) (clause.Expr, bool) {
+	if term, ok := node.(*query.Term); ok {
+		return likeTermCondition(term.Text), true
+	}
+	return clause.Expr{}, false
+}
+
+// Rebuild 实现 FullTextIndex 接口（LIKE 模式无需索引）
+func (likeIndex) Rebuild(db *gorm.DB) error {
+	return nil
+}
@@ -0,0 +1,121 @@
+package intelligence
+
+import (
+	"strings"
+
+	"policy-backend/query"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// mysqlFullTextIndexName FULLTEXT 索引名
+const mysqlFullTextIndexName = "ft_intelligences"
+
+// mysqlFullTextColumns 参与全文检索的列，MATCH() 的列必须与索引定义完全一致
+const mysqlFullTextColumns = "title, summary, keywords, content"
+
+// mysqlFullTextIndex 基于 MySQL InnoDB FULLTEXT (ngram parser) 的全文索引
+// 索引建在 intelligences 表上，由数据库自动维护，无需应用层同步
+type mysqlFullTextIndex struct{}
+
+// ensureMySQLFullText 建立 ngram FULLTEXT 索引（已存在则跳过）
+func ensureMySQLFullText(db *gorm.DB) error {
+	if db.Migrator().HasIndex(&Intelligence{}, mysqlFullTextIndexName) {
+		return nil
+	}
+	return db.Exec("ALTER TABLE intelligences ADD FULLTEXT INDEX " + mysqlFullTextIndexName +
+		" (" + mysqlFullTextColumns + ") WITH PARSER ngram").Error
+}
+
+// Backend 实现 FullTextIndex 接口
+func (mysqlFullTextIndex) Backend() string {
+	return FullTextBackendMySQL
+}
+
+// mysqlPhrase 将检索词转换为 BOOLEAN MODE 短语，检索词为空时返回空串
+func mysqlPhrase(term string) string {
+	term = strings.TrimSpace(strings.ReplaceAll(term, `"`, ""))
+	if term == "" {
+		return ""
+	}
+	return `"` + term + `"`
+}
+
+// Rank 实现 FullTextIndex 接口
+// 不带 + 前缀的短语之间为 OR 关系，命中越多相关度越高
+func (mysqlFullTextIndex) Rank(db *gorm.DB, terms []string) *gorm.DB {
+	parts := []string{}
+	for _, term := range terms {
+		if phrase := mysqlPhrase(term); phrase != "" {
+			parts = append(parts, phrase)
+		}
+	}
+	if len(parts) == 0 {
+		return likeIndex{}.Rank(db, terms)
+	}
+	expr := strings.Join(parts, " ")
+	against := "MATCH(" + mysqlFullTextColumns + ") AGAINST (? IN BOOLEAN MODE)"
+	return db.Session(&gorm.Session{NewDB: true}).
+		Table("intelligences").
+		Select("id, "+against+" AS score", expr).
+		Where(against, expr)
+}
+
+// condition 实现 FullTextIndex 接口
+func (mysqlFullTextIndex) condition(node query.Node) (clause.Expr, bool) {
+	expr, ok := mysqlNodeExpr(node)
+	if !ok {
+		return clause.Expr{}, false
+	}
+	return clause.Expr{
+		SQL:  "MATCH(" + mysqlFullTextColumns + ") AGAINST (? IN BOOLEAN MODE)",
+		Vars: []interface{}{"+" + expr},
+	}, true
+}
+
+// mysqlNodeExpr 将纯文本子表达式转换为 BOOLEAN MODE 语法
+// AND 的子条件加 + 或 - 前缀，OR 的子条件不加前缀；全部为排除条件的 AND 无法表达
+func mysqlNodeExpr(node query.Node) (string, bool) {
+	switch n := node.(type) {
+	case *query.Term:
+		phrase := mysqlPhrase(n.Text)
+		return phrase, phrase != ""
+	case *query.Or:
+		parts := make([]string, 0, len(n.Nodes))
+		for _, child := range n.Nodes {
+			expr, ok := mysqlNodeExpr(child)
+			if !ok {
+				return "", false
+			}
+			parts = append(parts, expr)
+		}
+		return "(" + strings.Join(parts, " ") + ")", true
+	case *query.And:
+		parts := make([]string, 0, len(n.Nodes))
+		positive := false
+		for _, child := range n.Nodes {
+			prefix := "+"
+			if not, ok := child.(*query.Not); ok {
+				child, prefix = not.Node, "-"
+			} else {
+				positive = true
+			}
+			expr, ok := mysqlNodeExpr(child)
+			if !ok {
+				return "", false
+			}
+			parts = append(parts, prefix+expr)
+		}
+		if !positive {
+			return "", false
+		}
+		return "(" + strings.Join(parts, " ") + ")", true
+	}
+	return "", false
+}
+
+// Rebuild 实现 FullTextIndex 接口
+func (mysqlFullTextIndex) Rebuild(db *gorm.DB) error {
+	return db.Exec("OPTIMIZE TABLE intelligences").Error
+}
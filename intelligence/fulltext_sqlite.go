@@ -0,0 +1,303 @@
+package intelligence
+
+import (
+	"reflect"
+	"strings"
+
+	"policy-backend/query"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ftsTableName FTS5 虚拟表名，rowid 与 intelligences.id 一致
+const ftsTableName = "intelligences_fts"
+
+// ftsRebuildBatchSize 重建索引时每批处理的记录数
+const ftsRebuildBatchSize = 500
+
+// sqliteFTSIndex 基于 SQLite FTS5 的全文索引
+// 索引列存放 SegmentText 切分后以空格连接的词元，以支持中文检索
+type sqliteFTSIndex struct{}
+
+// ensureFTS5Table 创建 FTS5 虚拟表，返回是否为新建
+func ensureFTS5Table(db *gorm.DB) (bool, error) {
+	if db.Migrator().HasTable(ftsTableName) {
+		return false, nil
+	}
+	err := db.Exec("CREATE VIRTUAL TABLE " + ftsTableName +
+		" USING fts5(title, summary, keywords, content, tokenize = 'unicode61 remove_diacritics 2')").Error
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Backend 实现 FullTextIndex 接口
+func (sqliteFTSIndex) Backend() string {
+	return FullTextBackendFTS5
+}
+
+// ftsPhrase 将检索词切分为词元后组成 FTS5 短语；单个汉字使用前缀匹配
+// 检索词不含可索引字符时返回空串
+func ftsPhrase(term string) string {
+	tokens := SegmentText(term)
+	if len(tokens) == 0 {
+		return ""
+	}
+	phrase := `"` + strings.Join(tokens, " ") + `"`
+	if len(tokens) == 1 && len([]rune(tokens[0])) == 1 && isCJK([]rune(tokens[0])[0]) {
+		phrase += "*"
+	}
+	return phrase
+}
+
+// Rank 实现 FullTextIndex 接口
+func (sqliteFTSIndex) Rank(db *gorm.DB, terms []string) *gorm.DB {
+	parts := []string{}
+	for _, term := range terms {
+		if phrase := ftsPhrase(term); phrase != "" {
+			parts = append(parts, phrase)
+		}
+	}
+	if len(parts) == 0 {
+		return likeIndex{}.Rank(db, terms)
+	}
+	return db.Session(&gorm.Session{NewDB: true}).
+		Table(ftsTableName).
+		Select("rowid AS id, -bm25("+ftsTableName+", 10.0, 5.0, 3.0, 1.0) AS score").
+		Where(ftsTableName+" MATCH ?", strings.Join(parts, " OR "))
+}
+
+// condition 实现 FullTextIndex 接口
+func (sqliteFTSIndex) condition(node query.Node) (clause.Expr, bool) {
+	expr, ok := ftsNodeExpr(node)
+	if !ok {
+		return clause.Expr{}, false
+	}
+	return clause.Expr{
+		SQL:  "intelligences.id IN (SELECT rowid FROM " + ftsTableName + " WHERE " + ftsTableName + " MATCH ?)",
+		Vars: []interface{}{expr},
+	}, true
+}
+
+// ftsNodeExpr 将纯文本子表达式转换为 FTS5 查询语法
+// FTS5 的 NOT 是二元运算符，因此 NOT 只能出现在含肯定条件的 AND 中，如 "a NOT b"
+func ftsNodeExpr(node query.Node) (string, bool) {
+	switch n := node.(type) {
+	case *query.Term:
+		phrase := ftsPhrase(n.Text)
+		return phrase, phrase != ""
+	case *query.Or:
+		parts := make([]string, 0, len(n.Nodes))
+		for _, child := range n.Nodes {
+			expr, ok := ftsNodeExpr(child)
+			if !ok {
+				return "", false
+			}
+			parts = append(parts, expr)
+		}
+		return "(" + strings.Join(parts, " OR ") + ")", true
+	case *query.And:
+		positive, negative := []string{}, []string{}
+		for _, child := range n.Nodes {
+			target := &positive
+			if not, ok := child.(*query.Not); ok {
+				child, target = not.Node, &negative
+			}
+			expr, ok := ftsNodeExpr(child)
+			if !ok {
+				return "", false
+			}
+			*target = append(*target, expr)
+		}
+		if len(positive) == 0 {
+			return "", false
+		}
+		expr := "(" + strings.Join(positive, " AND ") + ")"
+		for _, neg := range negative {
+			expr = "(" + expr + " NOT " + neg + ")"
+		}
+		return expr, true
+	}
+	return "", false
+}
+
+// Rebuild 实现 FullTextIndex 接口
+func (ix sqliteFTSIndex) Rebuild(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM " + ftsTableName).Error; err != nil {
+			return err
+		}
+		var batch []Intelligence
+		return tx.Model(&Intelligence{}).FindInBatches(&batch, ftsRebuildBatchSize, func(b *gorm.DB, _ int) error {
+			for i := range batch {
+				if err := ix.insert(tx, &batch[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	})
+}
+
+// insert 写入单条情报的索引
+func (sqliteFTSIndex) insert(db *gorm.DB, it *Intelligence) error {
+	return db.Exec("INSERT INTO "+ftsTableName+" (rowid, title, summary, keywords, content) VALUES (?, ?, ?, ?, ?)",
+		it.ID,
+		strings.Join(SegmentText(it.Title), " "),
+		strings.Join(SegmentText(it.Summary), " "),
+		strings.Join(SegmentText(it.Keywords), " "),
+		strings.Join(SegmentText(it.Content), " "),
+	).Error
+}
+
+// reindex 重新读取指定情报并覆盖其索引
+func (ix sqliteFTSIndex) reindex(db *gorm.DB, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var rows []Intelligence
+	if err := db.Where("id IN ?", ids).Find(&rows).Error; err != nil {
+		return err
+	}
+	if err := db.Exec("DELETE FROM "+ftsTableName+" WHERE rowid IN ?", ids).Error; err != nil {
+		return err
+	}
+	for i := range rows {
+		if err := ix.insert(db, &rows[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 同步回调名称
+const (
+	ftsCallbackSave    = "fulltext:sync_save"
+	ftsCallbackDelete  = "fulltext:sync_delete"
+	ftsCallbackCollect = "fulltext:collect_ids"
+)
+
+// ftsMatchedIDsKey 更新前按条件查出的情报ID在语句中的存放键
+const ftsMatchedIDsKey = "fulltext:matched_ids"
+
+// ftsIndexedColumns 参与全文索引的列
+var ftsIndexedColumns = map[string]bool{"title": true, "summary": true, "keywords": true, "content": true}
+
+// registerCallbacks 注册 GORM 回调，使创建、更新、删除情报时索引自动同步
+// 回调在写入所在的事务中执行，事务回滚时索引一并回滚；
+// 只带条件的批量更新（如 db.Model(&Intelligence{}).Where(...).Update(...)）在更新前按条件查出受影响的情报，更新后重建其索引
+func (ix sqliteFTSIndex) registerCallbacks(db *gorm.DB) error {
+	if db.Callback().Create().Get(ftsCallbackSave) != nil {
+		return nil
+	}
+	if err := db.Callback().Create().After("gorm:create").Register(ftsCallbackSave, ix.afterSave); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register(ftsCallbackCollect, ix.beforeUpdate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register(ftsCallbackSave, ix.afterSave); err != nil {
+		return err
+	}
+	return registerDeleteCleanup(db, ftsCallbackDelete, ix.deleteRows)
+}
+
+// beforeUpdate 模型值不带主键的更新涉及索引列时，按语句的条件查出将被更新的情报ID
+// 须在更新前查询，否则条件中引用了被更新的列时（如按旧标题改标题）更新后就查不到了
+func (ix sqliteFTSIndex) beforeUpdate(tx *gorm.DB) {
+	if !isIntelligenceStatement(tx) || len(statementIDs(tx)) > 0 || !updatesIndexedColumns(tx) {
+		return
+	}
+	where, ok := tx.Statement.Clauses["WHERE"]
+	if !ok && !tx.Statement.AllowGlobalUpdate {
+		return // 没有条件的更新会被 GORM 拒绝
+	}
+
+	query := tx.Session(&gorm.Session{NewDB: true}).Model(&Intelligence{})
+	if ok {
+		query = query.Clauses(where.Expression)
+	}
+	var ids []uint
+	if err := query.Pluck("intelligences.id", &ids).Error; err != nil {
+		_ = tx.AddError(err)
+		return
+	}
+	tx.Statement.Settings.Store(ftsMatchedIDsKey, ids)
+}
+
+// updatesIndexedColumns 更新内容是否可能涉及索引列
+// 以 map 指定的更新可逐列判断，其他形式（结构体、表达式）保守地视为涉及
+func updatesIndexedColumns(tx *gorm.DB) bool {
+	values, ok := tx.Statement.Dest.(map[string]interface{})
+	if !ok {
+		return true
+	}
+	for name := range values {
+		column := name
+		if field := tx.Statement.Schema.LookUpField(name); field != nil {
+			column = field.DBName
+		}
+		if ftsIndexedColumns[column] {
+			return true
+		}
+	}
+	return false
+}
+
+// afterSave 创建/更新情报后重建对应索引
+func (ix sqliteFTSIndex) afterSave(tx *gorm.DB) {
+	if !isIntelligenceStatement(tx) {
+		return
+	}
+	ids := statementIDs(tx)
+	if matched, ok := tx.Statement.Settings.Load(ftsMatchedIDsKey); ok {
+		ids = append(ids, matched.([]uint)...)
+	}
+	if err := ix.reindex(tx.Session(&gorm.Session{NewDB: true}), ids); err != nil {
+		_ = tx.AddError(err)
+	}
+}
+
+// deleteRows 删除情报后清理其索引行
+func (ix sqliteFTSIndex) deleteRows(db *gorm.DB, ids []uint) error {
+	return db.Exec("DELETE FROM "+ftsTableName+" WHERE rowid IN ?", ids).Error
+}
+
+// isIntelligenceStatement 判断当前语句是否成功作用于情报表
+func isIntelligenceStatement(tx *gorm.DB) bool {
+	return tx.Error == nil &&
+		tx.Statement.Schema != nil &&
+		tx.Statement.Schema.Table == (Intelligence{}).TableName()
+}
+
+// statementIDs 从语句的模型值中提取非零主键
+func statementIDs(tx *gorm.DB) []uint {
+	field := tx.Statement.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return nil
+	}
+
+	ids := []uint{}
+	collect := func(v reflect.Value) {
+		value, zero := field.ValueOf(tx.Statement.Context, v)
+		if zero {
+			return
+		}
+		if id, ok := value.(uint); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	rv := reflect.Indirect(tx.Statement.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			collect(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		collect(rv)
+	}
+	return ids
+}
@@ -1,11 +1,20 @@
 package intelligence
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"policy-backend/export"
+	"policy-backend/query"
+	"policy-backend/user"
 	"policy-backend/utils"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type Handler struct {
@@ -43,9 +52,11 @@ func (h *Handler) GetIntelligenceDetail(c echo.Context) error {
 		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
 	}
 
-	// 获取当前用户ID (待完善：从token获取)
-	// currently hardcoded or assumed to be 0 if not logged in
+	// 当前用户ID，用于取我的评分与可见的标签；未登录时为 0
 	userID := uint(0)
+	if currentUser, ok := c.Get("user").(*user.User); ok {
+		userID = currentUser.ID
+	}
 
 	detail, err := h.svc.GetIntelligenceDetail(uint(id), userID)
 	if err != nil {
@@ -55,6 +66,35 @@ func (h *Handler) GetIntelligenceDetail(c echo.Context) error {
 	return utils.Success(c, detail)
 }
 
+// GetIntelligencePDF 在线阅读/下载情报的 PDF 原文
+// 支持 Range 请求，便于浏览器内分段加载
+func (h *Handler) GetIntelligencePDF(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
+	}
+
+	_, obj, err := h.svc.OpenPDF(c.Request().Context(), uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.Error(c, http.StatusNotFound, "Intelligence not found")
+	}
+	if errors.Is(err, ErrPDFNotFound) {
+		return utils.Error(c, http.StatusNotFound, "PDF not found")
+	}
+	if err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to open PDF")
+	}
+	defer obj.Close()
+
+	// ServeContent 负责 Range、If-Modified-Since 等条件请求
+	name := idStr + ".pdf"
+	c.Response().Header().Set(echo.HeaderContentType, "application/pdf")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", name))
+	http.ServeContent(c.Response(), c.Request(), name, obj.Info().ModTime, obj)
+	return nil
+}
+
 // ListIntelligences 获取情报列表
 func (h *Handler) ListIntelligences(c echo.Context) error {
 	page, _ := strconv.Atoi(c.QueryParam("page"))
@@ -66,8 +106,24 @@ func (h *Handler) ListIntelligences(c echo.Context) error {
 		pageSize = 10
 	}
 	keyword := c.QueryParam("keyword")
+	hasPDF, _ := strconv.ParseBool(c.QueryParam("has_pdf"))
+	tagIDs, err := parseIDList(c.QueryParam("tags"))
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid tags")
+	}
+	userID := uint(0)
+	if currentUser, ok := c.Get("user").(*user.User); ok {
+		userID = currentUser.ID
+	}
 
-	data, total, err := h.svc.ListIntelligences(page, pageSize, keyword)
+	data, total, err := h.svc.ListIntelligences(page, pageSize, keyword, hasPDF, tagIDs, userID)
+	var queryErr *query.Error
+	if errors.As(err, &queryErr) {
+		return utils.FailWithData(c, http.StatusBadRequest, "Invalid query: "+queryErr.Error(), queryErr)
+	}
+	if errors.Is(err, ErrTagNotFound) {
+		return utils.Error(c, http.StatusBadRequest, "Tag not found")
+	}
 	if err != nil {
 		return utils.Error(c, http.StatusInternalServerError, "Failed to fetch list")
 	}
@@ -93,6 +149,36 @@ func (h *Handler) DeleteIntelligence(c echo.Context) error {
 	return utils.Success(c, nil)
 }
 
+// ReextractKeywords 按当前语料统计重新抽取情报的关键词，覆盖原有的 Keywords
+// POST /api/intelligence/:id/keywords/extract
+func (h *Handler) ReextractKeywords(c echo.Context) error {
+	if _, ok := c.Get("user").(*user.User); !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
+	}
+
+	it, keywords, err := h.svc.ReextractKeywords(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.Error(c, http.StatusNotFound, "Intelligence not found")
+	}
+	if errors.Is(err, ErrKeywordsDisabled) {
+		return utils.Error(c, http.StatusServiceUnavailable, "Keyword extraction is not enabled")
+	}
+	if err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to extract keywords")
+	}
+
+	return utils.Success(c, map[string]interface{}{
+		"id":       it.ID,
+		"keywords": it.Keywords,
+		"weights":  keywords,
+	})
+}
+
 // RateIntelligence 评分
 type RatingRequest struct {
 	Score int `json:"score"`
@@ -133,3 +219,186 @@ func (h *Handler) ShareIntelligence(c echo.Context) error {
 
 	return utils.Success(c, nil)
 }
+
+// ExportIntelligences 批量导出情报
+// 指定 ids（逗号分隔）时按给出的顺序导出，否则按 keyword、has_pdf、tags 与列表相同的条件导出；
+// 两种方式都限定在 scope（all/mine/team，默认 all）与 team_id 范围内，指定的情报超出范围时返回 403；
+// 条数不超过同步上限时直接下载文件，超过上限或 async=true 时创建后台导出任务并返回任务信息
+func (h *Handler) ExportIntelligences(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	format, err := export.ParseFormat(c.QueryParam("format"))
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid format, expected one of: "+strings.Join(export.Formats, ", "))
+	}
+	ids, err := parseIDList(c.QueryParam("ids"))
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ids")
+	}
+	tagIDs, err := parseIDList(c.QueryParam("tags"))
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid tags")
+	}
+	if err := h.svc.CheckTagFilter(currentUser.ID, tagIDs); err != nil {
+		if errors.Is(err, ErrTagNotFound) {
+			return utils.Error(c, http.StatusBadRequest, "Tag not found")
+		}
+		return utils.Error(c, http.StatusInternalServerError, "Failed to check tags")
+	}
+	scope := c.QueryParam("scope")
+	switch scope {
+	case "":
+		scope = "all"
+	case "all", "mine", "team":
+	default:
+		return utils.Error(c, http.StatusBadRequest, "Invalid scope, expected one of: all, mine, team")
+	}
+	var teamID uint64
+	if s := c.QueryParam("team_id"); s != "" {
+		if teamID, err = strconv.ParseUint(s, 10, 32); err != nil {
+			return utils.Error(c, http.StatusBadRequest, "Invalid team_id")
+		}
+	}
+	hasPDF, _ := strconv.ParseBool(c.QueryParam("has_pdf"))
+	async, _ := strconv.ParseBool(c.QueryParam("async"))
+	q := ExportQuery{Format: format, Scope: scope, TeamID: uint(teamID), IDs: ids, Keyword: c.QueryParam("keyword"), HasPDF: hasPDF, Tags: tagIDs}
+
+	total, err := h.svc.CountExport(currentUser.ID, q)
+	var queryErr *query.Error
+	if errors.As(err, &queryErr) {
+		return utils.FailWithData(c, http.StatusBadRequest, "Invalid query: "+queryErr.Error(), queryErr)
+	}
+	if errors.Is(err, ErrExportOutOfScope) {
+		return utils.Error(c, http.StatusForbidden, "Some intelligences are outside the export scope")
+	}
+	if err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to count intelligences")
+	}
+	if total > int64(h.svc.cfg.ExportMaxRows) {
+		return utils.Error(c, http.StatusBadRequest,
+			fmt.Sprintf("Too many intelligences to export: %d (limit %d)", total, h.svc.cfg.ExportMaxRows))
+	}
+
+	if async || total > int64(h.svc.cfg.ExportSyncLimit) {
+		job, err := h.svc.StartExportJob(currentUser.ID, q, total)
+		if err != nil {
+			return utils.Error(c, http.StatusInternalServerError, "Failed to create export job")
+		}
+		return utils.Success(c, job)
+	}
+
+	// 同步导出：边读边写，响应头发出后出错只能中断连接
+	name := ExportFileName(format, time.Now())
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, export.ContentType(format))
+	resp.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", name))
+	resp.WriteHeader(http.StatusOK)
+
+	w, err := newExportWriter(format, resp)
+	if err != nil {
+		return err
+	}
+	if _, err := h.svc.WriteExport(c.Request().Context(), w, currentUser.ID, q); err != nil {
+		zap.L().Warn("Failed to export intelligences", zap.Error(err))
+		return err
+	}
+	return w.Close()
+}
+
+// ListExportJobs 获取我的后台导出任务
+func (h *Handler) ListExportJobs(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(c.QueryParam("page_size"))
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	jobs, total, err := h.svc.ListExportJobs(currentUser.ID, page, pageSize)
+	if err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to fetch export jobs")
+	}
+
+	return utils.Success(c, map[string]interface{}{
+		"list":  jobs,
+		"total": total,
+	})
+}
+
+// GetExportJob 查询后台导出任务的状态
+func (h *Handler) GetExportJob(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
+	}
+
+	job, err := h.svc.GetExportJob(currentUser.ID, uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.Error(c, http.StatusNotFound, "Export job not found")
+	}
+	if err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to fetch export job")
+	}
+
+	return utils.Success(c, job)
+}
+
+// DownloadExport 下载后台导出任务生成的文件
+func (h *Handler) DownloadExport(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
+	}
+
+	job, obj, err := h.svc.OpenExport(c.Request().Context(), currentUser.ID, uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.Error(c, http.StatusNotFound, "Export job not found")
+	}
+	if errors.Is(err, ErrExportNotReady) {
+		return utils.Error(c, http.StatusConflict, "Export file is not ready or has expired")
+	}
+	if err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to open export file")
+	}
+	defer obj.Close()
+
+	c.Response().Header().Set(echo.HeaderContentType, export.ContentType(job.Format))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", job.FileName))
+	http.ServeContent(c.Response(), c.Request(), job.FileName, obj.Info().ModTime, obj)
+	return nil
+}
+
+// parseIDList 解析逗号分隔的ID列表，空字符串返回 nil
+func parseIDList(s string) ([]uint, error) {
+	var ids []uint
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || id == 0 {
+			return nil, fmt.Errorf("invalid id %q", part)
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
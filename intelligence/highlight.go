@@ -0,0 +1,112 @@
+package intelligence
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// 高亮标记
+const (
+	highlightOpen  = "<em>"
+	highlightClose = "</em>"
+)
+
+// defaultSnippetLength 摘要片段默认长度（字符数）
+const defaultSnippetLength = 120
+
+// Highlight 检索结果高亮信息
+type Highlight struct {
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"`
+}
+
+// BuildHighlight 为情报生成标题高亮和正文片段
+// 片段优先取正文中第一个命中位置附近的文字，正文未命中时使用摘要
+func BuildHighlight(title, summary, content, query string) *Highlight {
+	terms := QueryTerms(query)
+
+	snippetSource := content
+	if findFirst([]rune(strings.ToLower(content)), terms) < 0 {
+		snippetSource = summary
+	}
+
+	return &Highlight{
+		Title:   HighlightText(title, terms, 0),
+		Snippet: HighlightText(snippetSource, terms, defaultSnippetLength),
+	}
+}
+
+// HighlightText 用 <em></em> 包裹命中的检索词，其余文字做 HTML 转义
+// maxRunes > 0 时截取第一个命中位置附近 maxRunes 个字符，截断处以省略号标记
+func HighlightText(text string, terms []string, maxRunes int) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	start, end := 0, len(runes)
+	if maxRunes > 0 && len(runes) > maxRunes {
+		if first := findFirst(lower, terms); first > 0 {
+			start = first - maxRunes/4
+			if start < 0 {
+				start = 0
+			}
+		}
+		end = start + maxRunes
+		if end > len(runes) {
+			end = len(runes)
+			start = end - maxRunes
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	plainFrom := start
+	for i := start; i < end; {
+		n := matchAt(lower, i, end, terms)
+		if n == 0 {
+			i++
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[plainFrom:i])))
+		b.WriteString(highlightOpen)
+		b.WriteString(html.EscapeString(string(runes[i : i+n])))
+		b.WriteString(highlightClose)
+		i += n
+		plainFrom = i
+	}
+	b.WriteString(html.EscapeString(string(runes[plainFrom:end])))
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// findFirst 返回任一检索词第一次出现的位置，未命中返回 -1
+func findFirst(lower []rune, terms []string) int {
+	for i := range lower {
+		if matchAt(lower, i, len(lower), terms) > 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// matchAt 判断 pos 处是否命中某个检索词，返回命中长度（优先最长的检索词）
+func matchAt(lower []rune, pos, end int, terms []string) int {
+	best := 0
+	for _, t := range terms {
+		tr := []rune(t)
+		if len(tr) <= best || pos+len(tr) > end {
+			continue
+		}
+		if string(lower[pos:pos+len(tr)]) == t {
+			best = len(tr)
+		}
+	}
+	return best
+}
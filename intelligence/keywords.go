@@ -0,0 +1,183 @@
+package intelligence
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"unicode/utf8"
+
+	"policy-backend/keyword"
+
+	"gorm.io/gorm"
+)
+
+// 关键词抽取参数
+const (
+	keywordBatchSize    = 200   // 重建语料与回填时每批处理的记录数
+	keywordMaxBodyRunes = 20000 // 参与抽取的摘要与正文的最大字数，过长的正文只取开头
+)
+
+// 关键词回调名称
+const (
+	keywordCallbackFill    = "keywords:fill"
+	keywordCallbackObserve = "keywords:observe"
+)
+
+// ErrKeywordsDisabled 未配置关键词抽取器
+var ErrKeywordsDisabled = errors.New("keyword extraction is not enabled")
+
+// keywordBody 参与抽取的正文部分：摘要与正文
+func keywordBody(it *Intelligence) string {
+	body := it.Summary + "\n" + it.Content
+	if utf8.RuneCountInString(body) > keywordMaxBodyRunes {
+		body = string([]rune(body)[:keywordMaxBodyRunes])
+	}
+	return body
+}
+
+// registerKeywordCallbacks 注册 GORM 回调：创建情报时 Keywords 为空则自动抽取，创建后计入语料统计
+// 与内容指纹一样，保证手工创建、检索导入、文件导入等任何入口创建的情报都有关键词
+func registerKeywordCallbacks(db *gorm.DB, extractor *keyword.Extractor) error {
+	if db.Callback().Create().Get(keywordCallbackFill) != nil {
+		return nil
+	}
+	err := db.Callback().Create().Before("gorm:create").Register(keywordCallbackFill, func(tx *gorm.DB) {
+		for _, it := range statementIntelligences(tx) {
+			if it.Keywords == "" {
+				it.Keywords = keyword.Join(extractor.Extract(it.Title, keywordBody(it)))
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return db.Callback().Create().After("gorm:create").Register(keywordCallbackObserve, func(tx *gorm.DB) {
+		if tx.Error != nil {
+			return
+		}
+		for _, it := range statementIntelligences(tx) {
+			extractor.Observe(it.Title, keywordBody(it))
+		}
+	})
+}
+
+// statementIntelligences 取出语句中待写入的情报（单条或切片），其他表的语句返回空
+func statementIntelligences(tx *gorm.DB) []*Intelligence {
+	if tx.Statement.Schema == nil || tx.Statement.Schema.Table != (Intelligence{}).TableName() {
+		return nil
+	}
+
+	var out []*Intelligence
+	collect := func(v reflect.Value) {
+		v = reflect.Indirect(v)
+		if v.CanAddr() {
+			if it, ok := v.Addr().Interface().(*Intelligence); ok {
+				out = append(out, it)
+			}
+		}
+	}
+	rv := reflect.Indirect(tx.Statement.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			collect(rv.Index(i))
+		}
+	case reflect.Struct:
+		collect(rv)
+	}
+	return out
+}
+
+// ExtractKeywords 按当前语料统计为情报抽取关键词（不写入数据库）
+func (s *Service) ExtractKeywords(it *Intelligence) ([]keyword.Keyword, error) {
+	if s.keywords == nil {
+		return nil, ErrKeywordsDisabled
+	}
+	return s.keywords.Extract(it.Title, keywordBody(it)), nil
+}
+
+// ReextractKeywords 重新抽取单条情报的关键词并覆盖 Keywords，同步更新由关键词生成的标签
+// 情报不存在时返回 gorm.ErrRecordNotFound
+func (s *Service) ReextractKeywords(id uint) (*Intelligence, []keyword.Keyword, error) {
+	var it Intelligence
+	if err := s.db.First(&it, id).Error; err != nil {
+		return nil, nil, err
+	}
+
+	keywords, err := s.ExtractKeywords(&it)
+	if err != nil {
+		return nil, nil, err
+	}
+	joined := keyword.Join(keywords)
+	if err := s.db.Model(&it).Update("keywords", joined).Error; err != nil {
+		return nil, nil, err
+	}
+	if err := syncKeywordTags(s.db, it.ID, joined); err != nil {
+		return nil, nil, err
+	}
+	return &it, keywords, nil
+}
+
+// RebuildKeywordCorpus 由情报库重新统计语料（文档数与各词的文档频率），完成后整体替换，返回文档数
+func (s *Service) RebuildKeywordCorpus(ctx context.Context) (int, error) {
+	if s.keywords == nil {
+		return 0, ErrKeywordsDisabled
+	}
+
+	corpus := keyword.NewCorpus()
+	var batch []Intelligence
+	err := s.db.Model(&Intelligence{}).
+		Select("id, title, summary, content").
+		FindInBatches(&batch, keywordBatchSize, func(tx *gorm.DB, _ int) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			for i := range batch {
+				s.keywords.ObserveInto(corpus, batch[i].Title, keywordBody(&batch[i]))
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return 0, err
+	}
+
+	s.keywords.SetCorpus(corpus)
+	return corpus.Docs(), nil
+}
+
+// BackfillKeywords 为 Keywords 为空的情报补充关键词，force 为 true 时重新抽取全部情报，返回更新条数
+// 只更新 keywords 列，不修改 updated_at；由关键词生成的标签随之同步
+func (s *Service) BackfillKeywords(ctx context.Context, force bool) (int, error) {
+	if s.keywords == nil {
+		return 0, ErrKeywordsDisabled
+	}
+
+	q := s.db.Model(&Intelligence{}).Select("id, title, summary, content, keywords")
+	if !force {
+		q = q.Where("keywords IS NULL OR keywords = ''")
+	}
+
+	updated := 0
+	var batch []Intelligence
+	err := q.FindInBatches(&batch, keywordBatchSize, func(tx *gorm.DB, _ int) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for i := range batch {
+			it := &batch[i]
+			keywords := keyword.Join(s.keywords.Extract(it.Title, keywordBody(it)))
+			if keywords == "" || keywords == it.Keywords {
+				continue
+			}
+			if err := s.db.Model(&Intelligence{ID: it.ID}).UpdateColumn("keywords", keywords).Error; err != nil {
+				return err
+			}
+			if err := syncKeywordTags(s.db, it.ID, keywords); err != nil {
+				return err
+			}
+			updated++
+		}
+		return nil
+	}).Error
+	return updated, err
+}
@@ -8,22 +8,41 @@ import (
 
 // Intelligence 情报信息
 type Intelligence struct {
-	ID            uint      `json:"id" gorm:"primaryKey"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
-	Title         string    `json:"title" gorm:"not null;index"`
-	Content       string    `json:"content" gorm:"type:text"`
-	AgencyID      uint      `json:"agency_id" gorm:"index"`
-	Source        string    `json:"source" gorm:"type:varchar(200)"`
-	URL           string    `json:"url" gorm:"type:text"`
-	Summary       string    `json:"summary" gorm:"type:text"`
-	Keywords      string    `json:"keywords" gorm:"type:text"`
-	DataHash      string    `json:"data_hash" gorm:"type:varchar(64);index"`
-	ContributorID uint      `json:"contributor_id" gorm:"not null;index"`
-	UserID        uint      `json:"user_id" gorm:"not null;index"`
-	TeamID        *uint     `json:"team_id,omitempty" gorm:"index"`
-	PublishDate   time.Time `json:"publish_date"`
-	Status        string    `json:"status" gorm:"type:varchar(20);default:'temporary'"` // temporary: 临时, official: 正式
+	ID                 uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+	Title              string    `json:"title" gorm:"not null;index"`
+	Content            string    `json:"content" gorm:"type:text"`
+	AgencyID           uint      `json:"agency_id" gorm:"index"`
+	CountryID          uint      `json:"country_id" gorm:"index"` // 所属国家，导入时由机构识别结果带入
+	Source             string    `json:"source" gorm:"type:varchar(200)"`
+	URL                string    `json:"url" gorm:"type:text"`
+	CanonicalURL       string    `json:"canonical_url" gorm:"type:text"` // 规范化后的 URL，DataHash 由其计算
+	Summary            string    `json:"summary" gorm:"type:text"`
+	Keywords           string    `json:"keywords" gorm:"type:text"`
+	DataHash           string    `json:"data_hash" gorm:"type:varchar(64);index"`
+	HashVersion        int       `json:"-" gorm:"not null;default:0"` // 计算 CanonicalURL 与 DataHash 所用的规则版本，见 search.RecomputeDataHashes
+	ContentFingerprint `gorm:"embedded"`
+	ContributorID      uint      `json:"contributor_id" gorm:"not null;index"`
+	UserID             uint      `json:"user_id" gorm:"not null;index"`
+	TeamID             *uint     `json:"team_id,omitempty" gorm:"index"`
+	PublishDate        time.Time `json:"publish_date"`
+	Status             string    `json:"status" gorm:"type:varchar(20);default:'temporary'"` // temporary: 临时, official: 正式
+
+	// PDF 原文：导入时识别到 PDF 链接则在后台下载保存，通过 /intelligence/:id/pdf 在线阅读
+	PDFURL    string `json:"pdf_url,omitempty" gorm:"column:pdf_url;type:text"`
+	PDFStatus string `json:"pdf_status,omitempty" gorm:"column:pdf_status;type:varchar(20)"` // pending, stored, failed
+	PDFPath   string `json:"-" gorm:"column:pdf_path;type:varchar(255)"`
+	PDFSize   int64  `json:"pdf_size,omitempty" gorm:"column:pdf_size"`
+	HasPDF    bool   `json:"has_pdf" gorm:"column:has_pdf;index;default:false"`
+}
+
+// BeforeCreate 入库前补全内容指纹，保证任何入口创建的情报都可参与近似查重
+func (i *Intelligence) BeforeCreate(tx *gorm.DB) error {
+	if i.ContentFingerprint.IsEmpty() {
+		i.ContentFingerprint = NewContentFingerprint(i.Title, i.Content)
+	}
+	return nil
 }
 
 // 常量定义状态
@@ -0,0 +1,244 @@
+package intelligence
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"policy-backend/safehttp"
+	"policy-backend/storage"
+)
+
+// PDF 抓取状态
+const (
+	PDFStatusPending = "pending" // 已识别到 PDF 链接，等待下载
+	PDFStatusStored  = "stored"  // 已下载保存
+	PDFStatusFailed  = "failed"  // 下载失败、超过大小上限或内容不是 PDF
+)
+
+// pdfSniffLen 用于判断文件类型的头部字节数，与 http.DetectContentType 一致
+const pdfSniffLen = 512
+
+// pdfKeyPrefix PDF 原文在文件存储中的键前缀
+const pdfKeyPrefix = "pdf/"
+
+var (
+	// ErrPDFNotFound 情报没有已保存的 PDF
+	ErrPDFNotFound = errors.New("pdf not found")
+	// errPDFTooLarge 超过大小上限
+	errPDFTooLarge = errors.New("pdf exceeds size limit")
+	// errNotPDF 内容不是 PDF
+	errNotPDF = errors.New("content is not a pdf")
+)
+
+// IsPDFLink 判断链接是否指向 PDF 文件（按路径扩展名）
+func IsPDFLink(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(path.Ext(u.Path), ".pdf")
+}
+
+// PDFStore 情报 PDF 原文的抓取与存储
+// 导入时识别到 PDF 链接的情报标记为 pending，由 CaptureAsync 在后台下载，
+// 校验大小与文件类型后写入文件存储（键为 pdf/<id>.pdf），并更新情报的 has_pdf 等字段
+type PDFStore struct {
+	db      *gorm.DB
+	cfg     Config
+	files   storage.Storage
+	client  *http.Client
+	workers chan struct{}
+}
+
+// NewPDFStore 创建 PDF 存储
+func NewPDFStore(db *gorm.DB, cfg *Config, files storage.Storage) *PDFStore {
+	concurrency := cfg.PDFFetchConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &PDFStore{
+		db:      db,
+		cfg:     *cfg,
+		files:   files,
+		client:  safehttp.NewClient(time.Duration(cfg.PDFFetchTimeoutSeconds) * time.Second),
+		workers: make(chan struct{}, concurrency),
+	}
+}
+
+// maxSize 单个 PDF 的大小上限（字节）
+func (s *PDFStore) maxSize() int64 {
+	return int64(s.cfg.PDFMaxSizeMB) << 20
+}
+
+// objectKey 情报 PDF 在文件存储中的键
+// 早期记录的 pdf_path 只保存文件名，补上前缀后与当前键一致
+func objectKey(pdfPath string) string {
+	if strings.Contains(pdfPath, "/") {
+		return pdfPath
+	}
+	return pdfKeyPrefix + pdfPath
+}
+
+// CaptureAsync 在后台下载情报的 PDF 原文，同时进行的下载数受 PDFFetchConcurrency 限制
+func (s *PDFStore) CaptureAsync(intelligenceID uint, link string) {
+	go func() {
+		s.workers <- struct{}{}
+		defer func() { <-s.workers }()
+
+		if err := s.Capture(context.Background(), intelligenceID, link); err != nil {
+			zap.L().Warn("Failed to capture pdf",
+				zap.Uint("intelligence_id", intelligenceID), zap.String("url", link), zap.Error(err))
+		}
+	}()
+}
+
+// Capture 下载并保存情报的 PDF 原文，结果写回情报记录
+func (s *PDFStore) Capture(ctx context.Context, intelligenceID uint, link string) error {
+	key := pdfKeyPrefix + strconv.FormatUint(uint64(intelligenceID), 10) + ".pdf"
+	size, err := s.download(ctx, link, key)
+	if err != nil {
+		s.updateStatus(intelligenceID, map[string]interface{}{
+			"pdf_status": PDFStatusFailed,
+			"has_pdf":    false,
+		})
+		return err
+	}
+
+	if err := s.updateStatus(intelligenceID, map[string]interface{}{
+		"pdf_status": PDFStatusStored,
+		"pdf_path":   key,
+		"pdf_size":   size,
+		"has_pdf":    true,
+	}); err != nil {
+		return err
+	}
+
+	// 下载期间情报已被删除时，删除刚写入的文件
+	var count int64
+	if err := s.db.Table("intelligences").Where("id = ?", intelligenceID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return s.files.Delete(ctx, key)
+	}
+	return nil
+}
+
+// ResumePending 重新排队服务重启前未完成的下载（pdf_status 为 pending），返回排队的情报数
+func (s *PDFStore) ResumePending() (int, error) {
+	var rows []struct {
+		ID     uint
+		PDFURL string
+	}
+	err := s.db.Table("intelligences").Select("id, pdf_url").
+		Where("pdf_status = ? AND pdf_url <> ''", PDFStatusPending).
+		Find(&rows).Error
+	if err != nil {
+		return 0, err
+	}
+	for _, row := range rows {
+		s.CaptureAsync(row.ID, row.PDFURL)
+	}
+	return len(rows), nil
+}
+
+// updateStatus 更新情报的 PDF 字段，不触发模型回调（这些字段不参与全文检索）
+func (s *PDFStore) updateStatus(intelligenceID uint, fields map[string]interface{}) error {
+	return s.db.Table("intelligences").Where("id = ?", intelligenceID).UpdateColumns(fields).Error
+}
+
+// download 下载 PDF 并写入文件存储，返回文件大小
+// 先写入本地临时文件，校验大小与类型通过后再上传，避免存储中留下不完整的文件
+func (s *PDFStore) download(ctx context.Context, link, key string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return 0, err
+	}
+	if err := safehttp.CheckURL(req.URL); err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/pdf")
+	req.Header.Set("User-Agent", "policy-backend/1.0 (+pdf fetcher)")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > s.maxSize() {
+		return 0, errPDFTooLarge
+	}
+
+	// 按内容判断类型，不信任响应头中的 Content-Type
+	head := make([]byte, pdfSniffLen)
+	n, err := io.ReadFull(resp.Body, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return 0, err
+	}
+	head = head[:n]
+	if http.DetectContentType(head) != "application/pdf" {
+		return 0, errNotPDF
+	}
+
+	tmp, err := os.CreateTemp("", "pdf-*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	// 多读一个字节用于判断是否超过上限
+	body := io.MultiReader(bytes.NewReader(head), resp.Body)
+	size, err := io.Copy(tmp, io.LimitReader(body, s.maxSize()+1))
+	if err != nil {
+		return 0, err
+	}
+	if size > s.maxSize() {
+		return 0, errPDFTooLarge
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	if err := s.files.Put(ctx, key, tmp, size, "application/pdf"); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// Remove 删除情报已保存的 PDF，情报没有 PDF 或文件已不存在时不报错
+func (s *PDFStore) Remove(ctx context.Context, intelligence *Intelligence) error {
+	if intelligence.PDFPath == "" {
+		return nil
+	}
+	return s.files.Delete(ctx, objectKey(intelligence.PDFPath))
+}
+
+// Open 打开情报已保存的 PDF，调用方负责关闭
+func (s *PDFStore) Open(ctx context.Context, intelligence *Intelligence) (storage.Object, error) {
+	if !intelligence.HasPDF || intelligence.PDFPath == "" {
+		return nil, ErrPDFNotFound
+	}
+	obj, err := s.files.Get(ctx, objectKey(intelligence.PDFPath))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrPDFNotFound
+	}
+	return obj, err
+}
@@ -0,0 +1,107 @@
+package intelligence
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Permission 资源访问控制记录（ACL）
+// 主体（用户或团队）对某个资源的权限，团队情报池即 subject_type=team 的情报
+type Permission struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	ResourceType string    `json:"resource_type" gorm:"type:varchar(20);not null;uniqueIndex:idx_permission"`
+	ResourceID   uint      `json:"resource_id" gorm:"not null;uniqueIndex:idx_permission"`
+	SubjectType  string    `json:"subject_type" gorm:"type:varchar(20);not null;uniqueIndex:idx_permission;index:idx_permission_subject"`
+	SubjectID    uint      `json:"subject_id" gorm:"not null;uniqueIndex:idx_permission;index:idx_permission_subject"`
+	Action       string    `json:"action" gorm:"type:varchar(20);not null"` // view, edit, admin
+	GrantedBy    uint      `json:"granted_by" gorm:"comment:授权人ID"`
+	GrantedAt    time.Time `json:"granted_at" gorm:"autoCreateTime"`
+}
+
+// 资源类型
+const (
+	ResourceTypeIntelligence = "intelligence"
+	ResourceTypeReport       = "report"     // 综述报告，共享到团队时写入团队的查看权限
+	ResourceTypeCollection   = "collection" // 收藏夹，共享到团队时写入团队的查看权限
+)
+
+// 主体类型
+const (
+	SubjectTypeUser = "user"
+	SubjectTypeTeam = "team"
+)
+
+// 权限级别
+const (
+	PermissionView  = "view"  // 查看
+	PermissionEdit  = "edit"  // 编辑
+	PermissionAdmin = "admin" // 完全控制
+)
+
+// TableName 指定表名
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+// permissionCallbackDelete 删除情报后清理其权限记录的回调名称
+const permissionCallbackDelete = "permissions:delete_intelligence"
+
+// registerPermissionCallbacks 注册 GORM 回调：删除情报后删除其权限记录，团队情报池随之移除
+func registerPermissionCallbacks(db *gorm.DB) error {
+	return registerDeleteCleanup(db, permissionCallbackDelete, func(db *gorm.DB, ids []uint) error {
+		return db.Where("resource_type = ? AND resource_id IN ?", ResourceTypeIntelligence, ids).
+			Delete(&Permission{}).Error
+	})
+}
+
+// GrantTeamOwnership 为团队情报写入归属记录：团队成员可查看，导入者拥有完全控制
+// 已存在的授权保持不变，可在事务中调用
+func GrantTeamOwnership(tx *gorm.DB, intelligenceID, teamID, userID uint) error {
+	permissions := []Permission{
+		{
+			ResourceType: ResourceTypeIntelligence,
+			ResourceID:   intelligenceID,
+			SubjectType:  SubjectTypeTeam,
+			SubjectID:    teamID,
+			Action:       PermissionView,
+			GrantedBy:    userID,
+		},
+		{
+			ResourceType: ResourceTypeIntelligence,
+			ResourceID:   intelligenceID,
+			SubjectType:  SubjectTypeUser,
+			SubjectID:    userID,
+			Action:       PermissionAdmin,
+			GrantedBy:    userID,
+		},
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&permissions).Error
+}
+
+// ApplyLibraryScope 按检索范围限定用户可见的情报，库内检索与导出共用
+// all: 我入库的 + 我所在团队的 + 分享给我的
+// mine: 仅我入库的
+// team: 仅我所在团队的（可指定某个团队）
+func ApplyLibraryScope(db *gorm.DB, userID uint, scope string, teamID uint) *gorm.DB {
+	myTeams := db.Session(&gorm.Session{NewDB: true}).
+		Table("team_members").Select("team_id").Where("user_id = ?", userID)
+
+	switch scope {
+	case "mine":
+		return db.Where("intelligences.user_id = ?", userID)
+	case "team":
+		db = db.Where("intelligences.team_id IN (?)", myTeams)
+		if teamID != 0 {
+			db = db.Where("intelligences.team_id = ?", teamID)
+		}
+		return db
+	default:
+		sharedToMe := db.Session(&gorm.Session{NewDB: true}).
+			Table("intelligence_shared").Select("intelligence_id").
+			Where("target_user_id = ? AND deleted_at IS NULL", userID)
+		return db.Where("intelligences.user_id = ? OR intelligences.team_id IN (?) OR intelligences.id IN (?)",
+			userID, myTeams, sharedToMe)
+	}
+}
@@ -0,0 +1,210 @@
+package intelligence
+
+import (
+	"strconv"
+	"strings"
+
+	"policy-backend/query"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// QueryFilter 检索表达式编译后的查询条件
+// 布尔条件作用于 intelligences 表；检索词另外生成相关度子查询，以 LEFT JOIN 方式参与排序
+type QueryFilter struct {
+	where *clause.Expr
+	rank  *gorm.DB
+	terms []string
+}
+
+// CompileQuery 解析检索表达式并编译为情报查询条件
+// 能整体交给全文索引的纯文本子表达式编译为全文检索语法，其余部分编译为普通 SQL 条件；
+// 表达式有误时返回 *query.Error
+func CompileQuery(db *gorm.DB, index FullTextIndex, input string) (*QueryFilter, error) {
+	node, err := query.Parse(input)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &QueryFilter{}
+	if node == nil {
+		return f, nil
+	}
+
+	where, err := compileNode(index, node)
+	if err != nil {
+		return nil, err
+	}
+	f.where = &where
+
+	f.terms = query.Terms(node)
+	if len(f.terms) > 0 {
+		f.rank = index.Rank(db, f.terms)
+	}
+	return f, nil
+}
+
+// Apply 将条件附加到以 intelligences 为主表的查询上
+func (f *QueryFilter) Apply(db *gorm.DB) *gorm.DB {
+	if f.where != nil {
+		db = db.Where(*f.where)
+	}
+	if f.rank != nil {
+		db = db.Joins("LEFT JOIN (?) AS ft ON ft.id = intelligences.id", f.rank)
+	}
+	return db
+}
+
+// HasRank 是否有检索词参与相关度排序
+func (f *QueryFilter) HasRank() bool {
+	return f.rank != nil
+}
+
+// ScoreColumn 相关度列表达式，须在 Apply 之后使用
+func (f *QueryFilter) ScoreColumn() string {
+	if f.rank == nil {
+		return "0"
+	}
+	return "COALESCE(ft.score, 0)"
+}
+
+// HighlightQuery 用于生成高亮片段的检索词
+func (f *QueryFilter) HighlightQuery() string {
+	return strings.Join(f.terms, " ")
+}
+
+// compileNode 递归编译语法树节点
+func compileNode(index FullTextIndex, node query.Node) (clause.Expr, error) {
+	switch n := node.(type) {
+	case *query.Term:
+		if expr, ok := index.condition(n); ok {
+			return expr, nil
+		}
+		return likeTermCondition(n.Text), nil
+	case *query.And:
+		if expr, ok := index.condition(n); ok {
+			return expr, nil
+		}
+		return joinNodes(index, n.Nodes, " AND ")
+	case *query.Or:
+		if expr, ok := index.condition(n); ok {
+			return expr, nil
+		}
+		return joinNodes(index, n.Nodes, " OR ")
+	case *query.Not:
+		inner, err := compileNode(index, n.Node)
+		if err != nil {
+			return clause.Expr{}, err
+		}
+		return clause.Expr{SQL: "NOT (" + inner.SQL + ")", Vars: inner.Vars}, nil
+	case *query.Field:
+		return fieldCondition(n)
+	}
+	return clause.Expr{SQL: "1 = 1"}, nil
+}
+
+// joinNodes 以 AND / OR 连接子节点条件
+func joinNodes(index FullTextIndex, nodes []query.Node, sep string) (clause.Expr, error) {
+	parts := make([]string, 0, len(nodes))
+	vars := []interface{}{}
+	for _, child := range nodes {
+		expr, err := compileNode(index, child)
+		if err != nil {
+			return clause.Expr{}, err
+		}
+		parts = append(parts, "("+expr.SQL+")")
+		vars = append(vars, expr.Vars...)
+	}
+	return clause.Expr{SQL: strings.Join(parts, sep), Vars: vars}, nil
+}
+
+// likeTermCondition 检索词在任一文本字段中出现
+func likeTermCondition(term string) clause.Expr {
+	kw := "%" + term + "%"
+	return clause.Expr{
+		SQL:  "intelligences.title LIKE ? OR intelligences.summary LIKE ? OR intelligences.keywords LIKE ? OR intelligences.content LIKE ?",
+		Vars: []interface{}{kw, kw, kw, kw},
+	}
+}
+
+// textFieldColumns 文本字段对应的列
+var textFieldColumns = map[string]string{
+	query.FieldTitle:    "intelligences.title",
+	query.FieldSummary:  "intelligences.summary",
+	query.FieldContent:  "intelligences.content",
+	query.FieldKeywords: "intelligences.keywords",
+	query.FieldSource:   "intelligences.source",
+	query.FieldURL:      "intelligences.url",
+}
+
+// fieldCondition 编译字段条件
+func fieldCondition(f *query.Field) (clause.Expr, error) {
+	if column, ok := textFieldColumns[f.Name]; ok {
+		return clause.Expr{SQL: column + " LIKE ?", Vars: []interface{}{"%" + f.Value + "%"}}, nil
+	}
+
+	switch f.Name {
+	case query.FieldAgency:
+		return agencyCondition(f.Value), nil
+	case query.FieldCountry:
+		countries := countrySubquery(f.Value)
+		return clause.Expr{
+			SQL: "intelligences.country_id IN (" + countries.SQL + ") OR intelligences.agency_id IN (" +
+				"SELECT id FROM agencies WHERE deleted_at IS NULL AND country_id IN (" + countries.SQL + "))",
+			Vars: append(append([]interface{}{}, countries.Vars...), countries.Vars...),
+		}, nil
+	case query.FieldDate:
+		return dateCondition(f)
+	}
+	return clause.Expr{}, &query.Error{Position: f.Offset, Message: "unsupported field " + strconv.Quote(f.Name)}
+}
+
+// agencyCondition 机构条件：数字按机构ID匹配，否则匹配机构名称（包含）、别名（相等）或所属国家代码
+// 例如 agency:NSF 命中 "美国国家科学基金会 (NSF)"，agency:US 命中全部美国机构
+func agencyCondition(value string) clause.Expr {
+	if id, err := strconv.ParseUint(value, 10, 64); err == nil {
+		return clause.Expr{SQL: "intelligences.agency_id = ?", Vars: []interface{}{id}}
+	}
+
+	alias := strings.ToLower(value)
+	return clause.Expr{
+		SQL: "intelligences.agency_id IN (SELECT id FROM agencies WHERE deleted_at IS NULL AND (" +
+			"name LIKE ? OR LOWER(aliases) = ? OR LOWER(aliases) LIKE ? OR LOWER(aliases) LIKE ? OR LOWER(aliases) LIKE ? " +
+			"OR country_id IN (SELECT id FROM countries WHERE deleted_at IS NULL AND code = ?)))",
+		Vars: []interface{}{"%" + value + "%", alias, alias + ";%", "%;" + alias, "%;" + alias + ";%", strings.ToUpper(value)},
+	}
+}
+
+// countrySubquery 按国家ID、代码或名称查找国家，结果用于 IN (...)
+func countrySubquery(value string) clause.Expr {
+	if id, err := strconv.ParseUint(value, 10, 64); err == nil {
+		return clause.Expr{SQL: "?", Vars: []interface{}{id}}
+	}
+	return clause.Expr{
+		SQL:  "SELECT id FROM countries WHERE deleted_at IS NULL AND (code = ? OR name = ?)",
+		Vars: []interface{}{strings.ToUpper(value), value},
+	}
+}
+
+// dateCondition 发布日期条件，取值按年、月或日展开为区间
+func dateCondition(f *query.Field) (clause.Expr, error) {
+	from, to, err := f.DateRange()
+	if err != nil {
+		return clause.Expr{}, err
+	}
+
+	const column = "intelligences.publish_date"
+	switch f.Op {
+	case query.OpGt:
+		return clause.Expr{SQL: column + " >= ?", Vars: []interface{}{to}}, nil
+	case query.OpGte:
+		return clause.Expr{SQL: column + " >= ?", Vars: []interface{}{from}}, nil
+	case query.OpLt:
+		return clause.Expr{SQL: column + " < ?", Vars: []interface{}{from}}, nil
+	case query.OpLte:
+		return clause.Expr{SQL: column + " < ?", Vars: []interface{}{to}}, nil
+	default:
+		return clause.Expr{SQL: column + " >= ? AND " + column + " < ?", Vars: []interface{}{from, to}}, nil
+	}
+}
@@ -9,8 +9,43 @@ func RegisterRoutes(g *echo.Group, h *Handler) {
 	// 基础 CRUD
 	g.POST("", h.CreateIntelligence)
 	g.GET("", h.ListIntelligences)
+	g.GET("/export", h.ExportIntelligences)          // 批量导出（少量直接下载，大量转后台任务）
+	g.GET("/exports", h.ListExportJobs)              // 我的后台导出任务
+	g.GET("/exports/:id", h.GetExportJob)            // 导出任务状态
+	g.GET("/exports/:id/download", h.DownloadExport) // 下载导出文件
+
+	// 标签体系
+	g.GET("/tags", h.ListTags)         // 可见的标签（全局与所在团队）
+	g.POST("/tags", h.CreateTag)       // 创建标签
+	g.GET("/tags/cloud", h.TagCloud)   // 标签云
+	g.POST("/tags/merge", h.MergeTags) // 合并标签
+	g.PUT("/tags/:id", h.UpdateTag)    // 改名、移动、修改同义词
+	g.DELETE("/tags/:id", h.DeleteTag) // 删除标签
+
+	// 收藏夹
+	g.GET("/collections", h.ListCollections)                                    // 我的收藏夹或共享到团队的收藏夹
+	g.POST("/collections", h.CreateCollection)                                  // 新建收藏夹
+	g.GET("/collections/:id", h.GetCollection)                                  // 收藏夹详情与其中的情报
+	g.PUT("/collections/:id", h.UpdateCollection)                               // 修改名称与描述
+	g.DELETE("/collections/:id", h.DeleteCollection)                            // 删除收藏夹（默认收藏夹除外）
+	g.POST("/collections/:id/items", h.AddCollectionItems)                      // 加入情报
+	g.PUT("/collections/:id/items/order", h.ReorderCollection)                  // 调整顺序
+	g.PUT("/collections/:id/items/:intelligence_id", h.UpdateCollectionItem)    // 修改备注
+	g.DELETE("/collections/:id/items/:intelligence_id", h.RemoveCollectionItem) // 移出情报
+	g.POST("/collections/:id/share", h.ShareCollection)                         // 只读共享到团队
+	g.DELETE("/collections/:id/share/:team_id", h.UnshareCollection)            // 取消共享
+
 	g.GET("/:id", h.GetIntelligenceDetail)
 	g.DELETE("/:id", h.DeleteIntelligence)
+	g.GET("/:id/pdf", h.GetIntelligencePDF)                // 在线阅读 PDF 原文（支持 Range）
+	g.POST("/:id/keywords/extract", h.ReextractKeywords)   // 重新抽取关键词
+	g.GET("/:id/tags", h.ListIntelligenceTags)             // 情报的标签
+	g.POST("/:id/tags", h.AddIntelligenceTags)             // 添加标签
+	g.DELETE("/:id/tags/:tag_id", h.RemoveIntelligenceTag) // 移除标签
+
+	// 收藏
+	g.POST("/:id/favorite", h.FavoriteIntelligence)     // 收藏（默认放入默认收藏夹）
+	g.DELETE("/:id/favorite", h.UnfavoriteIntelligence) // 取消收藏（移出我的所有收藏夹）
 
 	// 评分
 	g.POST("/:id/rate", h.RateIntelligence)
@@ -0,0 +1,74 @@
+package intelligence
+
+import (
+	"strings"
+	"unicode"
+)
+
+// isCJK 判断是否为中日韩文字（这类文字之间没有空格分词）
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
+
+// SegmentText 将文本切分为全文索引使用的词元
+// 拉丁字母与数字按连续片段切分并转小写；
+// 中日韩文字按二元组（bigram）切分，单字片段保留单字。
+// 例如 "NSF 量子计算" -> ["nsf", "量子", "子计", "计算"]
+func SegmentText(text string) []string {
+	tokens := []string{}
+	var word []rune
+	var cjk []rune
+
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		switch {
+		case len(cjk) == 1:
+			tokens = append(tokens, string(cjk))
+		case len(cjk) > 1:
+			for i := 0; i+1 < len(cjk); i++ {
+				tokens = append(tokens, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+
+	return tokens
+}
+
+// QueryTerms 将用户输入的检索词按空白切分并去重（保持原顺序）
+func QueryTerms(query string) []string {
+	seen := make(map[string]bool)
+	terms := []string{}
+	for _, t := range strings.Fields(query) {
+		t = strings.ToLower(t)
+		if !seen[t] {
+			seen[t] = true
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
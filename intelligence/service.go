@@ -1,17 +1,56 @@
 package intelligence
 
 import (
+	"context"
 	"errors"
 
+	"go.uber.org/zap"
 	"gorm.io/gorm"
+
+	"policy-backend/keyword"
+	"policy-backend/storage"
 )
 
 type Service struct {
-	db *gorm.DB
+	db            *gorm.DB
+	index         FullTextIndex
+	pdf           *PDFStore
+	files         storage.Storage
+	keywords      *keyword.Extractor
+	cfg           Config
+	exportWorkers chan struct{}
 }
 
-func NewService(db *gorm.DB) *Service {
-	return &Service{db: db}
+// NewService 创建情报服务，files 用于保存后台导出的文件
+// keywords 为关键词抽取器，不为空时注册回调，创建情报时自动补充关键词
+func NewService(db *gorm.DB, pdf *PDFStore, files storage.Storage, keywords *keyword.Extractor, cfg *Config) *Service {
+	concurrency := cfg.ExportConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if keywords != nil {
+		if err := registerKeywordCallbacks(db, keywords); err != nil {
+			zap.L().Warn("Failed to register keyword callbacks", zap.Error(err))
+		}
+	}
+	if err := registerTagCallbacks(db); err != nil {
+		zap.L().Warn("Failed to register tag callbacks", zap.Error(err))
+	}
+	if err := registerCollectionCallbacks(db); err != nil {
+		zap.L().Warn("Failed to register collection callbacks", zap.Error(err))
+	}
+	if err := registerPermissionCallbacks(db); err != nil {
+		zap.L().Warn("Failed to register permission callbacks", zap.Error(err))
+	}
+	return &Service{
+		db:            db,
+		index:         DetectFullTextIndex(db),
+		pdf:           pdf,
+		files:         files,
+		keywords:      keywords,
+		cfg:           *cfg,
+		exportWorkers: make(chan struct{}, concurrency),
+	}
 }
 
 // CreateIntelligence 创建情报 (默认状态为 temporary)
@@ -20,14 +59,16 @@ func (s *Service) CreateIntelligence(intelligence *Intelligence) error {
 	return s.db.Create(intelligence).Error
 }
 
-// IntelligenceDetail 包含情报详情和评分
+// IntelligenceDetail 包含情报详情、评分、标签和收藏状态
 type IntelligenceDetail struct {
 	Intelligence
-	AvgRating float64 `json:"avg_rating"`
-	MyRating  int     `json:"my_rating"`
+	AvgRating float64  `json:"avg_rating"`
+	MyRating  int      `json:"my_rating"`
+	Tags      []TagRef `json:"tags"`
+	Favorited bool     `json:"favorited"`
 }
 
-// GetIntelligenceDetail 获取情报详情（包括平均分和当前用户的评分）
+// GetIntelligenceDetail 获取情报详情（包括平均分、当前用户的评分、可见的标签以及是否已收藏）
 func (s *Service) GetIntelligenceDetail(id uint, userID uint) (*IntelligenceDetail, error) {
 	var intelligence Intelligence
 	if err := s.db.First(&intelligence, id).Error; err != nil {
@@ -50,21 +91,66 @@ func (s *Service) GetIntelligenceDetail(id uint, userID uint) (*IntelligenceDeta
 		myScore = myRating.Score
 	}
 
+	tags, err := s.IntelligenceTags([]uint{id}, userID)
+	if err != nil {
+		return nil, err
+	}
+	if tags[id] == nil {
+		tags[id] = []TagRef{}
+	}
+	favorited, err := s.favoritedIDs(userID, []uint{id})
+	if err != nil {
+		return nil, err
+	}
+
 	return &IntelligenceDetail{
 		Intelligence: intelligence,
 		AvgRating:    avgResult.AvgScore,
 		MyRating:     myScore,
+		Tags:         tags[id],
+		Favorited:    favorited[id],
 	}, nil
 }
 
+// OpenPDF 打开情报已保存的 PDF 原文，调用方负责关闭
+// 情报不存在时返回 gorm.ErrRecordNotFound，没有 PDF 时返回 ErrPDFNotFound
+func (s *Service) OpenPDF(ctx context.Context, id uint) (*Intelligence, storage.Object, error) {
+	var intelligence Intelligence
+	if err := s.db.First(&intelligence, id).Error; err != nil {
+		return nil, nil, err
+	}
+
+	obj, err := s.pdf.Open(ctx, &intelligence)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &intelligence, obj, nil
+}
+
 // UpdateIntelligenceStatus 更新情报状态 (例如从临时变为正式)
 func (s *Service) UpdateIntelligenceStatus(id uint, status string) error {
 	return s.db.Model(&Intelligence{}).Where("id = ?", id).Update("status", status).Error
 }
 
-// DeleteIntelligence 删除情报
+// DeleteIntelligence 删除情报，同时删除已保存的 PDF 原文
 func (s *Service) DeleteIntelligence(id uint) error {
-	return s.db.Delete(&Intelligence{}, id).Error
+	var intelligence Intelligence
+	if err := s.db.Select("id", "pdf_path").First(&intelligence, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if err := s.db.Delete(&Intelligence{}, id).Error; err != nil {
+		return err
+	}
+
+	// 文件删除失败不影响情报删除，只记录日志
+	if err := s.pdf.Remove(context.Background(), &intelligence); err != nil {
+		zap.L().Warn("Failed to delete intelligence pdf",
+			zap.Uint("intelligence_id", id), zap.String("pdf_path", intelligence.PDFPath), zap.Error(err))
+	}
+	return nil
 }
 
 // RateIntelligence 对情报进行评分
@@ -149,31 +235,94 @@ func (s *Service) ShareIntelligence(req ShareRequest) error {
 	})
 }
 
-// ListIntelligences 获取情报列表，支持分页和关键词搜索
-func (s *Service) ListIntelligences(page, pageSize int, keyword string) ([]Intelligence, int64, error) {
-	var intelligences []Intelligence
-	var total int64
+// IntelligenceListItem 情报列表项，关键词检索时附带相关度和高亮片段
+type IntelligenceListItem struct {
+	Intelligence
+	Score     float64    `json:"score,omitempty" gorm:"->"`
+	Highlight *Highlight `json:"highlight,omitempty" gorm:"-"`
+	Tags      []TagRef   `json:"tags" gorm:"-"`
+	Favorited bool       `json:"favorited" gorm:"-"`
+}
 
-	db := s.db.Model(&Intelligence{})
+// ListIntelligences 获取情报列表，支持分页和布尔检索表达式（语法见 query 包）
+// 有检索词时按全文索引相关度排序，否则按入库时间倒序；表达式有误时返回 *query.Error
+// hasPDF 为 true 时只返回已保存 PDF 原文的情报，tagIDs 不为空时只返回带有全部这些标签（含下级标签）的情报；
+// 标签须为 userID 可见，否则返回 ErrTagNotFound。列表项带有该用户可见的标签与是否已收藏
+func (s *Service) ListIntelligences(page, pageSize int, keyword string, hasPDF bool, tagIDs []uint, userID uint) ([]IntelligenceListItem, int64, error) {
+	var items []IntelligenceListItem
+	var total int64
 
-	if keyword != "" {
-		db = db.Where("title LIKE ? OR summary LIKE ? OR keywords LIKE ?",
-			"%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%")
+	if err := s.CheckTagFilter(userID, tagIDs); err != nil {
+		return nil, 0, err
+	}
+	db, filter, err := s.filterIntelligences(keyword, hasPDF, tagIDs)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	if err := db.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
+	if filter.HasRank() {
+		db = db.Select("intelligences.*, " + filter.ScoreColumn() + " AS score").Order("score DESC")
+	} else {
+		db = db.Select("intelligences.*")
+	}
+
 	offset := (page - 1) * pageSize
-	err := db.Limit(pageSize).
+	err = db.Limit(pageSize).
 		Offset(offset).
-		Order("created_at desc").
-		Find(&intelligences).Error
+		Order("intelligences.created_at desc").
+		Find(&items).Error
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if filter.HasRank() {
+		for i := range items {
+			items[i].Highlight = BuildHighlight(items[i].Title, items[i].Summary, items[i].Content, filter.HighlightQuery())
+		}
+	}
 
+	ids := make([]uint, len(items))
+	for i := range items {
+		ids[i] = items[i].ID
+	}
+	tags, err := s.IntelligenceTags(ids, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	favorited, err := s.favoritedIDs(userID, ids)
 	if err != nil {
 		return nil, 0, err
 	}
+	for i := range items {
+		items[i].Tags = tags[items[i].ID]
+		if items[i].Tags == nil {
+			items[i].Tags = []TagRef{}
+		}
+		items[i].Favorited = favorited[items[i].ID]
+	}
 
-	return intelligences, total, nil
+	return items, total, nil
+}
+
+// filterIntelligences 按检索表达式、PDF 与标签条件构造情报查询，列表与导出共用；表达式有误时返回 *query.Error
+func (s *Service) filterIntelligences(keyword string, hasPDF bool, tagIDs []uint) (*gorm.DB, *QueryFilter, error) {
+	filter, err := CompileQuery(s.db, s.index, keyword)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db := filter.Apply(s.db.Model(&Intelligence{}))
+	if hasPDF {
+		db = db.Where("intelligences.has_pdf = ?", true)
+	}
+	db, err = s.applyTagFilter(db, tagIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, filter, nil
 }
@@ -0,0 +1,151 @@
+package intelligence
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// 近似重复检测参数
+const (
+	// SimilarMaxDistance 判定为近似重复的最大汉明距离（64 位指纹）
+	// 指纹分为 4 段，距离不超过 3 时至少有一段完全相同，可走索引召回
+	SimilarMaxDistance = 3
+	// simHashMinTokens 生成指纹所需的最少词元数，过短的文本指纹不稳定
+	simHashMinTokens = 8
+	// similarCandidateLimit 单次召回的候选记录上限
+	similarCandidateLimit = 200
+)
+
+// ContentFingerprint 内容指纹（SimHash），与 DataHash 配合使用：
+// DataHash 判断完全相同的来源，SimHash 判断改换 URL 或小幅修改标题后重新发布的同一文档
+type ContentFingerprint struct {
+	SimHash  string `json:"sim_hash,omitempty" gorm:"type:varchar(16);comment:内容SimHash指纹"`
+	SimBand0 uint16 `json:"-" gorm:"index;comment:指纹分段0"`
+	SimBand1 uint16 `json:"-" gorm:"index;comment:指纹分段1"`
+	SimBand2 uint16 `json:"-" gorm:"index;comment:指纹分段2"`
+	SimBand3 uint16 `json:"-" gorm:"index;comment:指纹分段3"`
+}
+
+// NewContentFingerprint 由若干文本片段（标题、正文等）生成内容指纹
+// 文本过短时返回空指纹
+func NewContentFingerprint(parts ...string) ContentFingerprint {
+	hash, ok := SimHash(strings.Join(parts, " "))
+	if !ok {
+		return ContentFingerprint{}
+	}
+	return fingerprintFromHash(hash)
+}
+
+// fingerprintFromHash 由 64 位指纹构造分段结构
+func fingerprintFromHash(hash uint64) ContentFingerprint {
+	return ContentFingerprint{
+		SimHash:  fmt.Sprintf("%016x", hash),
+		SimBand0: uint16(hash >> 48),
+		SimBand1: uint16(hash >> 32),
+		SimBand2: uint16(hash >> 16),
+		SimBand3: uint16(hash),
+	}
+}
+
+// IsEmpty 指纹是否为空
+func (f ContentFingerprint) IsEmpty() bool {
+	return f.SimHash == ""
+}
+
+// Hash 返回 64 位指纹值
+func (f ContentFingerprint) Hash() (uint64, bool) {
+	if f.SimHash == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(f.SimHash, 16, 64)
+	return v, err == nil
+}
+
+// SimHash 计算文本的 64 位 SimHash 指纹
+// 特征为 SegmentText 切分的词元（中文为二元组），权重为词频
+func SimHash(text string) (uint64, bool) {
+	tokens := SegmentText(text)
+	if len(tokens) < simHashMinTokens {
+		return 0, false
+	}
+
+	weights := make(map[string]int, len(tokens))
+	for _, t := range tokens {
+		weights[t]++
+	}
+
+	var v [64]int
+	for token, w := range weights {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(token))
+		sum := h.Sum64()
+		for i := 0; i < 64; i++ {
+			if sum&(1<<uint(i)) != 0 {
+				v[i] += w
+			} else {
+				v[i] -= w
+			}
+		}
+	}
+
+	var hash uint64
+	for i := 0; i < 64; i++ {
+		if v[i] > 0 {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash, true
+}
+
+// Similarity 根据汉明距离计算两个指纹的相似度（0~1）
+func Similarity(a, b uint64) float64 {
+	return 1 - float64(bits.OnesCount64(a^b))/64
+}
+
+// SimilarMatch 近似重复匹配结果
+type SimilarMatch struct {
+	IntelligenceID uint    `json:"intelligence_id"`
+	Title          string  `json:"title"`
+	Similarity     float64 `json:"similarity"`
+}
+
+// FindSimilar 在情报库中查找与指纹近似的情报，按相似度降序返回
+func FindSimilar(db *gorm.DB, fp ContentFingerprint) ([]SimilarMatch, error) {
+	target, ok := fp.Hash()
+	if !ok {
+		return nil, nil
+	}
+
+	var candidates []Intelligence
+	if err := db.Select("id, title, sim_hash").
+		Where("sim_band0 = ? OR sim_band1 = ? OR sim_band2 = ? OR sim_band3 = ?",
+			fp.SimBand0, fp.SimBand1, fp.SimBand2, fp.SimBand3).
+		Limit(similarCandidateLimit).
+		Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	matches := []SimilarMatch{}
+	for _, c := range candidates {
+		v, ok := c.ContentFingerprint.Hash()
+		if !ok || bits.OnesCount64(v^target) > SimilarMaxDistance {
+			continue
+		}
+		matches = append(matches, SimilarMatch{
+			IntelligenceID: c.ID,
+			Title:          c.Title,
+			Similarity:     Similarity(v, target),
+		})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Similarity > matches[j].Similarity
+	})
+	return matches, nil
+}
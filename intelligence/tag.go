@@ -0,0 +1,1011 @@
+package intelligence
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"policy-backend/user"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 标签错误
+var (
+	ErrTagNotFound      = errors.New("tag not found")
+	ErrTagConflict      = errors.New("tag name or synonym already exists")
+	ErrTagForbidden     = errors.New("no permission to manage tags in this scope")
+	ErrTagInvalidName   = errors.New("invalid tag name")
+	ErrTagInvalidParent = errors.New("invalid parent tag")
+	ErrTagInvalidMerge  = errors.New("invalid tag merge")
+)
+
+// teamRoleAdmin 团队管理员角色，与 team.RoleAdmin 相同（team 包依赖本包，不能反向引用）
+const teamRoleAdmin = "admin"
+
+// 标签回调名称
+const (
+	tagCallbackKeywords = "tags:sync_keywords"
+	tagCallbackDelete   = "tags:delete_links"
+)
+
+// normalizeTagName 规范标签名：去除首尾空白、合并连续空白，不能为空、过长或包含层级分隔符
+func normalizeTagName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || strings.Contains(name, TagPathSep) || utf8.RuneCountInString(name) > TagNameMaxLen {
+		return "", ErrTagInvalidName
+	}
+	return name, nil
+}
+
+// splitKeywords 拆分 Keywords 字段（逗号、分号、顿号分隔），去重并跳过不能作为标签名的词
+func splitKeywords(keywords string) []string {
+	fields := strings.FieldsFunc(keywords, func(r rune) bool {
+		return strings.ContainsRune(",，;；、", r)
+	})
+	seen := make(map[string]bool, len(fields))
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		name, err := normalizeTagName(f)
+		if err != nil || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// tagPathIDs 解析 Path 中的祖先ID（含自身）
+func tagPathIDs(path string) []uint {
+	var ids []uint
+	for _, part := range strings.Split(strings.Trim(path, "/"), "/") {
+		if id, err := strconv.ParseUint(part, 10, 32); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
+
+// tagScopes 用户可见的标签范围：全局（0）与所在的团队
+func (s *Service) tagScopes(userID uint) ([]uint, error) {
+	scopes := []uint{0}
+	if userID == 0 {
+		return scopes, nil
+	}
+	var teamIDs []uint
+	if err := s.db.Model(&user.TeamMember{}).Where("user_id = ?", userID).Pluck("team_id", &teamIDs).Error; err != nil {
+		return nil, err
+	}
+	return append(scopes, teamIDs...), nil
+}
+
+// canManageTags 能否维护某范围的标签体系（创建、改名、移动、合并、删除）
+// 全局标签由配置的标签管理员维护，团队标签由团队管理员维护
+func (s *Service) canManageTags(u *user.User, teamID uint) error {
+	if teamID == 0 {
+		for _, name := range strings.Split(s.cfg.TagAdmins, ",") {
+			if name = strings.TrimSpace(name); name != "" && name == u.Username {
+				return nil
+			}
+		}
+		return ErrTagForbidden
+	}
+	var count int64
+	err := s.db.Model(&user.TeamMember{}).
+		Where("team_id = ? AND user_id = ? AND role = ?", teamID, u.ID, teamRoleAdmin).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrTagForbidden
+	}
+	return nil
+}
+
+// visibleTag 按ID查找用户可见的标签，不存在或不可见时返回 ErrTagNotFound
+func visibleTag(tx *gorm.DB, id uint, scopes []uint) (*Tag, error) {
+	var tag Tag
+	err := tx.Where("id = ? AND team_id IN ?", id, scopes).First(&tag).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTagNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// tagNameTaken 范围内是否已有同名（不区分大小写）的标签或同义词，excludeTagID 的标签名及其同义词不计
+func tagNameTaken(tx *gorm.DB, teamID uint, name string, excludeTagID uint) (bool, error) {
+	var count int64
+	err := tx.Model(&Tag{}).
+		Where("team_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", teamID, name, excludeTagID).
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+	err = tx.Model(&TagSynonym{}).
+		Where("team_id = ? AND LOWER(name) = LOWER(?) AND tag_id <> ?", teamID, name, excludeTagID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// findTagByName 在范围内按标签名或同义词（不区分大小写）查找标签，没有时返回 nil
+func findTagByName(tx *gorm.DB, teamID uint, name string) (*Tag, error) {
+	var tags []Tag
+	if err := tx.Where("team_id = ? AND LOWER(name) = LOWER(?)", teamID, name).Limit(1).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	if len(tags) > 0 {
+		return &tags[0], nil
+	}
+	err := tx.Where("id IN (?)", tx.Model(&TagSynonym{}).
+		Select("tag_id").
+		Where("team_id = ? AND LOWER(name) = LOWER(?)", teamID, name)).
+		Limit(1).Find(&tags).Error
+	if err != nil || len(tags) == 0 {
+		return nil, err
+	}
+	return &tags[0], nil
+}
+
+// createTag 在 parent 下创建标签（parent 为空表示顶级），写入后补全 Path
+func createTag(tx *gorm.DB, tag *Tag, parent *Tag) error {
+	prefix := "/"
+	if parent != nil {
+		if parent.Depth() >= TagMaxDepth {
+			return ErrTagInvalidParent
+		}
+		tag.ParentID = &parent.ID
+		prefix = parent.Path
+	}
+	if err := tx.Create(tag).Error; err != nil {
+		return err
+	}
+	tag.Path = prefix + strconv.FormatUint(uint64(tag.ID), 10) + "/"
+	return tx.Model(tag).UpdateColumn("path", tag.Path).Error
+}
+
+// resolveTagName 按名称解析标签，不存在时创建；“领域 > 量子信息” 形式逐级解析，缺少的层级创建在上一级之下
+// 已存在的标签保持原有位置
+func resolveTagName(tx *gorm.DB, teamID uint, name string, userID uint) (*Tag, error) {
+	var parent *Tag
+	for _, part := range strings.Split(name, TagPathSep) {
+		part, err := normalizeTagName(part)
+		if err != nil {
+			return nil, err
+		}
+		tag, err := findTagByName(tx, teamID, part)
+		if err != nil {
+			return nil, err
+		}
+		if tag == nil {
+			tag = &Tag{TeamID: teamID, Name: part, CreatedBy: userID}
+			if err := createTag(tx, tag, parent); err != nil {
+				// 并发创建同名标签时唯一索引冲突，改用已创建的标签
+				existing, findErr := findTagByName(tx, teamID, part)
+				if findErr != nil || existing == nil {
+					return nil, err
+				}
+				tag = existing
+			}
+		}
+		parent = tag
+	}
+	return parent, nil
+}
+
+// setTagParent 将标签移到 parent 之下（parent 为空表示顶级），同步改写整个子树的 Path
+func setTagParent(tx *gorm.DB, tag *Tag, parent *Tag) error {
+	var parentID *uint
+	prefix := "/"
+	if parent != nil {
+		parentID = &parent.ID
+		prefix = parent.Path
+	}
+	oldPath := tag.Path
+	newPath := prefix + strconv.FormatUint(uint64(tag.ID), 10) + "/"
+	err := tx.Model(&Tag{}).Where("id = ?", tag.ID).
+		Updates(map[string]interface{}{"parent_id": parentID, "path": newPath}).Error
+	if err != nil {
+		return err
+	}
+	tag.ParentID, tag.Path = parentID, newPath
+
+	var descendants []Tag
+	if err := tx.Select("id, path").Where("path LIKE ? AND id <> ?", oldPath+"%", tag.ID).Find(&descendants).Error; err != nil {
+		return err
+	}
+	for _, d := range descendants {
+		path := newPath + strings.TrimPrefix(d.Path, oldPath)
+		if err := tx.Model(&Tag{}).Where("id = ?", d.ID).UpdateColumn("path", path).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subtreeHeight 以标签为根的子树层数（只有自身时为 1）
+func subtreeHeight(tx *gorm.DB, tag *Tag) (int, error) {
+	var paths []string
+	if err := tx.Model(&Tag{}).Where("path LIKE ?", tag.Path+"%").Pluck("path", &paths).Error; err != nil {
+		return 0, err
+	}
+	height := 1
+	for _, p := range paths {
+		if h := strings.Count(p, "/") - tag.Depth(); h > height {
+			height = h
+		}
+	}
+	return height, nil
+}
+
+// replaceSynonyms 整体替换标签的同义词，与范围内其他标签名或同义词冲突时返回 ErrTagConflict
+func replaceSynonyms(tx *gorm.DB, tag *Tag, names []string) error {
+	seen := map[string]bool{strings.ToLower(tag.Name): true}
+	var synonyms []TagSynonym
+	for _, n := range names {
+		name, err := normalizeTagName(n)
+		if err != nil {
+			return err
+		}
+		if seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		taken, err := tagNameTaken(tx, tag.TeamID, name, tag.ID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrTagConflict
+		}
+		synonyms = append(synonyms, TagSynonym{TagID: tag.ID, TeamID: tag.TeamID, Name: name})
+	}
+	if len(synonyms) > tagMaxSynonyms {
+		return ErrTagInvalidName
+	}
+	if err := tx.Where("tag_id = ?", tag.ID).Delete(&TagSynonym{}).Error; err != nil {
+		return err
+	}
+	if len(synonyms) == 0 {
+		return nil
+	}
+	return tx.Create(&synonyms).Error
+}
+
+// tagFullNames 标签的完整名称，如 “领域 > 量子信息”
+func (s *Service) tagFullNames(tags []Tag) (map[uint]string, error) {
+	names := make(map[uint]string, len(tags))
+	var missing []uint
+	for _, t := range tags {
+		names[t.ID] = t.Name
+	}
+	for _, t := range tags {
+		for _, id := range tagPathIDs(t.Path) {
+			if _, ok := names[id]; !ok {
+				missing = append(missing, id)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		var ancestors []Tag
+		if err := s.db.Select("id, name").Where("id IN ?", missing).Find(&ancestors).Error; err != nil {
+			return nil, err
+		}
+		for _, a := range ancestors {
+			names[a.ID] = a.Name
+		}
+	}
+
+	full := make(map[uint]string, len(tags))
+	for _, t := range tags {
+		ids := tagPathIDs(t.Path)
+		parts := make([]string, 0, len(ids))
+		for _, id := range ids {
+			if name, ok := names[id]; ok {
+				parts = append(parts, name)
+			}
+		}
+		if len(parts) == 0 {
+			parts = append(parts, t.Name)
+		}
+		full[t.ID] = strings.Join(parts, " "+TagPathSep+" ")
+	}
+	return full, nil
+}
+
+// tagInfos 补全标签的完整名称、同义词与使用次数，按范围与完整名称排序
+func (s *Service) tagInfos(tags []Tag) ([]TagInfo, error) {
+	infos := make([]TagInfo, 0, len(tags))
+	if len(tags) == 0 {
+		return infos, nil
+	}
+	ids := make([]uint, len(tags))
+	for i, t := range tags {
+		ids[i] = t.ID
+	}
+
+	fullNames, err := s.tagFullNames(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	var synonyms []TagSynonym
+	if err := s.db.Where("tag_id IN ?", ids).Order("name").Find(&synonyms).Error; err != nil {
+		return nil, err
+	}
+	synonymsByTag := make(map[uint][]string)
+	for _, syn := range synonyms {
+		synonymsByTag[syn.TagID] = append(synonymsByTag[syn.TagID], syn.Name)
+	}
+
+	var counts []struct {
+		TagID uint
+		Count int64
+	}
+	err = s.db.Model(&IntelligenceTag{}).
+		Select("tag_id, COUNT(*) AS count").
+		Where("tag_id IN ?", ids).
+		Group("tag_id").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	countByTag := make(map[uint]int64, len(counts))
+	for _, c := range counts {
+		countByTag[c.TagID] = c.Count
+	}
+
+	for _, t := range tags {
+		syns := synonymsByTag[t.ID]
+		if syns == nil {
+			syns = []string{}
+		}
+		infos = append(infos, TagInfo{Tag: t, FullName: fullNames[t.ID], Synonyms: syns, Count: countByTag[t.ID]})
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		if infos[i].TeamID != infos[j].TeamID {
+			return infos[i].TeamID < infos[j].TeamID
+		}
+		return infos[i].FullName < infos[j].FullName
+	})
+	return infos, nil
+}
+
+// tagInfo 单个标签的详细信息
+func (s *Service) tagInfo(tag *Tag) (*TagInfo, error) {
+	infos, err := s.tagInfos([]Tag{*tag})
+	if err != nil {
+		return nil, err
+	}
+	return &infos[0], nil
+}
+
+// ListTags 列出用户可见的标签，teamID 不为空时只列该范围（0 为全局）；q 按标签名或同义词模糊匹配
+func (s *Service) ListTags(userID uint, teamID *uint, q string) ([]TagInfo, error) {
+	scopes, err := s.tagScopes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if teamID != nil {
+		if !slices.Contains(scopes, *teamID) {
+			return nil, ErrTagForbidden
+		}
+		scopes = []uint{*teamID}
+	}
+
+	db := s.db.Where("team_id IN ?", scopes)
+	if q = strings.TrimSpace(q); q != "" {
+		like := "%" + strings.ToLower(q) + "%"
+		db = db.Where("LOWER(name) LIKE ? OR id IN (?)", like,
+			s.db.Model(&TagSynonym{}).Select("tag_id").Where("LOWER(name) LIKE ?", like))
+	}
+	var tags []Tag
+	if err := db.Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return s.tagInfos(tags)
+}
+
+// CreateTag 创建标签，需要该范围的标签维护权限
+func (s *Service) CreateTag(u *user.User, req *TagRequest) (*TagInfo, error) {
+	name, err := normalizeTagName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.canManageTags(u, req.TeamID); err != nil {
+		return nil, err
+	}
+
+	tag := &Tag{TeamID: req.TeamID, Name: name, CreatedBy: u.ID}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var parent *Tag
+		if req.ParentID != nil {
+			var p Tag
+			if err := tx.Where("id = ? AND team_id = ?", *req.ParentID, req.TeamID).First(&p).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrTagInvalidParent
+				}
+				return err
+			}
+			parent = &p
+		}
+		taken, err := tagNameTaken(tx, req.TeamID, name, 0)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrTagConflict
+		}
+		if err := createTag(tx, tag, parent); err != nil {
+			return err
+		}
+		return replaceSynonyms(tx, tag, req.Synonyms)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.tagInfo(tag)
+}
+
+// UpdateTag 修改标签：改名、移动位置、替换同义词，需要该范围的标签维护权限
+func (s *Service) UpdateTag(u *user.User, id uint, req *TagUpdateRequest) (*TagInfo, error) {
+	scopes, err := s.tagScopes(u.ID)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := visibleTag(s.db, id, scopes)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.canManageTags(u, tag.TeamID); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if req.Synonyms != nil {
+			if err := replaceSynonyms(tx, tag, *req.Synonyms); err != nil {
+				return err
+			}
+		}
+		if req.Name != nil {
+			if err := renameTag(tx, tag, *req.Name, req.KeepOldName); err != nil {
+				return err
+			}
+		}
+		if req.ParentID != nil {
+			return moveTag(tx, tag, *req.ParentID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.tagInfo(tag)
+}
+
+// renameTag 标签改名；新名称原为自身的同义词时移除该同义词，keepOld 为 true 时原名保留为同义词
+func renameTag(tx *gorm.DB, tag *Tag, newName string, keepOld bool) error {
+	name, err := normalizeTagName(newName)
+	if err != nil {
+		return err
+	}
+	if name == tag.Name {
+		return nil
+	}
+	taken, err := tagNameTaken(tx, tag.TeamID, name, tag.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrTagConflict
+	}
+	if err := tx.Where("tag_id = ? AND LOWER(name) = LOWER(?)", tag.ID, name).Delete(&TagSynonym{}).Error; err != nil {
+		return err
+	}
+	if keepOld && !strings.EqualFold(name, tag.Name) {
+		if err := tx.Create(&TagSynonym{TagID: tag.ID, TeamID: tag.TeamID, Name: tag.Name}).Error; err != nil {
+			return err
+		}
+	}
+	tag.Name = name
+	return tx.Model(tag).Update("name", name).Error
+}
+
+// moveTag 将标签连同子树移到 parentID 之下（0 为顶级）；新的上级必须在同一范围、不能是自身或下级，移动后不能超过最大层级
+func moveTag(tx *gorm.DB, tag *Tag, parentID uint) error {
+	var parent *Tag
+	parentDepth := 0
+	if parentID != 0 {
+		var p Tag
+		if err := tx.Where("id = ? AND team_id = ?", parentID, tag.TeamID).First(&p).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTagInvalidParent
+			}
+			return err
+		}
+		if strings.HasPrefix(p.Path, tag.Path) {
+			return ErrTagInvalidParent
+		}
+		parent, parentDepth = &p, p.Depth()
+	}
+	height, err := subtreeHeight(tx, tag)
+	if err != nil {
+		return err
+	}
+	if parentDepth+height > TagMaxDepth {
+		return ErrTagInvalidParent
+	}
+	return setTagParent(tx, tag, parent)
+}
+
+// DeleteTag 删除标签及其同义词与情报关联，下级标签移到被删除标签的上级之下
+func (s *Service) DeleteTag(u *user.User, id uint) error {
+	scopes, err := s.tagScopes(u.ID)
+	if err != nil {
+		return err
+	}
+	tag, err := visibleTag(s.db, id, scopes)
+	if err != nil {
+		return err
+	}
+	if err := s.canManageTags(u, tag.TeamID); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var parent *Tag
+		if tag.ParentID != nil {
+			var p Tag
+			if err := tx.First(&p, *tag.ParentID).Error; err != nil {
+				return err
+			}
+			parent = &p
+		}
+		var children []Tag
+		if err := tx.Where("parent_id = ?", tag.ID).Find(&children).Error; err != nil {
+			return err
+		}
+		for i := range children {
+			if err := setTagParent(tx, &children[i], parent); err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("tag_id = ?", tag.ID).Delete(&IntelligenceTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tag_id = ?", tag.ID).Delete(&TagSynonym{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Tag{}, tag.ID).Error
+	})
+}
+
+// MergeTags 将若干标签并入目标标签：情报关联转到目标（去重），被合并标签的名称与同义词成为目标的同义词，
+// 下级标签移到目标之下。只能合并同一范围的标签，目标不能位于被合并标签的子树中
+func (s *Service) MergeTags(u *user.User, req *TagMergeRequest) (*TagInfo, error) {
+	scopes, err := s.tagScopes(u.ID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := visibleTag(s.db, req.TargetID, scopes)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.canManageTags(u, target.TeamID); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		merged := make(map[uint]bool, len(req.SourceIDs))
+		for _, id := range req.SourceIDs {
+			if merged[id] {
+				continue
+			}
+			merged[id] = true
+			if err := mergeTag(tx, id, target); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.tagInfo(target)
+}
+
+// mergeTag 将单个标签并入目标标签
+func mergeTag(tx *gorm.DB, sourceID uint, target *Tag) error {
+	if sourceID == target.ID {
+		return ErrTagInvalidMerge
+	}
+	var source Tag
+	if err := tx.Where("id = ? AND team_id = ?", sourceID, target.TeamID).First(&source).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTagNotFound
+		}
+		return err
+	}
+	if strings.HasPrefix(target.Path, source.Path) {
+		return ErrTagInvalidMerge
+	}
+
+	var children []Tag
+	if err := tx.Where("parent_id = ?", source.ID).Find(&children).Error; err != nil {
+		return err
+	}
+	for i := range children {
+		height, err := subtreeHeight(tx, &children[i])
+		if err != nil {
+			return err
+		}
+		if target.Depth()+height > TagMaxDepth {
+			return ErrTagInvalidMerge
+		}
+		if err := setTagParent(tx, &children[i], target); err != nil {
+			return err
+		}
+	}
+
+	// 情报关联转到目标标签，已有目标标签的情报只保留原关联
+	err := tx.Exec(`INSERT INTO intelligence_tags (intelligence_id, tag_id, source, created_by, created_at)
+		SELECT intelligence_id, ?, source, created_by, created_at FROM intelligence_tags
+		WHERE tag_id = ? AND intelligence_id NOT IN (SELECT intelligence_id FROM intelligence_tags WHERE tag_id = ?)`,
+		target.ID, source.ID, target.ID).Error
+	if err != nil {
+		return err
+	}
+	if err := tx.Where("tag_id = ?", source.ID).Delete(&IntelligenceTag{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&TagSynonym{}).Where("tag_id = ?", source.ID).Update("tag_id", target.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("tag_id = ? AND LOWER(name) = LOWER(?)", target.ID, target.Name).Delete(&TagSynonym{}).Error; err != nil {
+		return err
+	}
+	if !strings.EqualFold(source.Name, target.Name) {
+		if err := tx.Create(&TagSynonym{TagID: target.ID, TeamID: target.TeamID, Name: source.Name}).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Delete(&Tag{}, source.ID).Error
+}
+
+// SetIntelligenceTags 为情报添加标签：TagIDs 须为用户可见的标签，Names 在 TeamID 范围内解析或创建（用户须为该团队成员）
+// 返回情报上当前用户可见的全部标签；情报不存在时返回 gorm.ErrRecordNotFound
+func (s *Service) SetIntelligenceTags(intelligenceID, userID uint, req *IntelligenceTagsRequest) ([]TagRef, error) {
+	if err := s.db.Select("id").First(&Intelligence{}, intelligenceID).Error; err != nil {
+		return nil, err
+	}
+	scopes, err := s.tagScopes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Names) > 0 && !slices.Contains(scopes, req.TeamID) {
+		return nil, ErrTagForbidden
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		for _, id := range req.TagIDs {
+			tag, err := visibleTag(tx, id, scopes)
+			if err != nil {
+				return err
+			}
+			ids = append(ids, tag.ID)
+		}
+		for _, name := range req.Names {
+			tag, err := resolveTagName(tx, req.TeamID, name, userID)
+			if err != nil {
+				return err
+			}
+			ids = append(ids, tag.ID)
+		}
+		slices.Sort(ids)
+		ids = slices.Compact(ids)
+
+		if req.Replace {
+			q := tx.Where("intelligence_id = ? AND tag_id IN (?)", intelligenceID,
+				tx.Model(&Tag{}).Select("id").Where("team_id IN ?", scopes))
+			if len(ids) > 0 {
+				q = q.Where("tag_id NOT IN ?", ids)
+			}
+			if err := q.Delete(&IntelligenceTag{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		links := make([]IntelligenceTag, len(ids))
+		for i, id := range ids {
+			links[i] = IntelligenceTag{IntelligenceID: intelligenceID, TagID: id, Source: TagSourceManual, CreatedBy: userID}
+		}
+		// 手工添加已由关键词生成的标签时改记为手工来源，之后重新抽取关键词不会将其移除
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "intelligence_id"}, {Name: "tag_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"source"}),
+		}).Create(&links).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.ListIntelligenceTags(intelligenceID, userID)
+}
+
+// RemoveIntelligenceTag 移除情报上的标签，标签须为用户可见
+func (s *Service) RemoveIntelligenceTag(intelligenceID, tagID, userID uint) error {
+	scopes, err := s.tagScopes(userID)
+	if err != nil {
+		return err
+	}
+	if _, err := visibleTag(s.db, tagID, scopes); err != nil {
+		return err
+	}
+	return s.db.Where("intelligence_id = ? AND tag_id = ?", intelligenceID, tagID).Delete(&IntelligenceTag{}).Error
+}
+
+// ListIntelligenceTags 情报上用户可见的标签；情报不存在时返回 gorm.ErrRecordNotFound
+func (s *Service) ListIntelligenceTags(intelligenceID, userID uint) ([]TagRef, error) {
+	if err := s.db.Select("id").First(&Intelligence{}, intelligenceID).Error; err != nil {
+		return nil, err
+	}
+	refs, err := s.IntelligenceTags([]uint{intelligenceID}, userID)
+	if err != nil {
+		return nil, err
+	}
+	if refs[intelligenceID] == nil {
+		return []TagRef{}, nil
+	}
+	return refs[intelligenceID], nil
+}
+
+// IntelligenceTags 批量取出情报上用户可见的标签，按情报ID分组
+func (s *Service) IntelligenceTags(intelligenceIDs []uint, userID uint) (map[uint][]TagRef, error) {
+	result := make(map[uint][]TagRef, len(intelligenceIDs))
+	if len(intelligenceIDs) == 0 {
+		return result, nil
+	}
+	scopes, err := s.tagScopes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		IntelligenceID uint
+		Source         string
+		Tag
+	}
+	err = s.db.Model(&IntelligenceTag{}).
+		Select("intelligence_tags.intelligence_id, intelligence_tags.source, tags.*").
+		Joins("JOIN tags ON tags.id = intelligence_tags.tag_id").
+		Where("intelligence_tags.intelligence_id IN ? AND tags.team_id IN ?", intelligenceIDs, scopes).
+		Order("tags.team_id, tags.name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]Tag, len(rows))
+	for i, r := range rows {
+		tags[i] = r.Tag
+	}
+	fullNames, err := s.tagFullNames(tags)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		result[r.IntelligenceID] = append(result[r.IntelligenceID], TagRef{
+			ID:       r.Tag.ID,
+			TeamID:   r.Tag.TeamID,
+			Name:     r.Tag.Name,
+			FullName: fullNames[r.Tag.ID],
+			Source:   r.Source,
+		})
+	}
+	return result, nil
+}
+
+// CheckTagFilter 检查按标签筛选时给出的标签均为用户可见，否则返回 ErrTagNotFound
+func (s *Service) CheckTagFilter(userID uint, tagIDs []uint) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+	scopes, err := s.tagScopes(userID)
+	if err != nil {
+		return err
+	}
+	for _, id := range tagIDs {
+		if _, err := visibleTag(s.db, id, scopes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyTagFilter 按标签筛选情报：每个标签都须命中（含其下级标签）
+func (s *Service) applyTagFilter(db *gorm.DB, tagIDs []uint) (*gorm.DB, error) {
+	for _, id := range tagIDs {
+		var tag Tag
+		if err := s.db.Select("id, path").First(&tag, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrTagNotFound
+			}
+			return nil, err
+		}
+		db = db.Where("intelligences.id IN (?)", s.db.Model(&IntelligenceTag{}).
+			Select("intelligence_tags.intelligence_id").
+			Joins("JOIN tags ON tags.id = intelligence_tags.tag_id").
+			Where("tags.path LIKE ?", tag.Path+"%"))
+	}
+	return db, nil
+}
+
+// TagCloud 标签云：用户可见标签按关联的情报数降序，teamID 不为空时只统计该范围
+func (s *Service) TagCloud(userID uint, teamID *uint, limit int) ([]TagCount, error) {
+	if limit < 1 {
+		limit = tagCloudDefault
+	}
+	if limit > tagCloudMax {
+		limit = tagCloudMax
+	}
+	scopes, err := s.tagScopes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if teamID != nil {
+		if !slices.Contains(scopes, *teamID) {
+			return nil, ErrTagForbidden
+		}
+		scopes = []uint{*teamID}
+	}
+
+	var rows []struct {
+		TagID uint
+		Count int64
+	}
+	err = s.db.Model(&IntelligenceTag{}).
+		Select("intelligence_tags.tag_id, COUNT(*) AS count").
+		Joins("JOIN tags ON tags.id = intelligence_tags.tag_id").
+		Where("tags.team_id IN ?", scopes).
+		Group("intelligence_tags.tag_id").
+		Order("count DESC, intelligence_tags.tag_id").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	cloud := make([]TagCount, 0, len(rows))
+	if len(rows) == 0 {
+		return cloud, nil
+	}
+
+	ids := make([]uint, len(rows))
+	for i, r := range rows {
+		ids[i] = r.TagID
+	}
+	var tags []Tag
+	if err := s.db.Where("id IN ?", ids).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]Tag, len(tags))
+	for _, t := range tags {
+		byID[t.ID] = t
+	}
+	fullNames, err := s.tagFullNames(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	maxCount := rows[0].Count
+	for _, r := range rows {
+		t := byID[r.TagID]
+		cloud = append(cloud, TagCount{
+			ID:       t.ID,
+			TeamID:   t.TeamID,
+			Name:     t.Name,
+			FullName: fullNames[t.ID],
+			Count:    r.Count,
+			Level:    tagCloudLevel(r.Count, maxCount),
+		})
+	}
+	return cloud, nil
+}
+
+// tagCloudLevel 按使用次数的对数分级，最常用的为最高级
+func tagCloudLevel(count, maxCount int64) int {
+	if maxCount <= 1 || count <= 1 {
+		return 1
+	}
+	return 1 + int(math.Floor(float64(tagCloudLevels-1)*math.Log(float64(count))/math.Log(float64(maxCount))))
+}
+
+// syncKeywordTags 将情报的 Keywords 同步为全局标签（来源为 keyword）：关键词按标签名或同义词匹配，不存在时创建顶级标签；
+// 已不在关键词中的 keyword 来源关联被移除，手工添加的关联不受影响
+func syncKeywordTags(tx *gorm.DB, intelligenceID uint, keywords string) error {
+	var ids []uint
+	for _, name := range splitKeywords(keywords) {
+		tag, err := resolveTagName(tx, 0, name, 0)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, tag.ID)
+	}
+
+	q := tx.Where("intelligence_id = ? AND source = ?", intelligenceID, TagSourceKeyword)
+	if len(ids) > 0 {
+		q = q.Where("tag_id NOT IN ?", ids)
+	}
+	if err := q.Delete(&IntelligenceTag{}).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	links := make([]IntelligenceTag, 0, len(ids))
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			links = append(links, IntelligenceTag{IntelligenceID: intelligenceID, TagID: id, Source: TagSourceKeyword})
+		}
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error
+}
+
+// registerTagCallbacks 注册 GORM 回调：创建情报后将关键词同步为标签，删除情报后清理其标签关联
+func registerTagCallbacks(db *gorm.DB) error {
+	if db.Callback().Create().Get(tagCallbackKeywords) != nil {
+		return nil
+	}
+	err := db.Callback().Create().After("gorm:create").Register(tagCallbackKeywords, func(tx *gorm.DB) {
+		if tx.Error != nil {
+			return
+		}
+		for _, it := range statementIntelligences(tx) {
+			if it.ID == 0 || it.Keywords == "" {
+				continue
+			}
+			if err := syncKeywordTags(tx.Session(&gorm.Session{NewDB: true}), it.ID, it.Keywords); err != nil {
+				_ = tx.AddError(err)
+				return
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return registerDeleteCleanup(db, tagCallbackDelete, func(db *gorm.DB, ids []uint) error {
+		return db.Where("intelligence_id IN ?", ids).Delete(&IntelligenceTag{}).Error
+	})
+}
+
+// MigrateKeywordsToTags 将已有情报的 Keywords 迁移为全局标签，标签表首次创建时由数据库迁移调用，返回处理的情报数
+func MigrateKeywordsToTags(db *gorm.DB) (int, error) {
+	migrated := 0
+	var batch []Intelligence
+	err := db.Model(&Intelligence{}).
+		Select("id, keywords").
+		Where("keywords IS NOT NULL AND keywords <> ''").
+		FindInBatches(&batch, keywordBatchSize, func(tx *gorm.DB, _ int) error {
+			for _, it := range batch {
+				if err := syncKeywordTags(db, it.ID, it.Keywords); err != nil {
+					return err
+				}
+				migrated++
+			}
+			return nil
+		}).Error
+	return migrated, err
+}
@@ -0,0 +1,237 @@
+package intelligence
+
+import (
+	"errors"
+	"net/http"
+	"policy-backend/user"
+	"policy-backend/utils"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// tagError 将标签相关错误转换为响应
+func tagError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrTagNotFound):
+		return utils.Error(c, http.StatusNotFound, "Tag not found")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return utils.Error(c, http.StatusNotFound, "Intelligence not found")
+	case errors.Is(err, ErrTagForbidden):
+		return utils.Error(c, http.StatusForbidden, "No permission to manage tags in this scope")
+	case errors.Is(err, ErrTagConflict):
+		return utils.Error(c, http.StatusConflict, "Tag name or synonym already exists")
+	case errors.Is(err, ErrTagInvalidName):
+		return utils.Error(c, http.StatusBadRequest, "Invalid tag name")
+	case errors.Is(err, ErrTagInvalidParent):
+		return utils.Error(c, http.StatusBadRequest, "Invalid parent tag")
+	case errors.Is(err, ErrTagInvalidMerge):
+		return utils.Error(c, http.StatusBadRequest, "Invalid tag merge")
+	}
+	zap.L().Error("Tag operation failed", zap.Error(err))
+	return utils.Error(c, http.StatusInternalServerError, "Tag operation failed")
+}
+
+// parseTeamScope 解析可选的 team_id 查询参数，0 表示全局标签
+func parseTeamScope(c echo.Context) (*uint, error) {
+	s := c.QueryParam("team_id")
+	if s == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	teamID := uint(id)
+	return &teamID, nil
+}
+
+// ListTags 列出可见的标签（全局与所在团队），可按 team_id 限定范围、按 q 搜索名称与同义词
+// GET /api/intelligence/tags
+func (h *Handler) ListTags(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	teamID, err := parseTeamScope(c)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid team_id")
+	}
+
+	tags, err := h.svc.ListTags(currentUser.ID, teamID, c.QueryParam("q"))
+	if err != nil {
+		return tagError(c, err)
+	}
+	return utils.Success(c, tags)
+}
+
+// CreateTag 创建标签
+// POST /api/intelligence/tags
+func (h *Handler) CreateTag(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	var req TagRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	tag, err := h.svc.CreateTag(currentUser, &req)
+	if err != nil {
+		return tagError(c, err)
+	}
+	return utils.Success(c, tag)
+}
+
+// UpdateTag 修改标签：改名（可保留原名为同义词）、移动到其他上级、替换同义词
+// PUT /api/intelligence/tags/:id
+func (h *Handler) UpdateTag(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
+	}
+	var req TagUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	tag, err := h.svc.UpdateTag(currentUser, uint(id), &req)
+	if err != nil {
+		return tagError(c, err)
+	}
+	return utils.Success(c, tag)
+}
+
+// DeleteTag 删除标签，下级标签移到其上级之下
+// DELETE /api/intelligence/tags/:id
+func (h *Handler) DeleteTag(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
+	}
+
+	if err := h.svc.DeleteTag(currentUser, uint(id)); err != nil {
+		return tagError(c, err)
+	}
+	return utils.Success(c, nil)
+}
+
+// MergeTags 将若干标签合并到目标标签
+// POST /api/intelligence/tags/merge
+func (h *Handler) MergeTags(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	var req TagMergeRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid request body")
+	}
+	if req.TargetID == 0 || len(req.SourceIDs) == 0 {
+		return utils.Error(c, http.StatusBadRequest, "source_ids and target_id are required")
+	}
+
+	tag, err := h.svc.MergeTags(currentUser, &req)
+	if err != nil {
+		return tagError(c, err)
+	}
+	return utils.Success(c, tag)
+}
+
+// TagCloud 标签云：可见标签按使用次数降序，可按 team_id 限定范围，limit 默认 50
+// GET /api/intelligence/tags/cloud
+func (h *Handler) TagCloud(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	teamID, err := parseTeamScope(c)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid team_id")
+	}
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	cloud, err := h.svc.TagCloud(currentUser.ID, teamID, limit)
+	if err != nil {
+		return tagError(c, err)
+	}
+	return utils.Success(c, cloud)
+}
+
+// ListIntelligenceTags 情报上可见的标签
+// GET /api/intelligence/:id/tags
+func (h *Handler) ListIntelligenceTags(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
+	}
+
+	tags, err := h.svc.ListIntelligenceTags(uint(id), currentUser.ID)
+	if err != nil {
+		return tagError(c, err)
+	}
+	return utils.Success(c, tags)
+}
+
+// AddIntelligenceTags 为情报添加标签（按ID或名称），replace=true 时替换可见的全部标签
+// POST /api/intelligence/:id/tags
+func (h *Handler) AddIntelligenceTags(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
+	}
+	var req IntelligenceTagsRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid request body")
+	}
+	if len(req.TagIDs) == 0 && len(req.Names) == 0 && !req.Replace {
+		return utils.Error(c, http.StatusBadRequest, "tag_ids or names is required")
+	}
+
+	tags, err := h.svc.SetIntelligenceTags(uint(id), currentUser.ID, &req)
+	if err != nil {
+		return tagError(c, err)
+	}
+	return utils.Success(c, tags)
+}
+
+// RemoveIntelligenceTag 移除情报上的标签
+// DELETE /api/intelligence/:id/tags/:tag_id
+func (h *Handler) RemoveIntelligenceTag(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Error(c, http.StatusUnauthorized, "User not authenticated")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid ID")
+	}
+	tagID, err := strconv.ParseUint(c.Param("tag_id"), 10, 32)
+	if err != nil {
+		return utils.Error(c, http.StatusBadRequest, "Invalid tag ID")
+	}
+
+	if err := h.svc.RemoveIntelligenceTag(uint(id), uint(tagID), currentUser.ID); err != nil {
+		return tagError(c, err)
+	}
+	return utils.Success(c, nil)
+}
@@ -0,0 +1,133 @@
+package intelligence
+
+import (
+	"strings"
+	"time"
+)
+
+// Tag 标签：以父子关系组成分类体系，如 “领域 > 量子信息”
+// TeamID 为 0 的是全局标签，所有用户可见；否则只有该团队成员可见
+// 同一范围内标签名与同义词不区分大小写地唯一
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TeamID    uint      `json:"team_id" gorm:"not null;default:0;uniqueIndex:idx_tag_name"`
+	Name      string    `json:"name" gorm:"type:varchar(100);not null;uniqueIndex:idx_tag_name"`
+	ParentID  *uint     `json:"parent_id" gorm:"index"`
+	Path      string    `json:"-" gorm:"type:varchar(255);not null;default:'';index"` // 由根到自身的ID链，如 /1/5/，用于查询子树
+	CreatedBy uint      `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// Depth 标签所在层级，顶级标签为 1
+func (t *Tag) Depth() int {
+	return strings.Count(t.Path, "/") - 1
+}
+
+// TagSynonym 标签同义词：按名称打标签时同义词解析为对应的标签，合并标签时被合并标签的名称转为同义词
+type TagSynonym struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TagID     uint      `json:"tag_id" gorm:"not null;index"`
+	TeamID    uint      `json:"team_id" gorm:"not null;default:0;uniqueIndex:idx_tag_synonym"` // 与所属标签相同
+	Name      string    `json:"name" gorm:"type:varchar(100);not null;uniqueIndex:idx_tag_synonym"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (TagSynonym) TableName() string {
+	return "tag_synonyms"
+}
+
+// IntelligenceTag 情报与标签的关联
+type IntelligenceTag struct {
+	IntelligenceID uint      `json:"intelligence_id" gorm:"primaryKey;autoIncrement:false"`
+	TagID          uint      `json:"tag_id" gorm:"primaryKey;autoIncrement:false;index"`
+	Source         string    `json:"source" gorm:"type:varchar(20);not null;default:'manual'"` // manual: 手工添加, keyword: 由关键词生成
+	CreatedBy      uint      `json:"created_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (IntelligenceTag) TableName() string {
+	return "intelligence_tags"
+}
+
+// 标签关联来源
+const (
+	TagSourceManual  = "manual"
+	TagSourceKeyword = "keyword"
+)
+
+// 标签限制
+const (
+	TagMaxDepth     = 5   // 分类体系的最大层级
+	TagNameMaxLen   = 100 // 标签名与同义词的最大字数
+	TagPathSep      = ">" // 完整名称中的层级分隔符，按名称打标签时可用 “领域 > 量子信息” 指定层级
+	tagMaxSynonyms  = 50  // 单个标签的同义词上限
+	tagCloudDefault = 50  // 标签云默认返回的标签数
+	tagCloudMax     = 200 // 标签云最多返回的标签数
+	tagCloudLevels  = 5   // 标签云的字号等级数
+)
+
+// TagInfo 标签列表项：完整名称、同义词与使用次数
+type TagInfo struct {
+	Tag
+	FullName string   `json:"full_name"`
+	Synonyms []string `json:"synonyms"`
+	Count    int64    `json:"count"`
+}
+
+// TagRef 情报上的标签
+type TagRef struct {
+	ID       uint   `json:"id"`
+	TeamID   uint   `json:"team_id"`
+	Name     string `json:"name"`
+	FullName string `json:"full_name"`
+	Source   string `json:"source"`
+}
+
+// TagCount 标签云中的一项，Level 为按使用次数对数分级的字号等级（1-5）
+type TagCount struct {
+	ID       uint   `json:"id"`
+	TeamID   uint   `json:"team_id"`
+	Name     string `json:"name"`
+	FullName string `json:"full_name"`
+	Count    int64  `json:"count"`
+	Level    int    `json:"level"`
+}
+
+// TagRequest 创建标签请求
+type TagRequest struct {
+	Name     string   `json:"name"`
+	TeamID   uint     `json:"team_id"`   // 0 为全局标签
+	ParentID *uint    `json:"parent_id"` // 为空表示顶级标签
+	Synonyms []string `json:"synonyms"`
+}
+
+// TagUpdateRequest 修改标签请求，字段为空表示不修改
+type TagUpdateRequest struct {
+	Name        *string   `json:"name"`
+	KeepOldName bool      `json:"keep_old_name"` // 改名时将原名保留为同义词
+	ParentID    *uint     `json:"parent_id"`     // 0 表示移到顶级
+	Synonyms    *[]string `json:"synonyms"`      // 整体替换同义词
+}
+
+// TagMergeRequest 合并标签请求：将 SourceIDs 并入 TargetID
+type TagMergeRequest struct {
+	SourceIDs []uint `json:"source_ids"`
+	TargetID  uint   `json:"target_id"`
+}
+
+// IntelligenceTagsRequest 为情报打标签请求
+// Names 中的名称按标签名或同义词匹配，不存在时在 TeamID 范围内创建顶级标签（“领域 > 量子信息” 形式按层级创建）
+type IntelligenceTagsRequest struct {
+	TagIDs  []uint   `json:"tag_ids"`
+	Names   []string `json:"names"`
+	TeamID  uint     `json:"team_id"`
+	Replace bool     `json:"replace"` // 为 true 时移除当前用户可见、但不在本次列表中的标签
+}
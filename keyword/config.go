@@ -0,0 +1,51 @@
+package keyword
+
+import (
+	"fmt"
+	"os"
+)
+
+// Config 关键词抽取配置
+type Config struct {
+	MaxKeywords   int    `koanf:"keyword_max_keywords"` // 每条情报保留的关键词数
+	TitleWeight   int    `koanf:"keyword_title_weight"` // 标题中的词计算词频时的倍数
+	UserDictPath  string `koanf:"keyword_user_dict"`    // 自定义词典文件，格式同内置词典，为空表示只用内置词典
+	StopWordsPath string `koanf:"keyword_stop_words"`   // 自定义停用词文件，每行一个，与内置停用词合并
+}
+
+// DefaultConfig 返回关键词抽取的默认配置
+func DefaultConfig() Config {
+	return Config{
+		MaxKeywords: 8,
+		TitleWeight: 3,
+	}
+}
+
+// New 按配置创建关键词抽取器，加载内置词典与停用词，以及配置的自定义文件
+func New(cfg *Config) (*Extractor, error) {
+	dict := builtinDictionary()
+	if cfg.UserDictPath != "" {
+		f, err := os.Open(cfg.UserDictPath)
+		if err != nil {
+			return nil, fmt.Errorf("keyword: open user dictionary: %w", err)
+		}
+		defer f.Close()
+		if err := dict.Load(f); err != nil {
+			return nil, fmt.Errorf("keyword: load user dictionary: %w", err)
+		}
+	}
+
+	stop := builtinStopWords()
+	if cfg.StopWordsPath != "" {
+		f, err := os.Open(cfg.StopWordsPath)
+		if err != nil {
+			return nil, fmt.Errorf("keyword: open stop words: %w", err)
+		}
+		defer f.Close()
+		if err := stop.Load(f); err != nil {
+			return nil, fmt.Errorf("keyword: load stop words: %w", err)
+		}
+	}
+
+	return NewExtractor(NewSegmenter(dict), stop, cfg.MaxKeywords, cfg.TitleWeight), nil
+}
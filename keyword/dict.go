@@ -0,0 +1,121 @@
+package keyword
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// 内置词典与停用词表，随程序一起编译
+var (
+	//go:embed dict.txt
+	builtinDict string
+	//go:embed stopwords.txt
+	builtinStop string
+)
+
+// defaultWordFreq 自定义词典中未写词频的词使用的词频，足以让整词优先于拆开的常用词
+const defaultWordFreq = 2000
+
+// Dictionary 分词词典：词 → 词频
+type Dictionary struct {
+	freq   map[string]float64
+	total  float64
+	maxLen int // 最长词的字数，切分时只需向后查看这么多字
+}
+
+// NewDictionary 创建空词典
+func NewDictionary() *Dictionary {
+	return &Dictionary{freq: make(map[string]float64)}
+}
+
+// builtinDictionary 加载内置词典
+func builtinDictionary() *Dictionary {
+	d := NewDictionary()
+	if err := d.Load(strings.NewReader(builtinDict)); err != nil {
+		panic(fmt.Sprintf("keyword: invalid builtin dictionary: %v", err))
+	}
+	return d
+}
+
+// Load 读取词典文件：每行 “词 [词频]”，# 开头的行为注释；已有的词以新的词频覆盖
+func (d *Dictionary) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		freq := float64(defaultWordFreq)
+		if len(fields) > 1 {
+			f, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil || f <= 0 {
+				return fmt.Errorf("line %d: invalid frequency %q", line, fields[1])
+			}
+			freq = f
+		}
+		d.Add(fields[0], freq)
+	}
+	return scanner.Err()
+}
+
+// Add 添加词或修改词频
+func (d *Dictionary) Add(word string, freq float64) {
+	if old, ok := d.freq[word]; ok {
+		d.total -= old
+	}
+	d.freq[word] = freq
+	d.total += freq
+	if n := len([]rune(word)); n > d.maxLen {
+		d.maxLen = n
+	}
+}
+
+// Contains 词典中是否有该词
+func (d *Dictionary) Contains(word string) bool {
+	_, ok := d.freq[word]
+	return ok
+}
+
+// logProb 词的对数概率，未登录词按词频 1 计
+func (d *Dictionary) logProb(word string) float64 {
+	total := math.Max(d.total, 1)
+	if f, ok := d.freq[word]; ok {
+		return math.Log(f / total)
+	}
+	return math.Log(1 / total)
+}
+
+// StopWords 停用词表，英文按小写比较
+type StopWords map[string]bool
+
+// builtinStopWords 加载内置停用词
+func builtinStopWords() StopWords {
+	s := StopWords{}
+	_ = s.Load(strings.NewReader(builtinStop))
+	return s
+}
+
+// Load 读取停用词文件：每行一个，# 开头的行为注释
+func (s StopWords) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		s[strings.ToLower(word)] = true
+	}
+	return scanner.Err()
+}
+
+// Contains 是否为停用词
+func (s StopWords) Contains(word string) bool {
+	return s[strings.ToLower(word)]
+}
@@ -0,0 +1,246 @@
+// Package keyword 为情报抽取关键词：中文按内置词典切分，英文按单词切分，
+// 去除停用词后以本地情报库为语料计算 TF-IDF，取权重最高的若干词
+package keyword
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Keyword 抽取出的关键词
+type Keyword struct {
+	Term   string  `json:"term"`
+	Weight float64 `json:"weight"`
+}
+
+// Corpus 语料统计：文档数与每个词出现的文档数
+// 并发安全；Extractor 以它计算逆文档频率
+type Corpus struct {
+	mu   sync.RWMutex
+	docs int
+	df   map[string]int
+}
+
+// NewCorpus 创建空语料
+func NewCorpus() *Corpus {
+	return &Corpus{df: make(map[string]int)}
+}
+
+// Docs 文档数
+func (c *Corpus) Docs() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.docs
+}
+
+// Terms 不同词的数量
+func (c *Corpus) Terms() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.df)
+}
+
+// add 计入一篇文档的词（已去重）
+func (c *Corpus) add(terms map[string]int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.docs++
+	for term := range terms {
+		c.df[term]++
+	}
+}
+
+// idf 平滑的逆文档频率，语料为空时各词均为 1
+func (c *Corpus) idf(term string) float64 {
+	return math.Log(float64(c.docs+1)/float64(c.df[term]+1)) + 1
+}
+
+// Extractor 关键词抽取器
+type Extractor struct {
+	seg         *Segmenter
+	stop        StopWords
+	maxKeywords int
+	titleWeight int
+
+	mu     sync.RWMutex
+	corpus *Corpus
+}
+
+// NewExtractor 创建关键词抽取器，语料初始为空，可通过 Observe 逐篇计入或 SetCorpus 整体替换
+func NewExtractor(seg *Segmenter, stop StopWords, maxKeywords, titleWeight int) *Extractor {
+	if maxKeywords < 1 {
+		maxKeywords = DefaultConfig().MaxKeywords
+	}
+	if titleWeight < 1 {
+		titleWeight = 1
+	}
+	return &Extractor{
+		seg:         seg,
+		stop:        stop,
+		maxKeywords: maxKeywords,
+		titleWeight: titleWeight,
+		corpus:      NewCorpus(),
+	}
+}
+
+// Corpus 当前使用的语料
+func (e *Extractor) Corpus() *Corpus {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.corpus
+}
+
+// SetCorpus 替换语料，用于定期由情报库重建统计
+func (e *Extractor) SetCorpus(c *Corpus) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.corpus = c
+}
+
+// Observe 将一篇文档计入语料
+func (e *Extractor) Observe(title, body string) {
+	e.Corpus().add(e.termCounts(title, body).counts)
+}
+
+// ObserveInto 将一篇文档计入指定语料（重建语料时使用）
+func (e *Extractor) ObserveInto(c *Corpus, title, body string) {
+	c.add(e.termCounts(title, body).counts)
+}
+
+// Extract 抽取关键词，按权重降序；权重相同时先出现的在前
+func (e *Extractor) Extract(title, body string) []Keyword {
+	tc := e.termCounts(title, body)
+	if tc.total == 0 {
+		return []Keyword{}
+	}
+
+	corpus := e.Corpus()
+	corpus.mu.RLock()
+	keywords := make([]Keyword, 0, len(tc.counts))
+	for term, n := range tc.counts {
+		tf := float64(n) / float64(tc.total)
+		keywords = append(keywords, Keyword{Term: term, Weight: tf * corpus.idf(term)})
+	}
+	corpus.mu.RUnlock()
+
+	sort.Slice(keywords, func(i, j int) bool {
+		if keywords[i].Weight != keywords[j].Weight {
+			return keywords[i].Weight > keywords[j].Weight
+		}
+		return tc.first[keywords[i].Term] < tc.first[keywords[j].Term]
+	})
+	if len(keywords) > e.maxKeywords {
+		keywords = keywords[:e.maxKeywords]
+	}
+	for i := range keywords {
+		keywords[i].Weight = math.Round(keywords[i].Weight*10000) / 10000
+		keywords[i].Term = tc.display[keywords[i].Term]
+	}
+	return keywords
+}
+
+// Join 以逗号连接关键词，与情报 Keywords 字段的格式一致
+func Join(keywords []Keyword) string {
+	terms := make([]string, len(keywords))
+	for i, k := range keywords {
+		terms[i] = k.Term
+	}
+	return strings.Join(terms, ",")
+}
+
+// termCounts 文档中各候选词的统计
+type termCounts struct {
+	counts  map[string]int    // 词（英文为小写）→ 加权词频
+	first   map[string]int    // 首次出现的位置
+	display map[string]string // 输出时使用的写法（英文取首次出现的原文）
+	total   int
+}
+
+// termCounts 切分标题与正文并统计候选词，标题中的词按 titleWeight 倍计
+func (e *Extractor) termCounts(title, body string) termCounts {
+	tc := termCounts{
+		counts:  make(map[string]int),
+		first:   make(map[string]int),
+		display: make(map[string]string),
+	}
+	pos := 0
+	add := func(text string, weight int) {
+		for _, tok := range e.seg.Cut(text) {
+			term, ok := e.candidate(tok)
+			if !ok {
+				continue
+			}
+			if _, seen := tc.counts[term]; !seen {
+				tc.first[term] = pos
+				tc.display[term] = displayForm(tok)
+			}
+			tc.counts[term] += weight
+			tc.total += weight
+			pos++
+		}
+	}
+	add(title, e.titleWeight)
+	add(body, 1)
+	return tc
+}
+
+// candidate 判断切分出的词能否作为关键词，返回统计用的规范形式
+// 排除停用词、单个汉字、纯数字，以及过短的英文词（缩写如 AI、5G 除外）
+func (e *Extractor) candidate(tok Token) (string, bool) {
+	term := tok.Text
+	if tok.Latin {
+		term = strings.ToLower(strings.Trim(term, ".-'’"))
+		if isNumeric(term) {
+			return "", false
+		}
+		if utf8.RuneCountInString(term) < 3 && !isAcronym(tok.Text) {
+			return "", false
+		}
+	} else if utf8.RuneCountInString(term) < 2 {
+		return "", false
+	}
+	if e.stop.Contains(term) {
+		return "", false
+	}
+	return term, true
+}
+
+// displayForm 关键词的输出写法：缩写保持大写，其余英文词转小写
+func displayForm(tok Token) string {
+	if !tok.Latin {
+		return tok.Text
+	}
+	text := strings.Trim(tok.Text, ".-'’")
+	if isAcronym(text) {
+		return text
+	}
+	return strings.ToLower(text)
+}
+
+// isNumeric 是否只由数字及小数点、连字符组成（年份、编号等）
+func isNumeric(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) && r != '.' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// isAcronym 是否为缩写：含大写字母且没有小写字母，如 AI、NSF、5G、COVID-19
+func isAcronym(s string) bool {
+	upper := false
+	for _, r := range s {
+		if unicode.IsLower(r) {
+			return false
+		}
+		if unicode.IsUpper(r) {
+			upper = true
+		}
+	}
+	return upper
+}
@@ -0,0 +1,125 @@
+package keyword
+
+import (
+	"strings"
+	"unicode"
+)
+
+// 未登录词合并的长度范围（字数）
+const (
+	minUnknownWordLen = 2
+	maxUnknownWordLen = 4
+)
+
+// Token 切分出的词
+type Token struct {
+	Text  string // 原文，英文保持原有大小写
+	Latin bool   // 是否为字母数字组成的词
+}
+
+// Segmenter 中英文混合分词器
+// 中文按词典做最大概率切分（动态规划），未登录的连续单字合并为候选新词；
+// 字母与数字按连续片段切分，片段内部的连字符、点号（如 COVID-19、U.S.）保留
+type Segmenter struct {
+	dict *Dictionary
+}
+
+// NewSegmenter 创建分词器
+func NewSegmenter(dict *Dictionary) *Segmenter {
+	return &Segmenter{dict: dict}
+}
+
+// isHan 是否为汉字
+func isHan(r rune) bool {
+	return unicode.Is(unicode.Han, r)
+}
+
+// isWordRune 是否为英文词的组成字符
+func isWordRune(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !isHan(r)
+}
+
+// Cut 切分文本，标点与空白不产生词
+func (s *Segmenter) Cut(text string) []Token {
+	var tokens []Token
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case isHan(r):
+			j := i
+			for j < len(runes) && isHan(runes[j]) {
+				j++
+			}
+			for _, w := range s.cutHan(runes[i:j]) {
+				tokens = append(tokens, Token{Text: w})
+			}
+			i = j
+		case isWordRune(r):
+			j := i
+			for j < len(runes) {
+				if isWordRune(runes[j]) {
+					j++
+					continue
+				}
+				// 连字符、点号、撇号两侧都是字母数字时视为词的一部分
+				if strings.ContainsRune("-.'’", runes[j]) && j+1 < len(runes) && isWordRune(runes[j+1]) {
+					j++
+					continue
+				}
+				break
+			}
+			tokens = append(tokens, Token{Text: string(runes[i:j]), Latin: true})
+			i = j
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+// cutHan 切分一段连续的汉字
+func (s *Segmenter) cutHan(runes []rune) []string {
+	n := len(runes)
+	// best[i] 为 runes[i:] 的最大对数概率，next[i] 为该切分中第一个词的结束位置
+	best := make([]float64, n+1)
+	next := make([]int, n+1)
+	for i := n - 1; i >= 0; i-- {
+		best[i] = s.dict.logProb(string(runes[i])) + best[i+1]
+		next[i] = i + 1
+		for j := i + 2; j <= n && j-i <= s.dict.maxLen; j++ {
+			word := string(runes[i:j])
+			if !s.dict.Contains(word) {
+				continue
+			}
+			if p := s.dict.logProb(word) + best[j]; p > best[i] {
+				best[i] = p
+				next[i] = j
+			}
+		}
+	}
+
+	var words []string
+	var unknown []rune
+	flush := func() {
+		if len(unknown) >= minUnknownWordLen && len(unknown) <= maxUnknownWordLen {
+			words = append(words, string(unknown))
+		} else {
+			for _, r := range unknown {
+				words = append(words, string(r))
+			}
+		}
+		unknown = unknown[:0]
+	}
+	for i := 0; i < n; i = next[i] {
+		word := runes[i:next[i]]
+		if len(word) == 1 && !s.dict.Contains(string(word)) {
+			unknown = append(unknown, word[0])
+			continue
+		}
+		flush()
+		words = append(words, string(word))
+	}
+	flush()
+	return words
+}
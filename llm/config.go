@@ -0,0 +1,91 @@
+package llm
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// 服务类型
+const (
+	ProviderOpenAI = "openai" // 兼容 OpenAI 协议的服务（OpenAI、Azure OpenAI 兼容网关、DeepSeek、通义千问、vLLM 等）
+	ProviderMock   = "mock"   // 确定性 Mock，不发起网络请求
+)
+
+// TierConfig 单个档位的服务配置
+type TierConfig struct {
+	Provider       string // 服务类型：openai、mock；为空表示该档位不使用大模型
+	Endpoint       string // 服务地址，如 https://api.openai.com/v1
+	APIKey         string
+	Model          string // 对话模型
+	EmbeddingModel string // 向量化模型
+}
+
+// Config 大模型配置
+// 各档位对应配置项 llm_<tier>_provider、llm_<tier>_endpoint、llm_<tier>_api_key、llm_<tier>_model、llm_<tier>_embedding_model
+type Config struct {
+	Basic          TierConfig
+	Advanced       TierConfig
+	Pro            TierConfig
+	TimeoutSeconds int `koanf:"llm_timeout_seconds"` // 单次请求超时（秒）
+}
+
+// DefaultConfig 返回大模型的默认配置
+// 默认不启用任何档位，配置 provider 后生效
+func DefaultConfig() Config {
+	tier := func(model string) TierConfig {
+		return TierConfig{
+			Endpoint:       "https://api.openai.com/v1",
+			Model:          model,
+			EmbeddingModel: "text-embedding-3-small",
+		}
+	}
+	return Config{
+		Basic:          tier("gpt-4o-mini"),
+		Advanced:       tier("gpt-4o"),
+		Pro:            tier("gpt-4.1"),
+		TimeoutSeconds: 60,
+	}
+}
+
+// Tier 返回档位的配置
+func (c Config) Tier(tier string) TierConfig {
+	switch tier {
+	case TierAdvanced:
+		return c.Advanced
+	case TierPro:
+		return c.Pro
+	default:
+		return c.Basic
+	}
+}
+
+// New 按配置创建客户端
+func New(cfg *Config) (*Client, error) {
+	httpClient := &http.Client{Timeout: time.Duration(cfg.TimeoutSeconds) * time.Second}
+	providers := make(map[string]Provider)
+	for _, tier := range Tiers {
+		tc := cfg.Tier(tier)
+		switch tc.Provider {
+		case "":
+			continue
+		case ProviderOpenAI:
+			p, err := NewOpenAI(OpenAIOptions{
+				Endpoint:       tc.Endpoint,
+				APIKey:         tc.APIKey,
+				Model:          tc.Model,
+				EmbeddingModel: tc.EmbeddingModel,
+				Client:         httpClient,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("llm: tier %s: %w", tier, err)
+			}
+			providers[tier] = p
+		case ProviderMock:
+			providers[tier] = NewMock(tc.Model)
+		default:
+			return nil, fmt.Errorf("llm: tier %s: unknown provider %q", tier, tc.Provider)
+		}
+	}
+	return NewClient(providers), nil
+}
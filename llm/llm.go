@@ -0,0 +1,174 @@
+// Package llm 大模型调用抽象
+//
+// 业务代码通过 Client 按档位（basic / advanced / pro）调用对话补全与向量化接口，
+// 每个档位对应的服务地址、模型在配置中指定；Provider 的实现包括兼容 OpenAI 协议的 HTTP 客户端
+// 与用于测试的确定性 Mock。每次调用都会返回实际消耗的 token 数，由调用方记录或计费。
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// 模型档位，与检索接口的 model 参数一致
+const (
+	TierBasic    = "basic"
+	TierAdvanced = "advanced"
+	TierPro      = "pro"
+)
+
+// Tiers 全部档位，按能力由低到高排列
+var Tiers = []string{TierBasic, TierAdvanced, TierPro}
+
+// 消息角色
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+var (
+	// ErrUnknownTier 未知的模型档位
+	ErrUnknownTier = errors.New("llm: unknown tier")
+	// ErrNotConfigured 档位未配置服务（provider 为空）
+	ErrNotConfigured = errors.New("llm: tier not configured")
+)
+
+// Message 对话消息
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatRequest 对话补全请求
+type ChatRequest struct {
+	Messages    []Message
+	Temperature *float64 // 为 nil 时使用服务端默认值
+	MaxTokens   int      // 为 0 时不限制
+	JSON        bool     // 要求模型输出 JSON 对象
+}
+
+// ChatResponse 对话补全结果
+type ChatResponse struct {
+	Content string `json:"content"`
+	Model   string `json:"model"` // 实际使用的模型
+	Usage   Usage  `json:"usage"`
+}
+
+// EmbeddingRequest 向量化请求
+type EmbeddingRequest struct {
+	Inputs []string
+}
+
+// EmbeddingResponse 向量化结果，Vectors 与 Inputs 一一对应
+type EmbeddingResponse struct {
+	Vectors [][]float32 `json:"vectors"`
+	Model   string      `json:"model"`
+	Usage   Usage       `json:"usage"`
+}
+
+// Usage token 用量
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Add 累加用量
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+}
+
+// Provider 大模型服务
+type Provider interface {
+	// Name 服务名称，如 openai、mock
+	Name() string
+	// Chat 对话补全
+	Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error)
+	// Embed 文本向量化
+	Embed(ctx context.Context, req EmbeddingRequest) (*EmbeddingResponse, error)
+}
+
+// APIError 服务端返回的错误
+type APIError struct {
+	StatusCode int
+	Type       string
+	Message    string
+}
+
+// Error 实现 error 接口
+func (e *APIError) Error() string {
+	if e.Type != "" {
+		return fmt.Sprintf("llm: api error %d (%s): %s", e.StatusCode, e.Type, e.Message)
+	}
+	return fmt.Sprintf("llm: api error %d: %s", e.StatusCode, e.Message)
+}
+
+// Client 按档位分发请求的大模型客户端
+type Client struct {
+	providers map[string]Provider
+}
+
+// NewClient 使用给定的档位与服务映射创建客户端，未包含的档位视为未配置
+// 测试中可传入 MockProvider
+func NewClient(providers map[string]Provider) *Client {
+	c := &Client{providers: make(map[string]Provider, len(providers))}
+	for tier, p := range providers {
+		if p != nil {
+			c.providers[tier] = p
+		}
+	}
+	return c
+}
+
+// Provider 返回档位对应的服务，档位为空时使用 basic
+func (c *Client) Provider(tier string) (Provider, error) {
+	if tier == "" {
+		tier = TierBasic
+	}
+	if !isTier(tier) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTier, tier)
+	}
+	p, ok := c.providers[tier]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotConfigured, tier)
+	}
+	return p, nil
+}
+
+// Configured 档位是否已配置服务
+func (c *Client) Configured(tier string) bool {
+	_, err := c.Provider(tier)
+	return err == nil
+}
+
+// Chat 使用档位对应的服务进行对话补全
+func (c *Client) Chat(ctx context.Context, tier string, req ChatRequest) (*ChatResponse, error) {
+	p, err := c.Provider(tier)
+	if err != nil {
+		return nil, err
+	}
+	return p.Chat(ctx, req)
+}
+
+// Embed 使用档位对应的服务进行向量化
+func (c *Client) Embed(ctx context.Context, tier string, req EmbeddingRequest) (*EmbeddingResponse, error) {
+	p, err := c.Provider(tier)
+	if err != nil {
+		return nil, err
+	}
+	return p.Embed(ctx, req)
+}
+
+// isTier 是否为已知档位
+func isTier(tier string) bool {
+	for _, t := range Tiers {
+		if t == tier {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestClientTierRouting(t *testing.T) {
+	basic := NewMock("basic-model")
+	pro := NewMock("pro-model")
+	c := NewClient(map[string]Provider{TierBasic: basic, TierPro: pro, TierAdvanced: nil})
+
+	tests := []struct {
+		name      string
+		tier      string
+		wantModel string
+		wantErr   error
+	}{
+		{name: "empty tier uses basic", tier: "", wantModel: "basic-model"},
+		{name: "basic", tier: TierBasic, wantModel: "basic-model"},
+		{name: "pro", tier: TierPro, wantModel: "pro-model"},
+		{name: "nil provider is unconfigured", tier: TierAdvanced, wantErr: ErrNotConfigured},
+		{name: "unknown tier", tier: "ultra", wantErr: ErrUnknownTier},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := c.Chat(context.Background(), tt.tier, ChatRequest{
+				Messages: []Message{{Role: RoleUser, Content: "hello"}},
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Chat() error = %v, want %v", err, tt.wantErr)
+				}
+				if c.Configured(tt.tier) {
+					t.Errorf("Configured(%q) = true, want false", tt.tier)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Chat() error = %v", err)
+			}
+			if resp.Model != tt.wantModel {
+				t.Errorf("Chat() model = %s, want %s", resp.Model, tt.wantModel)
+			}
+			if !c.Configured(tt.tier) {
+				t.Errorf("Configured(%q) = false, want true", tt.tier)
+			}
+		})
+	}
+
+	if n := len(basic.Requests()); n != 2 {
+		t.Errorf("basic provider received %d requests, want 2", n)
+	}
+	if n := len(pro.Requests()); n != 1 {
+		t.Errorf("pro provider received %d requests, want 1", n)
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Advanced.Provider = ProviderMock
+	c, err := New(&cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if c.Configured(TierBasic) || !c.Configured(TierAdvanced) || c.Configured(TierPro) {
+		t.Errorf("configured tiers = basic:%v advanced:%v pro:%v, want only advanced",
+			c.Configured(TierBasic), c.Configured(TierAdvanced), c.Configured(TierPro))
+	}
+
+	cfg.Pro.Provider = "unknown"
+	if _, err := New(&cfg); err == nil {
+		t.Error("New() with unknown provider error = nil")
+	}
+}
+
+func TestUsageAdd(t *testing.T) {
+	var total Usage
+	total.Add(Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15})
+	total.Add(Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5})
+	total.Add(Usage{})
+	want := Usage{PromptTokens: 13, CompletionTokens: 7, TotalTokens: 20}
+	if total != want {
+		t.Errorf("Usage = %+v, want %+v", total, want)
+	}
+}
+
+func TestMockChat(t *testing.T) {
+	m := NewMock("")
+	ctx := context.Background()
+
+	resp, err := m.Chat(ctx, ChatRequest{Messages: []Message{
+		{Role: RoleSystem, Content: "system prompt"},
+		{Role: RoleUser, Content: "  quantum policy  "},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "[mock] quantum policy" || resp.Model != "mock" {
+		t.Errorf("Chat() = %+v", resp)
+	}
+	// "system prompt" 13 字符 → 4，"  quantum policy  " 18 字符 → 5，回复 21 字符 → 6
+	want := Usage{PromptTokens: 9, CompletionTokens: 6, TotalTokens: 15}
+	if resp.Usage != want {
+		t.Errorf("Usage = %+v, want %+v", resp.Usage, want)
+	}
+
+	resp, _ = m.Chat(ctx, ChatRequest{JSON: true, Messages: []Message{{Role: RoleUser, Content: "x"}}})
+	if resp.Content != "{}" {
+		t.Errorf("JSON reply = %q, want {}", resp.Content)
+	}
+
+	errBoom := errors.New("boom")
+	m.Reply = func(req ChatRequest) (string, error) { return "", errBoom }
+	if _, err := m.Chat(ctx, ChatRequest{}); !errors.Is(err, errBoom) {
+		t.Errorf("Chat() error = %v, want %v", err, errBoom)
+	}
+	if n := len(m.Requests()); n != 3 {
+		t.Errorf("Requests() = %d, want 3", n)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := m.Chat(cancelled, ChatRequest{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Chat() with cancelled context error = %v", err)
+	}
+}
+
+func TestMockEmbed(t *testing.T) {
+	m := NewMock("")
+	resp, err := m.Embed(context.Background(), EmbeddingRequest{Inputs: []string{"量子", "量子", "芯片"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Vectors) != 3 || resp.Usage.PromptTokens != 3 || resp.Usage.TotalTokens != 3 {
+		t.Fatalf("Embed() = %d vectors, usage %+v", len(resp.Vectors), resp.Usage)
+	}
+	for i, v := range resp.Vectors {
+		var norm float64
+		for _, x := range v {
+			norm += float64(x) * float64(x)
+		}
+		if len(v) != mockEmbeddingDims || math.Abs(norm-1) > 1e-5 {
+			t.Errorf("vector %d: dims = %d, norm = %f", i, len(v), norm)
+		}
+	}
+	for i := range resp.Vectors[0] {
+		if resp.Vectors[0][i] != resp.Vectors[1][i] {
+			t.Fatal("same input produced different vectors")
+		}
+	}
+}
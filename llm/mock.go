@@ -0,0 +1,142 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// mockEmbeddingDims Mock 向量的维度
+const mockEmbeddingDims = 16
+
+// MockProvider 确定性的 Mock 服务：相同输入总是得到相同输出，不发起网络请求
+// 默认回复为最后一条用户消息的摘录；设置 Reply 可自定义回复或模拟失败。
+// token 用量按字符数估算（约 4 个字符 1 个 token，至少 1），便于测试计费逻辑
+type MockProvider struct {
+	Model string
+	// Reply 自定义回复，为 nil 时使用默认回复
+	Reply func(req ChatRequest) (string, error)
+
+	mu       sync.Mutex
+	requests []ChatRequest
+}
+
+// NewMock 创建 Mock 服务
+func NewMock(model string) *MockProvider {
+	if model == "" {
+		model = "mock"
+	}
+	return &MockProvider{Model: model}
+}
+
+// Name 实现 Provider 接口
+func (m *MockProvider) Name() string {
+	return ProviderMock
+}
+
+// Requests 返回收到的对话请求，便于测试断言
+func (m *MockProvider) Requests() []ChatRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]ChatRequest(nil), m.requests...)
+}
+
+// Chat 实现 Provider 接口
+func (m *MockProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	m.requests = append(m.requests, req)
+	m.mu.Unlock()
+
+	var (
+		content string
+		err     error
+	)
+	if m.Reply != nil {
+		content, err = m.Reply(req)
+	} else {
+		content = defaultMockReply(req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	prompt := 0
+	for _, msg := range req.Messages {
+		prompt += mockTokens(msg.Content)
+	}
+	completion := mockTokens(content)
+	return &ChatResponse{
+		Content: content,
+		Model:   m.Model,
+		Usage:   Usage{PromptTokens: prompt, CompletionTokens: completion, TotalTokens: prompt + completion},
+	}, nil
+}
+
+// defaultMockReply 默认回复：JSON 模式返回空对象，否则返回最后一条用户消息的前 100 个字符
+func defaultMockReply(req ChatRequest) string {
+	if req.JSON {
+		return "{}"
+	}
+	last := ""
+	for _, msg := range req.Messages {
+		if msg.Role == RoleUser {
+			last = msg.Content
+		}
+	}
+	runes := []rune(strings.TrimSpace(last))
+	if len(runes) > 100 {
+		runes = runes[:100]
+	}
+	return fmt.Sprintf("[mock] %s", string(runes))
+}
+
+// Embed 实现 Provider 接口，向量由输入文本的 SHA-256 生成并归一化
+func (m *MockProvider) Embed(ctx context.Context, req EmbeddingRequest) (*EmbeddingResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	resp := &EmbeddingResponse{Model: m.Model, Vectors: make([][]float32, len(req.Inputs))}
+	for i, input := range req.Inputs {
+		resp.Vectors[i] = mockVector(input)
+		tokens := mockTokens(input)
+		resp.Usage.PromptTokens += tokens
+		resp.Usage.TotalTokens += tokens
+	}
+	return resp, nil
+}
+
+// mockVector 由文本哈希生成单位向量
+func mockVector(text string) []float32 {
+	sum := sha256.Sum256([]byte(text))
+	vec := make([]float32, mockEmbeddingDims)
+	var norm float64
+	for i := range vec {
+		v := float64(int16(binary.BigEndian.Uint16(sum[i*2:]))) / math.MaxInt16
+		vec[i] = float32(v)
+		norm += v * v
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for i := range vec {
+			vec[i] *= scale
+		}
+	}
+	return vec
+}
+
+// mockTokens 按字符数估算 token 数
+func mockTokens(s string) int {
+	n := utf8.RuneCountInString(s)
+	if n == 0 {
+		return 0
+	}
+	return (n + 3) / 4
+}
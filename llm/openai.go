@@ -0,0 +1,217 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	maxResponseSize    = 32 << 20 // 响应体读取上限
+	maxErrorMessageLen = 500      // 非 JSON 错误响应保留的最大字符数
+)
+
+// OpenAIOptions 兼容 OpenAI 协议的服务参数
+type OpenAIOptions struct {
+	Endpoint       string // 包含版本路径，如 https://api.openai.com/v1
+	APIKey         string
+	Model          string
+	EmbeddingModel string
+	Client         *http.Client
+}
+
+// OpenAIProvider 兼容 OpenAI 协议的服务，使用 /chat/completions 与 /embeddings 接口
+type OpenAIProvider struct {
+	endpoint       string
+	apiKey         string
+	model          string
+	embeddingModel string
+	client         *http.Client
+}
+
+// NewOpenAI 创建兼容 OpenAI 协议的服务
+func NewOpenAI(opts OpenAIOptions) (*OpenAIProvider, error) {
+	u, err := url.Parse(opts.Endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid endpoint %q", opts.Endpoint)
+	}
+	if opts.Model == "" {
+		return nil, errors.New("model is required")
+	}
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &OpenAIProvider{
+		endpoint:       strings.TrimRight(opts.Endpoint, "/"),
+		apiKey:         opts.APIKey,
+		model:          opts.Model,
+		embeddingModel: opts.EmbeddingModel,
+		client:         client,
+	}, nil
+}
+
+// Name 实现 Provider 接口
+func (p *OpenAIProvider) Name() string {
+	return ProviderOpenAI
+}
+
+// openAIUsage 响应中的用量
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// usage 转换为 Usage，部分兼容服务不返回 total_tokens
+func (u openAIUsage) usage() Usage {
+	total := u.TotalTokens
+	if total == 0 {
+		total = u.PromptTokens + u.CompletionTokens
+	}
+	return Usage{PromptTokens: u.PromptTokens, CompletionTokens: u.CompletionTokens, TotalTokens: total}
+}
+
+// Chat 实现 Provider 接口
+func (p *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	body := map[string]interface{}{
+		"model":    p.model,
+		"messages": req.Messages,
+	}
+	if req.Temperature != nil {
+		body["temperature"] = *req.Temperature
+	}
+	if req.MaxTokens > 0 {
+		body["max_tokens"] = req.MaxTokens
+	}
+	if req.JSON {
+		body["response_format"] = map[string]string{"type": "json_object"}
+	}
+
+	var resp struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Message Message `json:"message"`
+		} `json:"choices"`
+		Usage openAIUsage `json:"usage"`
+	}
+	if err := p.post(ctx, "/chat/completions", body, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, errors.New("llm: empty choices in response")
+	}
+
+	model := resp.Model
+	if model == "" {
+		model = p.model
+	}
+	return &ChatResponse{
+		Content: resp.Choices[0].Message.Content,
+		Model:   model,
+		Usage:   resp.Usage.usage(),
+	}, nil
+}
+
+// Embed 实现 Provider 接口
+func (p *OpenAIProvider) Embed(ctx context.Context, req EmbeddingRequest) (*EmbeddingResponse, error) {
+	if p.embeddingModel == "" {
+		return nil, errors.New("llm: embedding model not configured")
+	}
+	if len(req.Inputs) == 0 {
+		return &EmbeddingResponse{Model: p.embeddingModel}, nil
+	}
+
+	var resp struct {
+		Model string `json:"model"`
+		Data  []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+		Usage openAIUsage `json:"usage"`
+	}
+	body := map[string]interface{}{
+		"model": p.embeddingModel,
+		"input": req.Inputs,
+	}
+	if err := p.post(ctx, "/embeddings", body, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Data) != len(req.Inputs) {
+		return nil, fmt.Errorf("llm: expected %d embeddings, got %d", len(req.Inputs), len(resp.Data))
+	}
+
+	// 按 index 还原顺序
+	vectors := make([][]float32, len(req.Inputs))
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("llm: embedding index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+
+	model := resp.Model
+	if model == "" {
+		model = p.embeddingModel
+	}
+	return &EmbeddingResponse{Vectors: vectors, Model: model, Usage: resp.Usage.usage()}, nil
+}
+
+// post 发送 JSON 请求并解析响应，非 2xx 响应转换为 APIError
+func (p *OpenAIProvider) post(ctx context.Context, path string, body interface{}, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		var errResp struct {
+			Error struct {
+				Message string `json:"message"`
+				Type    string `json:"type"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(respBody, &errResp) == nil && errResp.Error.Message != "" {
+			apiErr.Type = errResp.Error.Type
+			apiErr.Message = errResp.Error.Message
+		} else {
+			apiErr.Message = truncate(strings.TrimSpace(string(respBody)), maxErrorMessageLen)
+		}
+		return apiErr
+	}
+	return json.Unmarshal(respBody, out)
+}
+
+// truncate 按字符截断
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
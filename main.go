@@ -1,11 +1,16 @@
 package main
 
 import (
+	"policy-backend/analysis"
 	"policy-backend/config"
 	"policy-backend/cron"
 	"policy-backend/database"
+	"policy-backend/intelligence"
+	"policy-backend/keyword"
+	"policy-backend/llm"
 	"policy-backend/router"
 	"policy-backend/search"
+	"policy-backend/storage"
 	"policy-backend/user"
 	"policy-backend/utils"
 
@@ -34,22 +39,75 @@ func main() {
 		zap.L().Fatal("Failed to auto migrate database", zap.Error(err))
 	}
 
+	// 初始化文件存储（本地目录或 S3 兼容对象存储）
+	files, err := storage.New(&cfg.Storage)
+	if err != nil {
+		zap.L().Fatal("Failed to initialize storage", zap.Error(err))
+	}
+	zap.L().Info("Storage initialized", zap.String("backend", files.Backend()))
+
+	// 初始化大模型客户端（按 basic / advanced / pro 档位配置）
+	llmClient, err := llm.New(&cfg.LLM)
+	if err != nil {
+		zap.L().Fatal("Failed to initialize llm client", zap.Error(err))
+	}
+
+	// 初始化关键词抽取器（内置词典与停用词，可追加自定义词典）
+	keywords, err := keyword.New(&cfg.Keyword)
+	if err != nil {
+		zap.L().Fatal("Failed to initialize keyword extractor", zap.Error(err))
+	}
+
 	// 初始化积分服务
 	pointsSvc := user.NewPointsTransactionService(database.DB)
 
-	// 创建搜索处理器（用于定时任务）
-	searchH := search.NewHandler(database.DB, pointsSvc)
+	// 情报 PDF 原文存储（搜索导入时抓取，情报模块负责读取）
+	pdfStore := intelligence.NewPDFStore(database.DB, &cfg.Intelligence, files)
+
+	// 创建搜索处理器（定时任务与 HTTP 路由共用，以共享检索任务队列与会话推送）
+	searchH := search.NewHandler(database.DB, pointsSvc, &cfg.Search, pdfStore, llmClient)
+
+	// 创建情报服务（定时任务与 HTTP 路由共用：清理过期的导出文件、重建关键词语料与回填关键词）
+	intelligenceSvc := intelligence.NewService(database.DB, pdfStore, files, keywords, &cfg.Intelligence)
+
+	// 重启前未完成的检索会话无法继续，标记为失败
+	if n, err := searchH.FailInterruptedSessions(); err != nil {
+		zap.L().Warn("Failed to mark interrupted search sessions", zap.Error(err))
+	} else if n > 0 {
+		zap.L().Info("Marked interrupted search sessions as failed", zap.Int64("count", n))
+	}
+
+	// 重启前未完成的综述报告同样标记为失败并退回积分
+	if n, err := analysis.FailInterruptedReports(database.DB, pointsSvc); err != nil {
+		zap.L().Warn("Failed to mark interrupted reports", zap.Error(err))
+	} else if n > 0 {
+		zap.L().Info("Marked interrupted reports as failed", zap.Int64("count", n))
+	}
+
+	// 重启前未完成的导出任务无法继续，标记为失败
+	if n, err := intelligenceSvc.FailInterruptedExports(); err != nil {
+		zap.L().Warn("Failed to mark interrupted export jobs", zap.Error(err))
+	} else if n > 0 {
+		zap.L().Info("Marked interrupted export jobs as failed", zap.Int64("count", n))
+	}
+
+	// 重启前未完成的 PDF 下载重新排队
+	if n, err := pdfStore.ResumePending(); err != nil {
+		zap.L().Warn("Failed to resume pending pdf captures", zap.Error(err))
+	} else if n > 0 {
+		zap.L().Info("Resumed pending pdf captures", zap.Int("count", n))
+	}
 
 	// 启动定时任务
-	cronJob := cron.NewCronJob(database.DB, searchH)
+	cronJob := cron.NewCronJob(database.DB, searchH, intelligenceSvc)
 	cronJob.Start()
 	defer cronJob.Stop()
 
 	// 创建Echo实例
 	e := echo.New()
 
-	// 注册路由（注入认证配置）
-	router.Init(e, database.DB, &cfg.Auth)
+	// 注册路由（注入认证与分析配置、与定时任务共用的搜索处理器和情报服务及大模型客户端）
+	router.Init(e, database.DB, &cfg.Auth, &cfg.Analysis, searchH, intelligenceSvc, llmClient)
 
 	// 启动服务器（使用服务器配置）
 	if err := e.Start(cfg.Server.ServerAddress); err != nil {
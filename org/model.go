@@ -25,6 +25,7 @@ type Agency struct {
 	CountryID uint    `json:"country_id" gorm:"not null;index"`
 	Country   Country `json:"country" gorm:"foreignKey:CountryID"`
 	Domain    string  `json:"domain" gorm:"size:300"`
+	Aliases   string  `json:"aliases" gorm:"size:500"` // 别名（英文名称、缩写等），以 ; 分隔，用于按来源名称匹配机构
 }
 
 // TableName 指定表名
@@ -153,6 +154,47 @@ func SeedData(db *gorm.DB) error {
 		{"EU", "欧盟委员会", "commission.europa.eu"},
 	}
 
+	// 机构别名：英文全称与常用缩写，用于按来源名称识别机构
+	// 共用域名的机构（如白宫各办公室）依赖别名区分
+	agencyAliases := map[string]string{
+		"美国国家科学技术委员会":              "National Science and Technology Council;NSTC",
+		"美国总统科技顾问委员会":              "President's Council of Advisors on Science and Technology;PCAST",
+		"美国白宫科技政策办公室":              "Office of Science and Technology Policy;OSTP;白宫科技政策办公室",
+		"美国国家情报委员会":                "National Intelligence Council;Office of the Director of National Intelligence;ODNI",
+		"美国能源部 (DOE)":              "Department of Energy;U.S. Department of Energy",
+		"美国国家科学基金会 (NSF)":          "National Science Foundation;U.S. National Science Foundation",
+		"美国国立卫生研究院 (NIH)":          "National Institutes of Health",
+		"美国国家科学院":                  "National Academy of Sciences;NAS",
+		"美国国家工程院":                  "National Academy of Engineering;NAE",
+		"美国国家医学院":                  "National Academy of Medicine;NAM",
+		"美国兰德公司":                   "RAND Corporation;RAND",
+		"美国布鲁金斯学会":                 "Brookings Institution;Brookings",
+		"美国新美国安全中心":                "Center for a New American Security;CNAS",
+		"美国战略与国际问题研究中心":            "Center for Strategic and International Studies;CSIS",
+		"美国大西洋理事会":                 "Atlantic Council",
+		"美国信息技术与创新基金会":             "Information Technology and Innovation Foundation;ITIF",
+		"英国研究与创新署":                 "UK Research and Innovation;UKRI",
+		"英国国家科学与技术委员会":             "Council for Science and Technology;CST",
+		"英国科学与技术战略办公室":             "Office for Science and Technology Strategy;OSTS",
+		"英国皇家学会":                   "The Royal Society;Royal Society",
+		"德国联邦教育与研究部":               "Bundesministerium für Bildung und Forschung;BMBF",
+		"德国研究联合会":                  "Deutsche Forschungsgemeinschaft;DFG",
+		"德国马普学会":                   "Max Planck Society;Max-Planck-Gesellschaft",
+		"德国弗朗霍夫协会":                 "Fraunhofer-Gesellschaft;Fraunhofer",
+		"法国国家科研署":                  "Agence nationale de la recherche;ANR",
+		"法国国家科研中心":                 "Centre national de la recherche scientifique;CNRS",
+		"日本文部科学省":                  "Ministry of Education, Culture, Sports, Science and Technology;MEXT",
+		"日本科学技术振兴机构研究开发战略中心(CRDS)": "Center for Research and Development Strategy",
+		"韩国科学技术信息通信部":              "Ministry of Science and ICT;MSIT",
+		"韩国研究基金会":                  "National Research Foundation of Korea;NRF",
+		"瑞士国家科学基金会":                "Swiss National Science Foundation;SNSF",
+		"澳大利亚研究理事会":                "Australian Research Council;ARC",
+		"澳大利亚联邦科学与工业研究组织":          "Commonwealth Scientific and Industrial Research Organisation;CSIRO",
+		"欧洲研究理事会":                  "European Research Council;ERC",
+		"欧洲创新理事会":                  "European Innovation Council;EIC",
+		"欧盟委员会":                    "European Commission",
+	}
+
 	// 4. 遍历并插入机构数据
 	for _, item := range agenciesData {
 		countryID, ok := countryMap[item.CountryCode]
@@ -165,10 +207,11 @@ func SeedData(db *gorm.DB) error {
 			Name:      item.Name,
 			CountryID: countryID,
 			Domain:    item.Domain,
+			Aliases:   agencyAliases[item.Name],
 		}
 
 		if err := db.Where(Agency{Name: agency.Name, CountryID: agency.CountryID}).
-			Attrs(Agency{Domain: agency.Domain}).
+			Attrs(Agency{Domain: agency.Domain, Aliases: agency.Aliases}).
 			FirstOrCreate(&agency).Error; err != nil {
 			return err
 		}
@@ -177,6 +220,11 @@ func SeedData(db *gorm.DB) error {
 		if item.Domain != "" && agency.Domain != item.Domain {
 			db.Model(&agency).Update("domain", item.Domain)
 		}
+
+		// 已有机构尚未设置别名时补全
+		if aliases := agencyAliases[item.Name]; aliases != "" && agency.Aliases == "" {
+			db.Model(&agency).Update("aliases", aliases)
+		}
 	}
 
 	return nil
@@ -0,0 +1,274 @@
+package org
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// 机构匹配方式
+const (
+	MatchByDomain = "domain" // 按 URL 主机名匹配
+	MatchByName   = "name"   // 按来源名称/别名匹配
+)
+
+// resolverCacheTTL 机构索引缓存时间
+// 机构与别名没有在线维护接口（由种子数据或直接改库维护），变更后最迟在此时间后生效
+const resolverCacheTTL = 5 * time.Minute
+
+// minAliasContainLength 名称参与包含匹配的最小长度（字符数），更短的别名（如 NSF）只做精确匹配
+const minAliasContainLength = 4
+
+// Resolution 机构解析结果
+type Resolution struct {
+	AgencyID  uint   `json:"agency_id,omitempty"`
+	CountryID uint   `json:"country_id,omitempty"`
+	MatchedBy string `json:"matched_by,omitempty"` // domain 或 name，为空表示未匹配
+}
+
+// Resolver 根据 URL 主机名和来源名称解析情报所属的机构与国家
+type Resolver struct {
+	db *gorm.DB
+
+	mu       sync.Mutex
+	loadedAt time.Time
+	byHost   map[string][]Agency // 规范主机名 -> 使用该域名的机构（可能多个机构共用同一域名）
+	names    []agencyName        // 所有机构的名称与别名
+}
+
+// agencyName 机构名称或别名的归一化形式
+type agencyName struct {
+	agency Agency
+	name   string
+}
+
+// NewResolver 创建机构解析器
+func NewResolver(db *gorm.DB) *Resolver {
+	return &Resolver{db: db}
+}
+
+// Resolve 解析机构与国家：
+//  1. 按 URL 主机名匹配机构域名（含子域名），唯一命中即返回
+//  2. 多个机构共用域名时，用来源名称及标题在这些机构中消歧；无法消歧但国家一致时只返回国家
+//  3. 域名未命中时，按来源名称匹配全部机构的名称与别名
+func (r *Resolver) Resolve(rawURL, source, title string) (Resolution, error) {
+	if err := r.ensureLoaded(); err != nil {
+		return Resolution{}, err
+	}
+
+	r.mu.Lock()
+	byHost, names := r.byHost, r.names
+	r.mu.Unlock()
+
+	if candidates := lookupHost(byHost, hostOf(rawURL)); len(candidates) > 0 {
+		if len(candidates) == 1 {
+			return resolved(candidates[0], MatchByDomain), nil
+		}
+		if agency, ok := bestNameMatch(namesOf(names, candidates), source, title); ok {
+			return resolved(agency, MatchByDomain), nil
+		}
+		if countryID := commonCountry(candidates); countryID != 0 {
+			return Resolution{CountryID: countryID, MatchedBy: MatchByDomain}, nil
+		}
+		return Resolution{}, nil
+	}
+
+	if agency, ok := bestNameMatch(names, source, ""); ok {
+		return resolved(agency, MatchByName), nil
+	}
+	return Resolution{}, nil
+}
+
+// resolved 构造命中单个机构的解析结果
+func resolved(agency Agency, matchedBy string) Resolution {
+	return Resolution{AgencyID: agency.ID, CountryID: agency.CountryID, MatchedBy: matchedBy}
+}
+
+// ensureLoaded 缓存过期时重新加载机构索引
+func (r *Resolver) ensureLoaded() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.byHost != nil && time.Since(r.loadedAt) < resolverCacheTTL {
+		return nil
+	}
+
+	var agencies []Agency
+	if err := r.db.Order("id ASC").Find(&agencies).Error; err != nil {
+		return err
+	}
+
+	byHost := make(map[string][]Agency)
+	names := []agencyName{}
+	for _, a := range agencies {
+		if host := hostOf(a.Domain); host != "" {
+			byHost[host] = append(byHost[host], a)
+		}
+		for _, n := range a.NameVariants() {
+			if key := normalizeName(n); key != "" {
+				names = append(names, agencyName{agency: a, name: key})
+			}
+		}
+	}
+
+	r.byHost, r.names, r.loadedAt = byHost, names, time.Now()
+	return nil
+}
+
+// hostOf 提取规范主机名：小写，去除协议、端口与 www. 前缀
+// 同时接受完整 URL 和裸域名
+func hostOf(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	return strings.TrimPrefix(host, "www.")
+}
+
+// lookupHost 从完整主机名开始逐级向上查找机构域名，保留至少两级（如 gov.uk）
+func lookupHost(byHost map[string][]Agency, host string) []Agency {
+	for host != "" {
+		if agencies, ok := byHost[host]; ok {
+			return agencies
+		}
+		dot := strings.IndexByte(host, '.')
+		if dot < 0 || !strings.Contains(host[dot+1:], ".") {
+			return nil
+		}
+		host = host[dot+1:]
+	}
+	return nil
+}
+
+// namesOf 筛选出指定机构的名称条目
+func namesOf(names []agencyName, agencies []Agency) []agencyName {
+	ids := make(map[uint]bool, len(agencies))
+	for _, a := range agencies {
+		ids[a.ID] = true
+	}
+	out := []agencyName{}
+	for _, n := range names {
+		if ids[n.agency.ID] {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// bestNameMatch 在名称条目中查找与来源名称（及标题）最匹配的唯一机构
+// 精确相等得 2 分，包含关系得 1 分；最高分有多个机构并列时视为无法判定
+func bestNameMatch(names []agencyName, source, title string) (Agency, bool) {
+	texts := []string{}
+	for _, t := range []string{source, title} {
+		if key := normalizeName(t); key != "" {
+			texts = append(texts, key)
+		}
+	}
+	if len(texts) == 0 {
+		return Agency{}, false
+	}
+
+	scores := make(map[uint]int)
+	agencies := make(map[uint]Agency)
+	for _, n := range names {
+		score := 0
+		for _, text := range texts {
+			switch {
+			case text == n.name:
+				score = 2
+			case score < 1 && len([]rune(n.name)) >= minAliasContainLength &&
+				(containsName(text, n.name) || len([]rune(text)) >= minAliasContainLength && containsName(n.name, text)):
+				score = 1
+			}
+		}
+		if score > scores[n.agency.ID] {
+			scores[n.agency.ID] = score
+			agencies[n.agency.ID] = n.agency
+		}
+	}
+
+	var best Agency
+	bestScore, tie := 0, false
+	for id, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, tie = agencies[id], score, false
+		case score == bestScore && score > 0:
+			tie = true
+		}
+	}
+	if bestScore == 0 || tie {
+		return Agency{}, false
+	}
+	return best, true
+}
+
+// commonCountry 候选机构属于同一国家时返回该国家ID
+func commonCountry(agencies []Agency) uint {
+	countryID := agencies[0].CountryID
+	for _, a := range agencies[1:] {
+		if a.CountryID != countryID {
+			return 0
+		}
+	}
+	return countryID
+}
+
+// normalizeName 名称归一化：小写，字母和数字之外的字符视为单词分隔，连续分隔合并为一个空格
+func normalizeName(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			space = false
+		} else {
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// containsName 判断 text 是否包含 name
+// 含中日韩文字的名称按子串匹配，其余名称须落在单词边界上，避免 RAND 命中 Random
+func containsName(text, name string) bool {
+	for _, r := range name {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			return strings.Contains(text, name)
+		}
+	}
+	return strings.Contains(" "+text+" ", " "+name+" ")
+}
+
+// parenPattern 匹配名称中括号内的缩写或英文名，如 "美国能源部 (DOE)"
+var parenPattern = regexp.MustCompile(`\s*[(（]([^)）]+)[)）]\s*`)
+
+// NameVariants 返回机构的全部可匹配名称：正式名称、去掉括号后的名称、括号内的缩写以及别名
+func (a Agency) NameVariants() []string {
+	variants := []string{a.Name}
+	if m := parenPattern.FindStringSubmatch(a.Name); m != nil {
+		variants = append(variants, parenPattern.ReplaceAllString(a.Name, ""), m[1])
+	}
+	for _, alias := range strings.Split(a.Aliases, ";") {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			variants = append(variants, alias)
+		}
+	}
+	return variants
+}
@@ -0,0 +1,157 @@
+// Package query 情报检索表达式的解析
+//
+// 示例：量子 AND (NSF OR DOE) NOT 新闻 agency:US title:"2026 budget" date>=2025-01-01
+//
+// 空格分隔的检索词之间默认为 AND 关系；运算符 AND、OR、NOT 须大写，优先级 NOT > AND > OR；
+// 前缀 - 等同于 NOT；双引号包裹短语，短语内的 \" 表示引号本身；
+// 字段条件形如 name:value 或 name>=value，可用字段与运算符见 fieldOps。
+// 解析结果为抽象语法树（AST），由调用方编译为数据库查询条件。
+package query
+
+import (
+	"fmt"
+	"time"
+)
+
+// 字段名
+const (
+	FieldTitle    = "title"    // 标题
+	FieldSummary  = "summary"  // 摘要
+	FieldContent  = "content"  // 正文
+	FieldKeywords = "keywords" // 关键词
+	FieldSource   = "source"   // 来源
+	FieldURL      = "url"      // 原文链接
+	FieldAgency   = "agency"   // 机构：ID、名称、别名或所属国家代码
+	FieldCountry  = "country"  // 国家：ID、代码或名称
+	FieldDate     = "date"     // 发布日期
+)
+
+// 字段运算符
+const (
+	OpMatch = ":"  // 包含（文本字段）或等于（其他字段）
+	OpEq    = "="  // 等于
+	OpGt    = ">"  // 晚于
+	OpGte   = ">=" // 不早于
+	OpLt    = "<"  // 早于
+	OpLte   = "<=" // 不晚于
+)
+
+// Node 语法树节点
+type Node interface {
+	// Pos 节点在原始表达式中的起始位置（从 0 开始的字符偏移）
+	Pos() int
+}
+
+// And 所有子节点均须命中
+type And struct {
+	Nodes  []Node
+	Offset int
+}
+
+// Or 任一子节点命中即可
+type Or struct {
+	Nodes  []Node
+	Offset int
+}
+
+// Not 子节点不得命中
+type Not struct {
+	Node   Node
+	Offset int
+}
+
+// Term 检索词或短语，在全部文本字段中匹配
+type Term struct {
+	Text   string
+	Phrase bool // 是否为引号短语
+	Offset int
+}
+
+// Field 字段条件
+type Field struct {
+	Name        string // 规范字段名，见 Field* 常量
+	Op          string // 运算符，见 Op* 常量
+	Value       string
+	Phrase      bool // 取值是否为引号短语
+	Offset      int
+	ValueOffset int // 取值的起始位置，用于报告取值错误
+}
+
+// Pos 实现 Node 接口
+func (n *And) Pos() int { return n.Offset }
+
+// Pos 实现 Node 接口
+func (n *Or) Pos() int { return n.Offset }
+
+// Pos 实现 Node 接口
+func (n *Not) Pos() int { return n.Offset }
+
+// Pos 实现 Node 接口
+func (n *Term) Pos() int { return n.Offset }
+
+// Pos 实现 Node 接口
+func (n *Field) Pos() int { return n.Offset }
+
+// Error 检索表达式错误，包含出错位置
+type Error struct {
+	Position int    `json:"position"` // 从 0 开始的字符偏移
+	Message  string `json:"message"`
+}
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Position)
+}
+
+// errorf 构造指定位置的表达式错误
+func errorf(pos int, format string, args ...interface{}) *Error {
+	return &Error{Position: pos, Message: fmt.Sprintf(format, args...)}
+}
+
+// Terms 返回表达式中需要命中的检索词（不含 NOT 之下的词和字段条件），用于相关度排序与高亮
+func Terms(n Node) []string {
+	terms := []string{}
+	var walk func(Node)
+	walk = func(n Node) {
+		switch n := n.(type) {
+		case *And:
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *Or:
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *Term:
+			terms = append(terms, n.Text)
+		}
+	}
+	walk(n)
+	return terms
+}
+
+// dateLayouts 日期字段支持的取值格式及对应的区间长度
+var dateLayouts = []struct {
+	layout string
+	years  int
+	months int
+	days   int
+}{
+	{"2006-01-02", 0, 0, 1},
+	{"2006-01", 0, 1, 0},
+	{"2006", 1, 0, 0},
+}
+
+// DateRange 解析日期字段的取值，返回左闭右开区间 [from, to)
+// 取值可以是年（2025）、年月（2025-01）或年月日（2025-01-01）
+func (f *Field) DateRange() (from, to time.Time, err error) {
+	for _, l := range dateLayouts {
+		if len(f.Value) != len(l.layout) {
+			continue
+		}
+		if from, err = time.Parse(l.layout, f.Value); err == nil {
+			return from, from.AddDate(l.years, l.months, l.days), nil
+		}
+	}
+	return time.Time{}, time.Time{}, errorf(f.ValueOffset, "invalid date %q, expected YYYY, YYYY-MM or YYYY-MM-DD", f.Value)
+}
@@ -0,0 +1,238 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+)
+
+// tokenKind 词法单元类型
+type tokenKind int
+
+const (
+	tokEOF    tokenKind = iota
+	tokWord             // 检索词
+	tokPhrase           // 引号短语
+	tokField            // 字段条件
+	tokAnd              // AND
+	tokOr               // OR
+	tokNot              // NOT 或前缀 -
+	tokLParen           // (
+	tokRParen           // )
+)
+
+// token 词法单元
+type token struct {
+	kind  tokenKind
+	pos   int
+	text  string // 检索词、短语内容或原始运算符
+	field *Field // 仅 tokField 有效
+}
+
+// describe 用于错误信息的词法单元描述
+func (t token) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokLParen:
+		return `"("`
+	case tokRParen:
+		return `")"`
+	default:
+		return `"` + t.text + `"`
+	}
+}
+
+// fieldAliases 字段名（小写）到规范字段名的映射
+var fieldAliases = map[string]string{
+	"title":    FieldTitle,
+	"summary":  FieldSummary,
+	"content":  FieldContent,
+	"keywords": FieldKeywords,
+	"keyword":  FieldKeywords,
+	"source":   FieldSource,
+	"url":      FieldURL,
+	"agency":   FieldAgency,
+	"country":  FieldCountry,
+	"date":     FieldDate,
+}
+
+// fieldOps 各字段允许的运算符
+var fieldOps = map[string][]string{
+	FieldTitle:    {OpMatch},
+	FieldSummary:  {OpMatch},
+	FieldContent:  {OpMatch},
+	FieldKeywords: {OpMatch},
+	FieldSource:   {OpMatch},
+	FieldURL:      {OpMatch},
+	FieldAgency:   {OpMatch, OpEq},
+	FieldCountry:  {OpMatch, OpEq},
+	FieldDate:     {OpMatch, OpEq, OpGt, OpGte, OpLt, OpLte},
+}
+
+// lexer 词法分析器，位置按字符（rune）计算
+type lexer struct {
+	src []rune
+	pos int
+}
+
+// peek 查看当前位置的字符，已到末尾时返回 0
+func (l *lexer) peek() rune {
+	if l.pos >= len(l.src) {
+		return 0
+	}
+	return l.src[l.pos]
+}
+
+// next 读取下一个词法单元
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) && unicode.IsSpace(l.src[l.pos]) {
+		l.pos++
+	}
+	start := l.pos
+	if start >= len(l.src) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+
+	switch l.src[start] {
+	case '(':
+		l.pos++
+		return token{kind: tokLParen, pos: start, text: "("}, nil
+	case ')':
+		l.pos++
+		return token{kind: tokRParen, pos: start, text: ")"}, nil
+	case '"':
+		text, err := l.readPhrase()
+		if err != nil {
+			return token{}, err
+		}
+		return token{kind: tokPhrase, pos: start, text: text}, nil
+	case '-':
+		// 紧跟检索词、短语或括号的 - 表示排除
+		if start+1 < len(l.src) && !unicode.IsSpace(l.src[start+1]) && l.src[start+1] != ')' {
+			l.pos++
+			return token{kind: tokNot, pos: start, text: "-"}, nil
+		}
+	}
+
+	word := l.readWord()
+	switch word {
+	case "AND":
+		return token{kind: tokAnd, pos: start, text: word}, nil
+	case "OR":
+		return token{kind: tokOr, pos: start, text: word}, nil
+	case "NOT":
+		return token{kind: tokNot, pos: start, text: word}, nil
+	}
+
+	if name, op, value, ok := splitField(word); ok {
+		return l.fieldToken(start, word, name, op, value)
+	}
+	return token{kind: tokWord, pos: start, text: word}, nil
+}
+
+// readWord 读取到空白、括号或引号为止的连续字符
+func (l *lexer) readWord() string {
+	start := l.pos
+	for l.pos < len(l.src) {
+		r := l.src[l.pos]
+		if unicode.IsSpace(r) || r == '(' || r == ')' || r == '"' {
+			break
+		}
+		l.pos++
+	}
+	return string(l.src[start:l.pos])
+}
+
+// readPhrase 读取引号短语，当前位置须为起始引号
+func (l *lexer) readPhrase() (string, error) {
+	start := l.pos
+	l.pos++
+
+	var b strings.Builder
+	for l.pos < len(l.src) {
+		r := l.src[l.pos]
+		switch {
+		case r == '\\' && l.pos+1 < len(l.src) && l.src[l.pos+1] == '"':
+			b.WriteRune('"')
+			l.pos += 2
+		case r == '"':
+			l.pos++
+			return strings.TrimSpace(b.String()), nil
+		default:
+			b.WriteRune(r)
+			l.pos++
+		}
+	}
+	return "", errorf(start, "unterminated quoted phrase")
+}
+
+// fieldToken 构造字段条件词法单元，取值为空时读取紧随其后的引号短语
+func (l *lexer) fieldToken(start int, word, name, op, value string) (token, error) {
+	canonical, known := fieldAliases[strings.ToLower(name)]
+	if !known {
+		// 形如 https://example.com 的链接按普通检索词处理
+		if op == OpMatch && strings.HasPrefix(value, "//") {
+			return token{kind: tokWord, pos: start, text: word}, nil
+		}
+		return token{}, errorf(start, "unknown field %q", name)
+	}
+
+	opPos := start + len([]rune(name))
+	if !allowsOp(canonical, op) {
+		return token{}, errorf(opPos, "operator %q is not supported for field %q", op, canonical)
+	}
+
+	f := &Field{Name: canonical, Op: op, Value: value, Offset: start, ValueOffset: opPos + len(op)}
+	if value == "" {
+		if l.peek() != '"' {
+			return token{}, errorf(f.ValueOffset, "missing value for field %q", canonical)
+		}
+		f.ValueOffset = l.pos
+		phrase, err := l.readPhrase()
+		if err != nil {
+			return token{}, err
+		}
+		if phrase == "" {
+			return token{}, errorf(f.ValueOffset, "missing value for field %q", canonical)
+		}
+		f.Value, f.Phrase = phrase, true
+	}
+
+	if canonical == FieldDate {
+		if _, _, err := f.DateRange(); err != nil {
+			return token{}, err
+		}
+	}
+
+	return token{kind: tokField, pos: start, text: string(l.src[start:l.pos]), field: f}, nil
+}
+
+// splitField 将 name:value、name>=value 等形式拆分为字段名、运算符和取值
+// 字段名须由字母或下划线组成
+func splitField(word string) (name, op, value string, ok bool) {
+	i := strings.IndexAny(word, ":=<>")
+	if i <= 0 {
+		return "", "", "", false
+	}
+	for _, r := range word[:i] {
+		if !(r == '_' || r < unicode.MaxASCII && unicode.IsLetter(r)) {
+			return "", "", "", false
+		}
+	}
+
+	op = word[i : i+1]
+	if (op == OpGt || op == OpLt) && strings.HasPrefix(word[i+1:], "=") {
+		op += "="
+	}
+	return word[:i], op, word[i+len(op):], true
+}
+
+// allowsOp 判断字段是否支持该运算符
+func allowsOp(field, op string) bool {
+	for _, allowed := range fieldOps[field] {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
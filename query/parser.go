@@ -0,0 +1,203 @@
+package query
+
+// maxDepth 括号最大嵌套层数
+const maxDepth = 32
+
+// parser 递归下降语法分析器
+//
+//	or      = and { "OR" and }
+//	and     = unary { ["AND"] unary }
+//	unary   = ("NOT" | "-") unary | primary
+//	primary = "(" or ")" | word | phrase | field
+type parser struct {
+	lex   *lexer
+	tok   token
+	depth int
+}
+
+// Parse 解析检索表达式，空表达式返回 nil
+// 语法错误以 *Error 返回，包含出错位置
+func Parse(input string) (Node, error) {
+	p := &parser{lex: &lexer{src: []rune(input)}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokEOF {
+		return nil, nil
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, errorf(p.tok.pos, "unexpected %s", p.tok.describe())
+	}
+	return node, nil
+}
+
+// advance 读取下一个词法单元
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+// startsUnary 当前词法单元能否作为一个条件的开始
+func (p *parser) startsUnary() bool {
+	switch p.tok.kind {
+	case tokWord, tokPhrase, tokField, tokNot, tokLParen:
+		return true
+	}
+	return false
+}
+
+// expectOperand 二元或一元运算符之后必须跟随条件
+func (p *parser) expectOperand(op token) error {
+	if p.startsUnary() {
+		return nil
+	}
+	if p.tok.kind == tokEOF {
+		return errorf(op.pos, "expected a term after %s", op.text)
+	}
+	return errorf(p.tok.pos, "unexpected %s after %s", p.tok.describe(), op.text)
+}
+
+// parseOr 解析 OR 连接的条件
+func (p *parser) parseOr() (Node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []Node{first}
+	for p.tok.kind == tokOr {
+		op := p.tok
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if err := p.expectOperand(op); err != nil {
+			return nil, err
+		}
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, next)
+	}
+
+	if len(nodes) == 1 {
+		return first, nil
+	}
+	return &Or{Nodes: nodes, Offset: first.Pos()}, nil
+}
+
+// parseAnd 解析 AND 连接（含省略 AND）的条件
+func (p *parser) parseAnd() (Node, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []Node{first}
+	for {
+		if p.tok.kind == tokAnd {
+			op := p.tok
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if err := p.expectOperand(op); err != nil {
+				return nil, err
+			}
+		} else if !p.startsUnary() {
+			break
+		}
+
+		next, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, next)
+	}
+
+	if len(nodes) == 1 {
+		return first, nil
+	}
+	return &And{Nodes: nodes, Offset: first.Pos()}, nil
+}
+
+// parseUnary 解析 NOT 条件
+func (p *parser) parseUnary() (Node, error) {
+	if p.tok.kind != tokNot {
+		return p.parsePrimary()
+	}
+
+	op := p.tok
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if err := p.expectOperand(op); err != nil {
+		return nil, err
+	}
+	inner, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &Not{Node: inner, Offset: op.pos}, nil
+}
+
+// parsePrimary 解析括号、检索词、短语与字段条件
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokWord, tokPhrase:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if tok.text == "" {
+			return nil, errorf(tok.pos, "empty quoted phrase")
+		}
+		return &Term{Text: tok.text, Phrase: tok.kind == tokPhrase, Offset: tok.pos}, nil
+
+	case tokField:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return tok.field, nil
+
+	case tokLParen:
+		if p.depth >= maxDepth {
+			return nil, errorf(tok.pos, "parentheses nested too deeply")
+		}
+		p.depth++
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tokRParen {
+			return nil, errorf(tok.pos, "empty parentheses")
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			if p.tok.kind == tokEOF {
+				return nil, errorf(tok.pos, "missing closing parenthesis")
+			}
+			return nil, errorf(p.tok.pos, "unexpected %s", p.tok.describe())
+		}
+		p.depth--
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return inner, nil
+
+	case tokEOF:
+		return nil, errorf(tok.pos, "unexpected end of query")
+	default:
+		return nil, errorf(tok.pos, "unexpected %s", tok.describe())
+	}
+}
@@ -0,0 +1,188 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// render 以前缀形式输出语法树，便于比较
+func render(n Node) string {
+	switch n := n.(type) {
+	case nil:
+		return "<nil>"
+	case *And:
+		return "(AND " + renderAll(n.Nodes) + ")"
+	case *Or:
+		return "(OR " + renderAll(n.Nodes) + ")"
+	case *Not:
+		return "(NOT " + render(n.Node) + ")"
+	case *Term:
+		if n.Phrase {
+			return `"` + n.Text + `"`
+		}
+		return n.Text
+	case *Field:
+		value := n.Value
+		if n.Phrase {
+			value = `"` + value + `"`
+		}
+		return n.Name + n.Op + value
+	}
+	return "?"
+}
+
+func renderAll(nodes []Node) string {
+	parts := make([]string, len(nodes))
+	for i, n := range nodes {
+		parts[i] = render(n)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "<nil>"},
+		{"   ", "<nil>"},
+		{"量子", "量子"},
+		{"量子 计算", "(AND 量子 计算)"},
+		{"量子 AND 计算", "(AND 量子 计算)"},
+		{"a OR b c", "(OR a (AND b c))"},
+		{"a OR b AND c", "(OR a (AND b c))"},
+		{"a b OR c d", "(OR (AND a b) (AND c d))"},
+		{"(a OR b) c", "(AND (OR a b) c)"},
+		{"NOT a b", "(AND (NOT a) b)"},
+		{"NOT a OR b", "(OR (NOT a) b)"},
+		{"NOT NOT a", "(NOT (NOT a))"},
+		{"-a", "(NOT a)"},
+		{"-(a OR b)", "(NOT (OR a b))"},
+		{`-"exact phrase"`, `(NOT "exact phrase")`},
+		{"a - b", "(AND a - b)"},
+		{"and or not", "(AND and or not)"},
+		{`"2026 budget"`, `"2026 budget"`},
+		{`"say \"hi\""`, `"say "hi""`},
+		{"https://www.nsf.gov/news", "https://www.nsf.gov/news"},
+		{"((a))", "a"},
+		{
+			`量子 AND (NSF OR DOE) NOT 新闻 agency:US title:"2026 budget" date>=2025-01-01`,
+			`(AND 量子 (OR NSF DOE) (NOT 新闻) agency:US title:"2026 budget" date>=2025-01-01)`,
+		},
+		{"Title:quantum keyword:芯片", "(AND title:quantum keywords:芯片)"},
+		{"country=US agency:NSF", "(AND country=US agency:NSF)"},
+		{"date:2025 date<2025-02 date<=2025-01-31 date>2024 date=2025-01-01", "(AND date:2025 date<2025-02 date<=2025-01-31 date>2024 date=2025-01-01)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			node, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.input, err)
+			}
+			if got := render(node); got != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		pos     int
+		message string
+	}{
+		{"a AND", 2, "expected a term after AND"},
+		{"a OR", 2, "expected a term after OR"},
+		{"NOT", 0, "expected a term after NOT"},
+		{"a OR )", 5, `unexpected ")" after OR`},
+		{"a AND OR b", 6, `unexpected "OR" after AND`},
+		{"OR a", 0, `unexpected "OR"`},
+		{"a b)", 3, `unexpected ")"`},
+		{"(a b", 0, "missing closing parenthesis"},
+		{"(a b OR", 5, "expected a term after OR"},
+		{"()", 0, "empty parentheses"},
+		{`""`, 0, "empty quoted phrase"},
+		{`a "abc`, 2, "unterminated quoted phrase"},
+		{"量子 foo:bar", 3, `unknown field "foo"`},
+		{"title>x", 5, `operator ">" is not supported for field "title"`},
+		{"source>=x", 6, `operator ">=" is not supported for field "source"`},
+		{"title:", 6, `missing value for field "title"`},
+		{`title:""`, 6, `missing value for field "title"`},
+		{`title:"abc`, 6, "unterminated quoted phrase"},
+		{"报告 date>=2025-13", 9, `invalid date "2025-13", expected YYYY, YYYY-MM or YYYY-MM-DD`},
+		{"date:yesterday", 5, `invalid date "yesterday", expected YYYY, YYYY-MM or YYYY-MM-DD`},
+		{strings.Repeat("(", maxDepth+1) + "a" + strings.Repeat(")", maxDepth+1), maxDepth, "parentheses nested too deeply"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			node, err := Parse(tt.input)
+			var qerr *Error
+			if !errors.As(err, &qerr) {
+				t.Fatalf("Parse(%q) = %s, %v, want *Error", tt.input, render(node), err)
+			}
+			if qerr.Position != tt.pos || qerr.Message != tt.message {
+				t.Errorf("Parse(%q) error = %q at %d, want %q at %d", tt.input, qerr.Message, qerr.Position, tt.message, tt.pos)
+			}
+		})
+	}
+}
+
+func TestParsePositions(t *testing.T) {
+	// 位置按字符计算：量子(0) AND(3) -(7) 新闻(8) title(11) "a b"(17)
+	node, err := Parse(`量子 AND -新闻 title:"a b"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	and, ok := node.(*And)
+	if !ok || len(and.Nodes) != 3 {
+		t.Fatalf("Parse() = %s", render(node))
+	}
+	not := and.Nodes[1].(*Not)
+	field := and.Nodes[2].(*Field)
+	got := []int{and.Pos(), and.Nodes[0].Pos(), not.Pos(), not.Node.Pos(), field.Pos(), field.ValueOffset}
+	if want := []int{0, 0, 7, 8, 11, 17}; !reflect.DeepEqual(got, want) {
+		t.Errorf("positions = %v, want %v", got, want)
+	}
+
+	node, _ = Parse("a OR (b c)")
+	or := node.(*Or)
+	if got := []int{or.Pos(), or.Nodes[1].Pos()}; !reflect.DeepEqual(got, []int{0, 6}) {
+		t.Errorf("positions = %v, want [0 6]", got)
+	}
+}
+
+func TestTerms(t *testing.T) {
+	node, err := Parse(`a (b OR "c d") NOT e -f title:g`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := Terms(node), []string{"a", "b", "c d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Terms() = %v, want %v", got, want)
+	}
+}
+
+func TestFieldDateRange(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		value    string
+		from, to time.Time
+	}{
+		{"2025", day(2025, 1, 1), day(2026, 1, 1)},
+		{"2025-02", day(2025, 2, 1), day(2025, 3, 1)},
+		{"2024-02-29", day(2024, 2, 29), day(2024, 3, 1)},
+		{"2025-12-31", day(2025, 12, 31), day(2026, 1, 1)},
+	}
+	for _, tt := range tests {
+		from, to, err := (&Field{Name: FieldDate, Value: tt.value}).DateRange()
+		if err != nil || !from.Equal(tt.from) || !to.Equal(tt.to) {
+			t.Errorf("DateRange(%s) = [%s, %s), %v, want [%s, %s)", tt.value, from, to, err, tt.from, tt.to)
+		}
+	}
+	if _, _, err := (&Field{Name: FieldDate, Value: "2025-02-30"}).DateRange(); err == nil {
+		t.Error("DateRange(2025-02-30) error = nil")
+	}
+}
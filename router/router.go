@@ -1,8 +1,10 @@
 package router
 
 import (
+	"policy-backend/analysis"
 	"policy-backend/auth"
 	"policy-backend/intelligence"
+	"policy-backend/llm"
 	custommiddleware "policy-backend/middleware"
 	"policy-backend/org"
 	"policy-backend/search"
@@ -15,8 +17,9 @@ import (
 	"gorm.io/gorm"
 )
 
-// Init 初始化路由，使用auth模块的配置
-func Init(e *echo.Echo, db *gorm.DB, authCfg *auth.Config) {
+// Init 初始化路由，使用auth模块和analysis模块的配置，llmClient 为按档位调用的大模型客户端；
+// searchH 与 intelligenceSvc 由调用方创建并与定时任务共用（监听任务创建的检索会话需经同一处理器推送进度）
+func Init(e *echo.Echo, db *gorm.DB, authCfg *auth.Config, analysisCfg *analysis.Config, searchH *search.Handler, intelligenceSvc *intelligence.Service, llmClient *llm.Client) {
 	// 1. 统一前缀
 	api := e.Group("/api")
 	api.Use(custommiddleware.ZapLogger()) // 使用自定义的 Zap 日志中间件
@@ -59,11 +62,15 @@ func Init(e *echo.Echo, db *gorm.DB, authCfg *auth.Config) {
 	user.RegisterRoutes(userGroup, userH)
 
 	// Search 模块（需要认证）
-	searchH := search.NewHandler(db, pointsSvc)
 	searchGroup := api.Group("/search")
 	searchGroup.Use(authMiddleware)
 	search.RegisterRoutes(searchGroup, searchH)
 
+	// 监听任务（与搜索模块共用处理器，需要认证）
+	monitorGroup := api.Group("/monitors")
+	monitorGroup.Use(authMiddleware)
+	search.RegisterMonitorRoutes(monitorGroup, searchH)
+
 	// Team 模块（需要认证）
 	teamH := team.NewHandler(db)
 	teamGroup := api.Group("/teams")
@@ -72,7 +79,6 @@ func Init(e *echo.Echo, db *gorm.DB, authCfg *auth.Config) {
 
 	// intelligence 模块（需要认证）
 	// 使用依赖注入模式
-	intelligenceSvc := intelligence.NewService(db)
 	intelligenceH := intelligence.NewHandler(intelligenceSvc)
 
 	// 注册 /intelligence 路由组
@@ -80,6 +86,12 @@ func Init(e *echo.Echo, db *gorm.DB, authCfg *auth.Config) {
 	intelligenceGroup.Use(authMiddleware)
 	intelligence.RegisterRoutes(intelligenceGroup, intelligenceH)
 
+	// Analysis 模块（需要认证）
+	analysisH := analysis.NewHandler(db, pointsSvc, analysisCfg, llmClient)
+	analysisGroup := api.Group("/analysis")
+	analysisGroup.Use(authMiddleware)
+	analysis.RegisterRoutes(analysisGroup, analysisH)
+
 	// Org 模块（需要认证）
 	orgH := org.NewHandler(db)
 	orgGroup := e.Group("/org")
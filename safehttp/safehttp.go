@@ -0,0 +1,85 @@
+// Package safehttp 抓取外部链接用的 HTTP 客户端
+// 情报链接、网页链接来自第三方订阅源或用户上传的文件，为防止借此访问内网服务（SSRF），
+// 只允许 http(s)，建立连接时校验实际连接的 IP，并限制与校验每次重定向
+package safehttp
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// MaxRedirects 最多跟随的重定向次数
+const MaxRedirects = 5
+
+var (
+	// ErrBadURL 链接不是 http(s) 地址
+	ErrBadURL = errors.New("url must be http or https")
+	// ErrBlockedAddress 链接指向内网、回环、链路本地等地址
+	ErrBlockedAddress = errors.New("url resolves to a non-public address")
+	// ErrTooManyRedirects 重定向次数超过 MaxRedirects
+	ErrTooManyRedirects = errors.New("url redirected too many times")
+)
+
+// NewClient 创建只访问公网地址的 HTTP 客户端
+// 建立连接时校验实际连接的 IP（域名解析后的结果，可防止 DNS 重绑定），每次重定向都重新校验目标链接并限制次数；
+// 不使用环境变量中的代理，否则校验的是代理地址
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: dialControl}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= MaxRedirects {
+				return ErrTooManyRedirects
+			}
+			return CheckURL(req.URL)
+		},
+	}
+}
+
+// CheckURL 校验链接：只允许 http(s)，主机为 IP 时不能是非公网地址
+// 域名在建立连接时才解析，由客户端的连接校验兜底
+func CheckURL(u *url.URL) error {
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrBadURL
+	}
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil && !IsPublicAddr(addr) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+// dialControl 在建立连接前校验目标 IP，拒绝非公网地址
+func dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !IsPublicAddr(addr) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+// sharedAddrSpace 运营商级 NAT 地址段（RFC 6598），同样不属于公网
+var sharedAddrSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// IsPublicAddr 是否为可访问的公网地址（排除内网、回环、链路本地、未指定与组播地址）
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return !(addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() ||
+		addr.IsUnspecified() || sharedAddrSpace.Contains(addr))
+}
@@ -0,0 +1,91 @@
+package safehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"8.8.8.8", true},
+		{"2001:4860:4860::8888", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"224.0.0.1", false},
+		{"::1", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := IsPublicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("IsPublicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{"https://www.gov.cn/a.html", nil},
+		{"http://8.8.8.8/a.pdf", nil},
+		{"ftp://example.com/a.pdf", ErrBadURL},
+		{"file:///etc/passwd", ErrBadURL},
+		{"http:///a", ErrBadURL},
+		{"http://127.0.0.1:8080/admin", ErrBlockedAddress},
+		{"http://[::1]/", ErrBlockedAddress},
+		{"http://169.254.169.254/latest/meta-data/", ErrBlockedAddress},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.url)
+		if err := CheckURL(u); !errors.Is(err, tt.want) {
+			t.Errorf("CheckURL(%s) = %v, want %v", tt.url, err, tt.want)
+		}
+	}
+}
+
+func TestClientBlocksLoopback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	// 以域名访问回环地址，只有建立连接时的校验能拦截
+	u, _ := url.Parse(srv.URL)
+	link := "http://localhost:" + u.Port() + "/"
+	_, err := NewClient(5 * time.Second).Get(link)
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("Get(%s) error = %v, want ErrBlockedAddress", link, err)
+	}
+}
+
+func TestClientCheckRedirect(t *testing.T) {
+	c := NewClient(time.Second)
+	req := func(link string) *http.Request {
+		r, _ := http.NewRequest(http.MethodGet, link, nil)
+		return r
+	}
+	via := make([]*http.Request, MaxRedirects-1)
+	if err := c.CheckRedirect(req("https://example.com/b"), via); err != nil {
+		t.Errorf("redirect %d error = %v", len(via), err)
+	}
+	if err := c.CheckRedirect(req("http://10.0.0.1/"), via); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("redirect to private address error = %v, want ErrBlockedAddress", err)
+	}
+	if err := c.CheckRedirect(req("https://example.com/c"), append(via, req("https://example.com/b"))); !errors.Is(err, ErrTooManyRedirects) {
+		t.Errorf("redirect %d error = %v, want ErrTooManyRedirects", MaxRedirects, err)
+	}
+}
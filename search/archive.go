@@ -0,0 +1,146 @@
+package search
+
+import (
+	"fmt"
+	"net/http"
+	"policy-backend/user"
+	"policy-backend/utils"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// bufferExpireAt 计算新写入缓冲区记录的过期时间
+// 取默认保留时长与会话已延期的过期时间中较晚者，保证延期后新抓取的记录同样生效
+func (h *Handler) bufferExpireAt(sessionID string) time.Time {
+	expireAt := time.Now().Add(h.cfg.bufferTTL())
+
+	var session SearchSession
+	if err := h.db.Select("expire_at").First(&session, "id = ?", sessionID).Error; err == nil &&
+		session.ExpireAt != nil && session.ExpireAt.After(expireAt) {
+		expireAt = *session.ExpireAt
+	}
+	return expireAt
+}
+
+// findUserSession 查找属于当前用户的会话
+func (h *Handler) findUserSession(c echo.Context) (*SearchSession, error) {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return nil, utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var session SearchSession
+	if err := h.db.Where("id = ? AND user_id = ?", c.Param("id"), currentUser.ID).First(&session).Error; err != nil {
+		return nil, utils.Fail(c, http.StatusNotFound, "Session not found")
+	}
+	return &session, nil
+}
+
+// setSessionExpireAt 同步更新会话及其缓冲区的过期时间
+func (h *Handler) setSessionExpireAt(sessionID string, expireAt time.Time) error {
+	if err := h.db.Model(&SearchSession{}).Where("id = ?", sessionID).
+		Update("expire_at", &expireAt).Error; err != nil {
+		return err
+	}
+	return h.db.Model(&SearchBuffer{}).Where("session_id = ?", sessionID).
+		Update("expire_at", expireAt).Error
+}
+
+// ExtendSession 延长会话缓冲区的保留时间
+// POST /api/search/sessions/:id/extend
+// 保留时间从当前时间起算，不超过配置的最长保留时长，且不会缩短已有的过期时间
+func (h *Handler) ExtendSession(c echo.Context) error {
+	var req ExtendSessionRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid parameters")
+	}
+
+	if err := utils.ValidateRequest(c, &req); err != nil {
+		return err
+	}
+
+	session, err := h.findUserSession(c)
+	if session == nil {
+		return err
+	}
+
+	if session.IsArchived() {
+		return utils.Fail(c, http.StatusConflict, "Archived sessions do not expire")
+	}
+
+	now := time.Now()
+	expireAt := now.Add(time.Duration(req.Hours) * time.Hour)
+	if limit := now.Add(h.cfg.maxBufferTTL()); expireAt.After(limit) {
+		expireAt = limit
+	}
+	if session.ExpireAt != nil && session.ExpireAt.After(expireAt) {
+		expireAt = *session.ExpireAt
+	}
+
+	if err := h.setSessionExpireAt(session.ID, expireAt); err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to extend session")
+	}
+
+	return utils.Success(c, map[string]interface{}{
+		"session_id": session.ID,
+		"expire_at":  expireAt,
+	})
+}
+
+// ArchiveSession 归档会话，归档后其缓冲区不再被定时任务清理
+// POST /api/search/sessions/:id/archive
+// 每个用户可归档的会话数受配置限制
+func (h *Handler) ArchiveSession(c echo.Context) error {
+	session, err := h.findUserSession(c)
+	if session == nil {
+		return err
+	}
+
+	if session.IsArchived() {
+		return utils.Success(c, session)
+	}
+
+	var archivedCount int64
+	if err := h.db.Model(&SearchSession{}).
+		Where("user_id = ? AND archived_at IS NOT NULL", session.UserID).
+		Count(&archivedCount).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to count archived sessions")
+	}
+	if archivedCount >= int64(h.cfg.ArchiveLimit) {
+		return utils.Fail(c, http.StatusForbidden,
+			fmt.Sprintf("Archive limit reached (%d sessions), unarchive a session first", h.cfg.ArchiveLimit))
+	}
+
+	now := time.Now()
+	if err := h.db.Model(session).Update("archived_at", &now).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to archive session")
+	}
+
+	return utils.Success(c, session)
+}
+
+// UnarchiveSession 取消归档，缓冲区从当前时间起按默认保留时长重新计时
+// DELETE /api/search/sessions/:id/archive
+func (h *Handler) UnarchiveSession(c echo.Context) error {
+	session, err := h.findUserSession(c)
+	if session == nil {
+		return err
+	}
+
+	if !session.IsArchived() {
+		return utils.Success(c, session)
+	}
+
+	if err := h.db.Model(session).Update("archived_at", nil).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to unarchive session")
+	}
+
+	expireAt := time.Now().Add(h.cfg.bufferTTL())
+	if err := h.setSessionExpireAt(session.ID, expireAt); err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to reset session expiry")
+	}
+	session.ExpireAt = &expireAt
+
+	return utils.Success(c, session)
+}
@@ -0,0 +1,101 @@
+package search
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"policy-backend/user"
+	"policy-backend/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultGlobalLimit 全网检索未指定数量时的结果上限，同时用于按条计价的预授权
+const defaultGlobalLimit = 100
+
+// errReservePoints 冻结积分失败（含余额不足）
+var errReservePoints = errors.New("failed to reserve points")
+
+// searchMetadata 检索扣费的结构化附加信息
+func searchMetadata(job searchJob, price ModelPrice) user.PointsMetadata {
+	meta := user.PointsMetadata{
+		"session_id": job.SessionID,
+		"query":      job.Request.Q,
+		"model":      price.Model,
+		"base":       price.Base,
+		"per_result": price.PerResult,
+		"limit":      job.Request.Limit,
+	}
+	if job.MonitorID != 0 {
+		meta["monitor_id"] = job.MonitorID
+	}
+	return meta
+}
+
+// reserveSearch 按最大可能费用（基础价 + 单条价 × 结果上限）冻结积分
+// 费用为 0 时不创建预授权，返回 nil
+func (h *Handler) reserveSearch(job searchJob) (*user.PointsReservation, error) {
+	price := h.cfg.Price(job.Request.Model)
+	amount := price.Cost(job.Request.Limit)
+	if amount == 0 {
+		return nil, nil
+	}
+	return h.pointsService.Reserve(job.UserID, amount, job.SessionID, "检索预授权（"+price.Model+"）",
+		searchMetadata(job, price))
+}
+
+// captureSearch 检索成功后按实际结果条数结算，多冻结的积分自动退回
+func (h *Handler) captureSearch(job searchJob, results int) int64 {
+	price := h.cfg.Price(job.Request.Model)
+	cost := price.Cost(results)
+	if job.ReservationID == 0 {
+		return 0
+	}
+
+	meta := searchMetadata(job, price)
+	meta["results"] = results
+	if err := h.pointsService.Capture(job.ReservationID, cost, "检索结算退回（"+price.Model+"）", meta); err != nil {
+		log.Printf("Failed to capture points for search session %s: %v\n", job.SessionID, err)
+	}
+	return cost
+}
+
+// releaseSession 释放会话的积分预授权（检索失败或中断时全额退回）
+func (h *Handler) releaseSession(sessionID string, reason string) {
+	var session SearchSession
+	if err := h.db.Select("id, reservation_id").First(&session, "id = ?", sessionID).Error; err != nil ||
+		session.ReservationID == nil {
+		return
+	}
+
+	err := h.pointsService.Release(*session.ReservationID, "检索失败退回", user.PointsMetadata{
+		"session_id": sessionID,
+		"reason":     reason,
+	})
+	if err != nil && !errors.Is(err, user.ErrReservationSettled) {
+		log.Printf("Failed to release points for search session %s: %v\n", sessionID, err)
+	}
+}
+
+// GetPricing 获取各模型档位的检索价格
+// GET /api/search/pricing
+func (h *Handler) GetPricing(c echo.Context) error {
+	return utils.Success(c, map[string]interface{}{
+		"default_limit": defaultGlobalLimit,
+		"models":        h.cfg.Pricing(),
+	})
+}
+
+// failStartSearch 发起全网检索失败时返回对应的错误响应
+func failStartSearch(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, user.ErrInsufficientPoints):
+		return utils.Fail(c, http.StatusPaymentRequired, "Insufficient points")
+	case errors.Is(err, errReservePoints):
+		return utils.Error(c, http.StatusInternalServerError, "Failed to reserve points")
+	case errors.Is(err, errSearchQueueFull):
+		return utils.Error(c, http.StatusServiceUnavailable, "Search queue is full, please retry later")
+	default:
+		return utils.Error(c, http.StatusInternalServerError, "Failed to create search session")
+	}
+}
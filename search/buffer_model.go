@@ -2,6 +2,7 @@ package search
 
 import (
 	"encoding/json"
+	"policy-backend/intelligence"
 	"time"
 
 	"gorm.io/gorm"
@@ -25,16 +26,33 @@ type SearchBuffer struct {
 	PreviewDate    time.Time `gorm:"comment:预览发布日期" json:"preview_date"`
 	PreviewSummary string    `gorm:"type:text;comment:预览摘要" json:"preview_summary"`
 
+	// 机构识别（按 URL 主机名或来源名称匹配 org.Agency）
+	AgencyID    uint   `gorm:"index;comment:识别出的机构ID" json:"agency_id,omitempty"`
+	CountryID   uint   `gorm:"index;comment:识别出的国家ID" json:"country_id,omitempty"`
+	AgencyMatch string `gorm:"type:varchar(20);comment:机构匹配方式:domain域名,name名称" json:"agency_match,omitempty"`
+
 	// 查重相关
-	DataHash        string `gorm:"type:varchar(64);index;comment:内容哈希（用于快速查重）" json:"data_hash"`
-	DuplicateStatus string `gorm:"type:varchar(20);default:'new';comment:查重结果:new新内容,exists已存在" json:"duplicate_status"`
+	CanonicalURL                    string `gorm:"type:text;comment:规范化后的URL（用于查重）" json:"canonical_url"`
+	DataHash                        string `gorm:"type:varchar(64);index;comment:内容哈希（用于快速查重）" json:"data_hash"`
+	HashVersion                     int    `gorm:"not null;default:0;comment:计算规范URL与哈希所用的规则版本" json:"-"`
+	intelligence.ContentFingerprint `gorm:"embedded"`
+	DuplicateStatus                 string          `gorm:"type:varchar(20);default:'new';comment:查重结果:new新内容,exists已存在,similar近似重复" json:"duplicate_status"`
+	SimilarMatches                  json.RawMessage `gorm:"type:json;comment:近似重复的情报ID及相似度" json:"similar_matches,omitempty"`
 
 	// 状态管理
-	Status     string     `gorm:"type:varchar(20);default:'pending';comment:状态:pending待处理,imported已入库,discarded已丢弃" json:"status"`
-	ExpireAt   time.Time  `gorm:"index;comment:过期时间" json:"expire_at"`
-	ImportedAt *time.Time `gorm:"comment:入库时间" json:"imported_at,omitempty"`
+	Status      string     `gorm:"type:varchar(20);index;default:'pending';comment:状态:pending待处理,imported已入库,discarded已丢弃" json:"status"`
+	ExpireAt    time.Time  `gorm:"index;comment:过期时间" json:"expire_at"`
+	ImportedAt  *time.Time `gorm:"comment:入库时间" json:"imported_at,omitempty"`
+	DiscardedAt *time.Time `gorm:"comment:丢弃时间" json:"discarded_at,omitempty"`
 }
 
+// 缓冲区记录状态
+const (
+	BufferStatusPending   = "pending"   // 待处理
+	BufferStatusImported  = "imported"  // 已入库
+	BufferStatusDiscarded = "discarded" // 已丢弃
+)
+
 // TableName 指定表名
 func (SearchBuffer) TableName() string {
 	return "search_buffers"
@@ -42,19 +60,27 @@ func (SearchBuffer) TableName() string {
 
 // SearchBufferPreview 用于列表展示的简化结构
 type SearchBufferPreview struct {
-	ID              uint      `json:"id"`
-	SessionID       string    `json:"session_id"`
-	PreviewTitle    string    `json:"title"`
-	PreviewSource   string    `json:"source"`
-	PreviewDate     time.Time `json:"publish_date"`
-	PreviewSummary  string    `json:"summary"`
-	DuplicateStatus string    `json:"duplicate_status"`
-	Status          string    `json:"status"`
-	CreatedAt       time.Time `json:"created_at"`
+	ID              uint                        `json:"id"`
+	SessionID       string                      `json:"session_id"`
+	PreviewTitle    string                      `json:"title"`
+	PreviewSource   string                      `json:"source"`
+	PreviewDate     time.Time                   `json:"publish_date"`
+	PreviewSummary  string                      `json:"summary"`
+	AgencyID        uint                        `json:"agency_id,omitempty"`
+	CountryID       uint                        `json:"country_id,omitempty"`
+	DuplicateStatus string                      `json:"duplicate_status"`
+	SimilarMatches  []intelligence.SimilarMatch `json:"similar_matches,omitempty"`
+	Status          string                      `json:"status"`
+	CreatedAt       time.Time                   `json:"created_at"`
 }
 
 // ToPreview 转换为预览结构
 func (b *SearchBuffer) ToPreview() *SearchBufferPreview {
+	var similar []intelligence.SimilarMatch
+	if len(b.SimilarMatches) > 0 {
+		_ = json.Unmarshal(b.SimilarMatches, &similar)
+	}
+
 	return &SearchBufferPreview{
 		ID:              b.ID,
 		SessionID:       b.SessionID,
@@ -62,30 +88,114 @@ func (b *SearchBuffer) ToPreview() *SearchBufferPreview {
 		PreviewSource:   b.PreviewSource,
 		PreviewDate:     b.PreviewDate,
 		PreviewSummary:  b.PreviewSummary,
+		AgencyID:        b.AgencyID,
+		CountryID:       b.CountryID,
 		DuplicateStatus: b.DuplicateStatus,
+		SimilarMatches:  similar,
 		Status:          b.Status,
 		CreatedAt:       b.CreatedAt,
 	}
 }
 
+// 缓冲区批量操作类型
+const (
+	BufferActionDiscard = "discard" // 丢弃
+	BufferActionRestore = "restore" // 恢复为待处理
+)
+
+// BufferBulkActionRequest 缓冲区批量操作请求
+type BufferBulkActionRequest struct {
+	Action    string `json:"action" validate:"required,oneof=discard restore"`
+	BufferIDs []uint `json:"buffer_ids" validate:"required,min=1,max=500"`
+}
+
+// BufferActionResult 缓冲区操作结果
+type BufferActionResult struct {
+	Action       string `json:"action"`
+	UpdatedCount int64  `json:"updated_count"`
+	UpdatedIDs   []uint `json:"updated_ids"`
+	SkippedIDs   []uint `json:"skipped_ids"` // 状态不允许该操作的记录（如已入库的记录不能丢弃）
+}
+
+// SessionBuffersQuery 会话缓冲区列表查询参数
+type SessionBuffersQuery struct {
+	Status          string `query:"status" validate:"omitempty,oneof=pending imported discarded"`                      // 按处理状态过滤
+	DuplicateStatus string `query:"duplicate_status" validate:"omitempty,oneof=new exists similar"`                    // 按查重结果过滤
+	Sort            string `query:"sort" validate:"omitempty,oneof=created_asc created_desc date_desc date_asc title"` // 排序方式
+	Page            int    `query:"page" validate:"omitempty,min=1"`                                                   // 页码
+	PageSize        int    `query:"page_size" validate:"omitempty,min=1,max=200"`                                      // 每页数量
+}
+
+// SessionSummary 会话缓冲区统计
+type SessionSummary struct {
+	Total     int64            `json:"total"`
+	Pending   int64            `json:"pending"`
+	Imported  int64            `json:"imported"`
+	Discarded int64            `json:"discarded"`
+	Duplicate map[string]int64 `json:"duplicate"` // 按查重结果统计（new/exists/similar）
+}
+
+// ExtendSessionRequest 会话延期请求
+type ExtendSessionRequest struct {
+	Hours int `json:"hours" validate:"required,min=1"` // 从当前时间起保留的小时数，超过上限时按上限处理
+}
+
 // ImportIntelligenceRequest 导入情报请求
 type ImportIntelligenceRequest struct {
-	BufferIDs   []uint `json:"buffer_ids" validate:"required,min=1"`
+	BufferIDs   []uint `json:"buffer_ids" validate:"required,min=1,max=500"`
 	TargetScope string `json:"target_scope" validate:"required,oneof=mine team"`
-	TeamID      uint   `json:"team_id,omitempty"` // 当 target_scope 为 team 时必填
+	TeamID      uint   `json:"team_id,omitempty"`                                        // 当 target_scope 为 team 时必填
+	Mode        string `json:"mode,omitempty" validate:"omitempty,oneof=atomic partial"` // 导入模式，默认 atomic
 }
 
+// 搜索会话状态
+const (
+	SessionStateQueued  = "queued"  // 已排队，等待后台执行
+	SessionStateRunning = "running" // 正在抓取
+	SessionStateDone    = "done"    // 抓取完成
+	SessionStateFailed  = "failed"  // 抓取失败
+)
+
 // SearchSession 搜索会话
 type SearchSession struct {
-	ID         string    `gorm:"primaryKey;type:varchar(64)" json:"id"`
-	UserID     uint      `gorm:"index" json:"user_id"`
-	Query      string    `gorm:"type:varchar(500)" json:"query"`
-	Source     string    `gorm:"type:varchar(50)" json:"source"`
-	TotalCount int       `json:"total_count"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID           string `gorm:"primaryKey;type:varchar(64)" json:"id"`
+	UserID       uint   `gorm:"index" json:"user_id"`
+	Query        string `gorm:"type:varchar(500)" json:"query"`
+	Source       string `gorm:"type:varchar(50)" json:"source"`
+	Model        string `gorm:"type:varchar(20)" json:"model"`
+	State        string `gorm:"type:varchar(20);index;default:'done';comment:状态:queued排队中,running抓取中,done已完成,failed失败" json:"state"`
+	FetchedCount int    `gorm:"default:0;comment:已写入缓冲区的条数" json:"fetched_count"`
+	TotalCount   int    `json:"total_count"`
+	Error        string `gorm:"type:varchar(500)" json:"error,omitempty"`
+
+	// 计费
+	ReservationID  *uint `gorm:"comment:积分预授权ID" json:"-"`
+	ReservedPoints int64 `gorm:"default:0;comment:冻结的积分" json:"reserved_points"`
+	Cost           int64 `gorm:"default:0;comment:实际扣除的积分" json:"cost"`
+	TokensUsed     int   `gorm:"default:0;comment:大模型消耗的 token 数" json:"tokens_used"`
+
+	// 监听任务
+	MonitorID *uint `gorm:"index;comment:发起检索的监听任务ID" json:"monitor_id,omitempty"`
+	NewCount  int   `gorm:"default:0;comment:监听任务本次运行的新增条数" json:"new_count"`
+
+	ExpireAt   *time.Time `gorm:"comment:缓冲区过期时间（延期后更新）" json:"expire_at,omitempty"`
+	ArchivedAt *time.Time `gorm:"index;comment:归档时间，已归档会话的缓冲区不会被清理" json:"archived_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
 }
 
 // TableName 指定表名
 func (SearchSession) TableName() string {
 	return "search_sessions"
 }
+
+// IsArchived 会话是否已归档
+func (s *SearchSession) IsArchived() bool {
+	return s.ArchivedAt != nil
+}
+
+// IsFinished 会话是否已结束（完成或失败）
+func (s *SearchSession) IsFinished() bool {
+	return s.State == SessionStateDone || s.State == SessionStateFailed
+}
@@ -0,0 +1,218 @@
+package search
+
+import (
+	"net/http"
+	"policy-backend/user"
+	"policy-backend/utils"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultBufferPageSize 缓冲区列表默认每页数量
+const defaultBufferPageSize = 50
+
+// bufferOrder 将排序参数转换为 ORDER BY 子句
+func bufferOrder(sort string) string {
+	switch sort {
+	case "created_desc":
+		return "created_at DESC, id DESC"
+	case "date_desc":
+		return "preview_date DESC, id ASC"
+	case "date_asc":
+		return "preview_date ASC, id ASC"
+	case "title":
+		return "preview_title ASC, id ASC"
+	default:
+		return "created_at ASC, id ASC"
+	}
+}
+
+// sessionSummary 统计会话缓冲区各状态的条数
+func (h *Handler) sessionSummary(sessionID string) (SessionSummary, error) {
+	summary := SessionSummary{Duplicate: map[string]int64{}}
+
+	var rows []struct {
+		Status          string
+		DuplicateStatus string
+		Count           int64
+	}
+	if err := h.db.Model(&SearchBuffer{}).
+		Select("status, duplicate_status, COUNT(*) AS count").
+		Where("session_id = ?", sessionID).
+		Group("status, duplicate_status").
+		Scan(&rows).Error; err != nil {
+		return summary, err
+	}
+
+	for _, row := range rows {
+		summary.Total += row.Count
+		summary.Duplicate[row.DuplicateStatus] += row.Count
+		switch row.Status {
+		case BufferStatusPending:
+			summary.Pending += row.Count
+		case BufferStatusImported:
+			summary.Imported += row.Count
+		case BufferStatusDiscarded:
+			summary.Discarded += row.Count
+		}
+	}
+	return summary, nil
+}
+
+// GetSessionSummary 获取会话缓冲区统计
+// GET /api/search/sessions/:id/summary
+func (h *Handler) GetSessionSummary(c echo.Context) error {
+	sessionID := c.Param("id")
+
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var session SearchSession
+	if err := h.db.Where("id = ? AND user_id = ?", sessionID, currentUser.ID).First(&session).Error; err != nil {
+		return utils.Fail(c, http.StatusNotFound, "Session not found")
+	}
+
+	summary, err := h.sessionSummary(sessionID)
+	if err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to summarize session")
+	}
+
+	return utils.Success(c, map[string]interface{}{
+		"session": session,
+		"summary": summary,
+	})
+}
+
+// DiscardBuffer 丢弃单条缓冲区记录
+// POST /api/search/buffers/:id/discard
+func (h *Handler) DiscardBuffer(c echo.Context) error {
+	return h.singleBufferAction(c, BufferActionDiscard)
+}
+
+// RestoreBuffer 将已丢弃的缓冲区记录恢复为待处理
+// POST /api/search/buffers/:id/restore
+func (h *Handler) RestoreBuffer(c echo.Context) error {
+	return h.singleBufferAction(c, BufferActionRestore)
+}
+
+// singleBufferAction 对路径参数指定的单条缓冲区记录执行操作
+func (h *Handler) singleBufferAction(c echo.Context, action string) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid buffer ID")
+	}
+
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	result, err := h.applyBufferAction(currentUser.ID, action, []uint{uint(id)})
+	if err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to update buffer status")
+	}
+	if result == nil {
+		return utils.Fail(c, http.StatusNotFound, "Buffer record not found")
+	}
+	if result.UpdatedCount == 0 {
+		return utils.Fail(c, http.StatusConflict, "Buffer status does not allow this action")
+	}
+
+	return utils.Success(c, result)
+}
+
+// BulkBufferAction 批量丢弃或恢复缓冲区记录
+// POST /api/search/buffers/bulk
+func (h *Handler) BulkBufferAction(c echo.Context) error {
+	var req BufferBulkActionRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid parameters")
+	}
+
+	if err := utils.ValidateRequest(c, &req); err != nil {
+		return err
+	}
+
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	result, err := h.applyBufferAction(currentUser.ID, req.Action, req.BufferIDs)
+	if err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to update buffer status")
+	}
+	if result == nil {
+		return utils.Fail(c, http.StatusForbidden, "Some buffer records do not belong to you or do not exist")
+	}
+
+	return utils.Success(c, result)
+}
+
+// applyBufferAction 执行缓冲区状态流转：
+// discard 仅作用于 pending 记录，restore 仅作用于 discarded 记录，其余记录计入 SkippedIDs
+// 任一记录不存在或不属于该用户时返回 nil 结果
+func (h *Handler) applyBufferAction(userID uint, action string, bufferIDs []uint) (*BufferActionResult, error) {
+	ids := uniqueIDs(bufferIDs)
+
+	var buffers []SearchBuffer
+	if err := h.db.Select("id, status").
+		Where("id IN ? AND user_id = ?", ids, userID).
+		Find(&buffers).Error; err != nil {
+		return nil, err
+	}
+	if len(buffers) != len(ids) {
+		return nil, nil
+	}
+
+	from, fields := BufferStatusPending, map[string]interface{}{}
+	now := time.Now()
+	switch action {
+	case BufferActionDiscard:
+		fields["status"] = BufferStatusDiscarded
+		fields["discarded_at"] = &now
+	case BufferActionRestore:
+		from = BufferStatusDiscarded
+		fields["status"] = BufferStatusPending
+		fields["discarded_at"] = nil
+	}
+
+	result := &BufferActionResult{Action: action, UpdatedIDs: []uint{}, SkippedIDs: []uint{}}
+	for _, buffer := range buffers {
+		if buffer.Status == from {
+			result.UpdatedIDs = append(result.UpdatedIDs, buffer.ID)
+		} else {
+			result.SkippedIDs = append(result.SkippedIDs, buffer.ID)
+		}
+	}
+	if len(result.UpdatedIDs) == 0 {
+		return result, nil
+	}
+
+	// 条件中带上原状态，避免与并发的导入操作互相覆盖
+	tx := h.db.Model(&SearchBuffer{}).
+		Where("id IN ? AND status = ?", result.UpdatedIDs, from).
+		Updates(fields)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	result.UpdatedCount = tx.RowsAffected
+	return result, nil
+}
+
+// uniqueIDs 去除重复的 ID，保持原顺序
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	out := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
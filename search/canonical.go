@@ -0,0 +1,180 @@
+package search
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// trackingParams 通用的跟踪参数，规范化时一律去除
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"msclkid": true,
+	"yclid":   true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"_ga":     true,
+	"_gl":     true,
+	"igshid":  true,
+	"spm":     true,
+	"ref_src": true,
+}
+
+// trackingParamPrefixes 以这些前缀开头的参数视为跟踪参数
+var trackingParamPrefixes = []string{"utm_", "hmsr", "hmpl", "hmcu", "hmkw", "hmci"}
+
+// DomainRule 针对特定站点的规范化规则
+type DomainRule struct {
+	// Host 规范主机名（不含 www.）
+	Host string
+	// Aliases 指向同一站点的其他主机名，规范化时统一替换为 Host
+	Aliases []string
+	// KeepParams 非空时只保留这些查询参数（用于以参数区分文章的站点）
+	KeepParams []string
+	// DropParams 该站点额外需要去除的查询参数
+	DropParams []string
+	// CaseInsensitivePath 路径大小写不敏感的站点，规范化时统一转小写
+	CaseInsensitivePath bool
+}
+
+// domainRules 内置的站点规则，key 为规范主机名
+var domainRules = map[string]DomainRule{}
+
+// hostAliases 别名主机名到规范主机名的映射
+var hostAliases = map[string]string{}
+
+func init() {
+	for _, rule := range []DomainRule{
+		{Host: "whitehouse.gov", Aliases: []string{"wh.gov"}},
+		{Host: "gov.cn", DropParams: []string{"eqid"}},
+		{Host: "mp.weixin.qq.com", KeepParams: []string{"__biz", "mid", "idx", "sn"}},
+	} {
+		RegisterDomainRule(rule)
+	}
+}
+
+// RegisterDomainRule 注册站点规范化规则，同名主机的规则会被覆盖
+// 应在服务启动阶段调用
+func RegisterDomainRule(rule DomainRule) {
+	host := stripWWW(strings.ToLower(rule.Host))
+	rule.Host = host
+	domainRules[host] = rule
+	for _, alias := range rule.Aliases {
+		hostAliases[stripWWW(strings.ToLower(alias))] = host
+	}
+}
+
+// CanonicalizeURL 返回用于查重的规范 URL，无法解析时返回去除首尾空白的原串
+// 规则：
+//  1. http/https 统一为 https，主机名转小写并去除 www. 前缀与默认端口
+//  2. 按站点规则替换别名主机
+//  3. 去除片段（#...）、utm_* 等跟踪参数，其余参数按名称排序
+//  4. 合并重复斜杠，去除末尾斜杠及 index.html 等默认首页
+func CanonicalizeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme == "http" || scheme == "https" {
+		scheme = "https"
+	}
+
+	host := stripWWW(strings.TrimSuffix(strings.ToLower(u.Hostname()), "."))
+	port := u.Port()
+	if port == "80" || port == "443" {
+		port = ""
+	}
+	if canonical, ok := hostAliases[host]; ok {
+		host = canonical
+	}
+	rule := domainRules[host]
+
+	p := canonicalPath(u.EscapedPath(), rule.CaseInsensitivePath)
+
+	var b strings.Builder
+	b.WriteString(scheme)
+	b.WriteString("://")
+	b.WriteString(host)
+	if port != "" {
+		b.WriteString(":")
+		b.WriteString(port)
+	}
+	b.WriteString(p)
+	if q := canonicalQuery(u.Query(), rule); q != "" {
+		b.WriteString("?")
+		b.WriteString(q)
+	}
+	return b.String()
+}
+
+// stripWWW 去除主机名的 www. 前缀
+func stripWWW(host string) string {
+	return strings.TrimPrefix(host, "www.")
+}
+
+// canonicalPath 规范化路径
+func canonicalPath(p string, lower bool) string {
+	if p == "" || p == "/" {
+		return ""
+	}
+	if lower {
+		p = strings.ToLower(p)
+	}
+	cleaned := path.Clean(p)
+	switch path.Base(cleaned) {
+	case "index.html", "index.htm", "index.php", "default.aspx":
+		cleaned = path.Dir(cleaned)
+	}
+	return strings.TrimSuffix(cleaned, "/")
+}
+
+// canonicalQuery 去除跟踪参数并按名称排序
+func canonicalQuery(values url.Values, rule DomainRule) string {
+	keep := make(map[string]bool, len(rule.KeepParams))
+	for _, k := range rule.KeepParams {
+		keep[k] = true
+	}
+	drop := make(map[string]bool, len(rule.DropParams))
+	for _, k := range rule.DropParams {
+		drop[k] = true
+	}
+
+	filtered := url.Values{}
+	for key, vals := range values {
+		lower := strings.ToLower(key)
+		if len(keep) > 0 && !keep[key] {
+			continue
+		}
+		if drop[key] || isTrackingParam(lower) {
+			continue
+		}
+		sorted := append([]string(nil), vals...)
+		sort.Strings(sorted)
+		filtered[key] = sorted
+	}
+	// url.Values.Encode 按键名排序输出
+	return filtered.Encode()
+}
+
+// isTrackingParam 判断查询参数是否为跟踪参数
+func isTrackingParam(key string) bool {
+	if trackingParams[key] {
+		return true
+	}
+	for _, prefix := range trackingParamPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package search
+
+import "time"
+
+// Config 搜索模块配置
+type Config struct {
+	BufferTTLHours    int `koanf:"search_buffer_ttl_hours"`     // 缓冲区记录默认保留时长（小时）
+	BufferMaxTTLHours int `koanf:"search_buffer_max_ttl_hours"` // 延期后距当前时间的最长保留时长（小时）
+	ArchiveLimit      int `koanf:"search_archive_limit"`        // 每个用户可归档的会话数上限
+	MonitorLimit      int `koanf:"search_monitor_limit"`        // 每个用户可创建的监听任务数上限
+
+	// 文件批量导入：上传的表格或题录解析后写入缓冲区
+	ImportMaxSizeMB int `koanf:"search_import_max_size_mb"` // 上传文件的大小上限（MB）
+	ImportMaxRows   int `koanf:"search_import_max_rows"`    // 单个文件的记录数上限
+
+	// 原网页正文抽取：数据源只提供摘要时抓取结果链接并抽取正文、作者、语言等
+	PageFetchEnabled        bool `koanf:"search_page_fetch_enabled"`
+	PageFetchTimeoutSeconds int  `koanf:"search_page_fetch_timeout_seconds"` // 单个网页的下载超时（秒）
+	PageFetchConcurrency    int  `koanf:"search_page_fetch_concurrency"`     // 同时抓取的网页数量
+	PageMaxSizeKB           int  `koanf:"search_page_max_size_kb"`           // 单个网页读取的最大字节数（KB），超出部分丢弃
+
+	// 模型计价：每次检索费用 = 基础价 + 单条价 × 结果条数
+	PriceBasic             int64 `koanf:"search_price_basic"`
+	PriceAdvanced          int64 `koanf:"search_price_advanced"`
+	PricePro               int64 `koanf:"search_price_pro"`
+	PricePerResultBasic    int64 `koanf:"search_price_per_result_basic"`
+	PricePerResultAdvanced int64 `koanf:"search_price_per_result_advanced"`
+	PricePerResultPro      int64 `koanf:"search_price_per_result_pro"`
+}
+
+// DefaultConfig 返回搜索模块的默认配置
+func DefaultConfig() Config {
+	return Config{
+		BufferTTLHours:    24,      // 默认保留 24 小时
+		BufferMaxTTLHours: 30 * 24, // 最多延期至 30 天后
+		ArchiveLimit:      20,      // 每人最多归档 20 个会话
+		MonitorLimit:      20,      // 每人最多 20 个监听任务
+
+		ImportMaxSizeMB: 10,
+		ImportMaxRows:   2000,
+
+		PageFetchEnabled:        true,
+		PageFetchTimeoutSeconds: 15,
+		PageFetchConcurrency:    8,
+		PageMaxSizeKB:           2048,
+
+		PriceBasic:             0,  // 基础模型免费
+		PriceAdvanced:          10, // 高级模型每次 10 积分
+		PricePro:               20, // 专业模型每次 20 积分
+		PricePerResultBasic:    0,
+		PricePerResultAdvanced: 0,
+		PricePerResultPro:      1, // 专业模型另按结果条数每条 1 积分
+	}
+}
+
+// 检索模型档位
+const (
+	ModelBasic    = "basic"
+	ModelAdvanced = "advanced"
+	ModelPro      = "pro"
+)
+
+// ModelPrice 某个模型档位的价格
+type ModelPrice struct {
+	Model     string `json:"model"`
+	Base      int64  `json:"base"`       // 每次检索的基础费用
+	PerResult int64  `json:"per_result"` // 每条结果的费用
+}
+
+// Cost 计算返回 results 条结果时的费用
+func (p ModelPrice) Cost(results int) int64 {
+	return p.Base + p.PerResult*int64(results)
+}
+
+// Price 返回模型档位的价格，未知档位按 basic 计价
+func (c Config) Price(model string) ModelPrice {
+	switch model {
+	case ModelAdvanced:
+		return ModelPrice{Model: ModelAdvanced, Base: c.PriceAdvanced, PerResult: c.PricePerResultAdvanced}
+	case ModelPro:
+		return ModelPrice{Model: ModelPro, Base: c.PricePro, PerResult: c.PricePerResultPro}
+	default:
+		return ModelPrice{Model: ModelBasic, Base: c.PriceBasic, PerResult: c.PricePerResultBasic}
+	}
+}
+
+// Pricing 返回全部模型档位的价格表
+func (c Config) Pricing() []ModelPrice {
+	return []ModelPrice{c.Price(ModelBasic), c.Price(ModelAdvanced), c.Price(ModelPro)}
+}
+
+// bufferTTL 缓冲区默认保留时长
+func (c Config) bufferTTL() time.Duration {
+	return time.Duration(c.BufferTTLHours) * time.Hour
+}
+
+// maxBufferTTL 延期允许的最长保留时长
+func (c Config) maxBufferTTL() time.Duration {
+	return time.Duration(c.BufferMaxTTLHours) * time.Hour
+}
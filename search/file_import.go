@@ -0,0 +1,415 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"policy-backend/importer"
+	"policy-backend/user"
+	"policy-backend/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// 文件导入参数
+const (
+	fileImportPreviewSize = 20  // 试运行返回的转换结果条数
+	maxColumnMapsPerUser  = 50  // 每个用户可保存的列映射数
+	maxImportQueryLen     = 500 // 会话 query 字段（记录文件名）的长度上限
+)
+
+// ImportFile 上传表格或题录文件，解析后写入新的搜索会话缓冲区
+// POST /api/search/import/file
+// multipart 参数：file（必填）、format（csv/xlsx/ris/bibtex，缺省按扩展名识别）、
+// map_id（已保存的列映射）、column_map（JSON 形式的临时列映射，优先于 map_id）、dry_run（只解析不写入）。
+// 未指定映射的字段按列名自动识别；写入缓冲区时与检索结果一样按 DataHash 与内容指纹查重，
+// 之后通过 POST /api/search/import 走审核入库流程
+func (h *Handler) ImportFile(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "File is required")
+	}
+	maxSize := int64(h.cfg.ImportMaxSizeMB) << 20
+	if fileHeader.Size > maxSize {
+		return utils.Fail(c, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("File exceeds size limit (%d MB)", h.cfg.ImportMaxSizeMB))
+	}
+
+	format, err := importer.DetectFormat(fileHeader.Filename, c.FormValue("format"))
+	if err != nil {
+		return utils.Fail(c, http.StatusBadRequest,
+			"Unsupported file format, expected one of: "+strings.Join(importer.Formats, ", "))
+	}
+
+	data, err := readUpload(fileHeader, maxSize)
+	if err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Failed to read file")
+	}
+	table, err := importer.Parse(format, data, h.cfg.ImportMaxRows)
+	if err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Failed to parse file: "+err.Error())
+	}
+
+	mapping, err := h.resolveColumnMap(c, currentUser.ID, table.Columns)
+	if mapping == nil {
+		return err
+	}
+	if mapping[importer.FieldTitle] == "" {
+		return utils.FailWithData(c, http.StatusBadRequest, "No title column found, specify a column map",
+			map[string]interface{}{"columns": table.Columns, "mapping": mapping})
+	}
+
+	result := &FileImportResult{
+		Format:         format,
+		Columns:        table.Columns,
+		Mapping:        mapping,
+		MissingColumns: mapping.Missing(table.Columns),
+		TotalRows:      len(table.Records),
+		Errors:         []FileImportRowError{},
+	}
+	items, raws := convertRecords(table, mapping, result)
+
+	dryRun, _ := strconv.ParseBool(c.FormValue("dry_run"))
+	if dryRun {
+		result.BufferedCount = len(raws)
+		if len(items) > fileImportPreviewSize {
+			items = items[:fileImportPreviewSize]
+		}
+		result.Preview = items
+		return utils.Success(c, result)
+	}
+	if len(raws) == 0 {
+		return utils.FailWithData(c, http.StatusUnprocessableEntity, "No valid records in file", result)
+	}
+
+	session, err := h.bufferImportedRecords(currentUser.ID, fileHeader.Filename, raws)
+	if err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to save records to buffer")
+	}
+	summary, err := h.sessionSummary(session.ID)
+	if err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to summarize session")
+	}
+	result.Session = session
+	result.BufferedCount = len(raws)
+	result.Summary = &summary
+	return utils.Success(c, result)
+}
+
+// readUpload 读取上传的文件，超过 maxSize 时返回错误
+func readUpload(fileHeader *multipart.FileHeader, maxSize int64) ([]byte, error) {
+	f, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, errors.New("file exceeds size limit")
+	}
+	return data, nil
+}
+
+// resolveColumnMap 确定本次导入使用的列映射：自动识别的结果依次被 map_id 指定的已保存映射、
+// column_map 中的临时映射覆盖；参数有误时返回 nil 映射并写入错误响应
+func (h *Handler) resolveColumnMap(c echo.Context, userID uint, columns []string) (importer.Mapping, error) {
+	mapping := importer.DetectMapping(columns)
+
+	if idStr := c.FormValue("map_id"); idStr != "" {
+		var saved ImportColumnMap
+		if err := h.db.Where("id = ? AND user_id = ?", idStr, userID).First(&saved).Error; err != nil {
+			return nil, utils.Fail(c, http.StatusNotFound, "Column map not found")
+		}
+		mapping = mapping.Merge(saved.ColumnMapping())
+	}
+
+	if raw := c.FormValue("column_map"); raw != "" {
+		var adHoc importer.Mapping
+		if err := json.Unmarshal([]byte(raw), &adHoc); err != nil {
+			return nil, utils.Fail(c, http.StatusBadRequest, "column_map must be a JSON object of field to column name")
+		}
+		if err := adHoc.Validate(); err != nil {
+			return nil, utils.Fail(c, http.StatusBadRequest, "Invalid column_map: "+err.Error())
+		}
+		mapping = mapping.Merge(adHoc)
+	}
+	return mapping, nil
+}
+
+// convertRecords 按列映射转换全部记录，返回转换结果及可写入缓冲区的原始数据
+// 转换失败、未通过入库校验（与 POST /api/search/import 相同的规则）或与文件中前面的记录重复的行记入 result.Errors
+func convertRecords(table *importer.Table, mapping importer.Mapping, result *FileImportResult) ([]importer.Item, []map[string]interface{}) {
+	var (
+		items []importer.Item
+		raws  []map[string]interface{}
+	)
+	firstRow := make(map[string]int) // DataHash → 首次出现的行
+	for i, rec := range table.Records {
+		row := table.Rows[i]
+		skip := func(reason string, errs []ImportFieldError) {
+			result.SkippedCount++
+			result.Errors = append(result.Errors, FileImportRowError{Row: row, Reason: reason, Errors: errs})
+		}
+
+		item, err := mapping.Apply(rec)
+		if err != nil {
+			var fieldErr *importer.FieldError
+			if errors.As(err, &fieldErr) {
+				errs := []ImportFieldError{{Field: fieldErr.Field, Message: fieldErr.Message}}
+				skip(fieldErrorsReason(errs), errs)
+			} else {
+				skip(err.Error(), nil)
+			}
+			continue
+		}
+
+		raw := itemToRaw(item)
+		rawJSON, err := json.Marshal(raw)
+		if err != nil {
+			skip(err.Error(), nil)
+			continue
+		}
+		if _, errs := mapBuffer(&SearchBuffer{RawData: rawJSON}); len(errs) > 0 {
+			skip(fieldErrorsReason(errs), errs)
+			continue
+		}
+
+		_, dataHash := dataHashFor(item.URL, item.Title)
+		if prev, ok := firstRow[dataHash]; ok {
+			skip(fmt.Sprintf("Duplicate of row %d in the same file", prev), nil)
+			continue
+		}
+		firstRow[dataHash] = row
+
+		items = append(items, item)
+		raws = append(raws, raw)
+	}
+	return items, raws
+}
+
+// itemToRaw 转换为与 SearchProvider 归一化结果相同结构的原始数据
+func itemToRaw(item importer.Item) map[string]interface{} {
+	raw := map[string]interface{}{
+		"title":   item.Title,
+		"url":     item.URL,
+		"source":  item.Source,
+		"content": item.Content,
+		"summary": item.Summary,
+	}
+	if item.PublishDate != nil {
+		raw["publish_date"] = item.PublishDate.Format(time.RFC3339)
+	}
+	if item.PDFURL != "" {
+		raw["pdf_url"] = item.PDFURL
+	}
+	if item.Keywords != "" {
+		raw["keywords"] = item.Keywords
+	}
+	return raw
+}
+
+// bufferImportedRecords 创建来源为 import 的搜索会话并逐条写入缓冲区
+func (h *Handler) bufferImportedRecords(userID uint, filename string, raws []map[string]interface{}) (*SearchSession, error) {
+	query := filename
+	if r := []rune(query); len(r) > maxImportQueryLen {
+		query = string(r[:maxImportQueryLen])
+	}
+
+	expireAt := time.Now().Add(h.cfg.bufferTTL())
+	session := SearchSession{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		Query:      query,
+		Source:     SessionSourceImport,
+		State:      SessionStateRunning,
+		TotalCount: len(raws),
+		ExpireAt:   &expireAt,
+	}
+	if err := h.db.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	for i, raw := range raws {
+		if _, err := h.saveToBuffer(session.ID, userID, raw); err != nil {
+			h.failSession(session.ID, err)
+			return nil, err
+		}
+		if (i+1)%100 == 0 {
+			h.updateSession(session.ID, map[string]interface{}{"fetched_count": i + 1})
+		}
+	}
+
+	now := time.Now()
+	h.updateSession(session.ID, map[string]interface{}{
+		"fetched_count": len(raws),
+		"state":         SessionStateDone,
+		"finished_at":   &now,
+	})
+	if err := h.db.First(&session, "id = ?", session.ID).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// findUserColumnMap 查找当前用户的列映射，不存在时返回 404 响应
+func (h *Handler) findUserColumnMap(c echo.Context) (*ImportColumnMap, error) {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return nil, utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var m ImportColumnMap
+	if err := h.db.Where("id = ? AND user_id = ?", c.Param("id"), currentUser.ID).First(&m).Error; err != nil {
+		return nil, utils.Fail(c, http.StatusNotFound, "Column map not found")
+	}
+	return &m, nil
+}
+
+// bindColumnMapRequest 解析并校验列映射请求
+func bindColumnMapRequest(c echo.Context) (*ColumnMapRequest, error) {
+	var req ColumnMapRequest
+	if err := c.Bind(&req); err != nil {
+		return nil, utils.Fail(c, http.StatusBadRequest, "Invalid parameters")
+	}
+	// 校验失败时 ValidateRequest 已写入响应，但返回的 error 可能为空
+	if err := utils.ValidateRequest(c, &req); err != nil || c.Response().Committed {
+		return nil, err
+	}
+	if err := importer.Mapping(req.Mapping).Validate(); err != nil {
+		return nil, utils.Fail(c, http.StatusBadRequest, "Invalid mapping: "+err.Error())
+	}
+	return &req, nil
+}
+
+// apply 将请求内容写入列映射
+func (req *ColumnMapRequest) apply(m *ImportColumnMap) error {
+	mapping, err := json.Marshal(req.Mapping)
+	if err != nil {
+		return err
+	}
+	m.Name = strings.TrimSpace(req.Name)
+	m.Format = req.Format
+	m.Mapping = mapping
+	return nil
+}
+
+// ListColumnMaps 获取当前用户保存的列映射
+// GET /api/search/import/maps
+func (h *Handler) ListColumnMaps(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var maps []ImportColumnMap
+	if err := h.db.Where("user_id = ?", currentUser.ID).Order("name ASC").Find(&maps).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to get column maps")
+	}
+
+	return utils.Success(c, map[string]interface{}{
+		"count":  len(maps),
+		"maps":   maps,
+		"fields": importer.Fields,
+	})
+}
+
+// CreateColumnMap 保存列映射
+// POST /api/search/import/maps
+func (h *Handler) CreateColumnMap(c echo.Context) error {
+	req, err := bindColumnMapRequest(c)
+	if req == nil {
+		return err
+	}
+
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var count int64
+	if err := h.db.Model(&ImportColumnMap{}).Where("user_id = ?", currentUser.ID).Count(&count).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to count column maps")
+	}
+	if count >= maxColumnMapsPerUser {
+		return utils.Fail(c, http.StatusForbidden,
+			fmt.Sprintf("Column map limit reached (%d maps), delete a map first", maxColumnMapsPerUser))
+	}
+
+	m := ImportColumnMap{UserID: currentUser.ID}
+	if err := req.apply(&m); err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid mapping")
+	}
+	if h.columnMapNameTaken(currentUser.ID, m.Name, 0) {
+		return utils.Fail(c, http.StatusConflict, "A column map with this name already exists")
+	}
+	if err := h.db.Create(&m).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to create column map")
+	}
+
+	return utils.Success(c, m)
+}
+
+// UpdateColumnMap 修改列映射
+// PUT /api/search/import/maps/:id
+func (h *Handler) UpdateColumnMap(c echo.Context) error {
+	req, err := bindColumnMapRequest(c)
+	if req == nil {
+		return err
+	}
+
+	m, err := h.findUserColumnMap(c)
+	if m == nil {
+		return err
+	}
+
+	if err := req.apply(m); err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid mapping")
+	}
+	if h.columnMapNameTaken(m.UserID, m.Name, m.ID) {
+		return utils.Fail(c, http.StatusConflict, "A column map with this name already exists")
+	}
+	if err := h.db.Save(m).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to update column map")
+	}
+
+	return utils.Success(c, m)
+}
+
+// DeleteColumnMap 删除列映射
+// DELETE /api/search/import/maps/:id
+func (h *Handler) DeleteColumnMap(c echo.Context) error {
+	m, err := h.findUserColumnMap(c)
+	if m == nil {
+		return err
+	}
+
+	if err := h.db.Delete(m).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to delete column map")
+	}
+
+	return utils.Success(c, nil)
+}
+
+// columnMapNameTaken 用户是否已有同名的其他列映射
+func (h *Handler) columnMapNameTaken(userID uint, name string, excludeID uint) bool {
+	var existing ImportColumnMap
+	err := h.db.Where("user_id = ? AND name = ? AND id <> ?", userID, name, excludeID).First(&existing).Error
+	return !errors.Is(err, gorm.ErrRecordNotFound)
+}
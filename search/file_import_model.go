@@ -0,0 +1,65 @@
+package search
+
+import (
+	"encoding/json"
+	"policy-backend/importer"
+	"time"
+)
+
+// SessionSourceImport 文件导入创建的搜索会话的来源（Source 字段）
+const SessionSourceImport = "import"
+
+// ImportColumnMap 用户保存的列映射，导入文件时按 map_id 引用
+// Mapping 为 “情报字段 → 文件列名” 的 JSON 对象，字段见 importer.Fields
+type ImportColumnMap struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`
+	UserID    uint            `gorm:"uniqueIndex:idx_import_map_name;comment:创建者ID" json:"user_id"`
+	Name      string          `gorm:"type:varchar(100);uniqueIndex:idx_import_map_name;comment:映射名称" json:"name"`
+	Format    string          `gorm:"type:varchar(10);comment:适用的文件格式，为空表示不限" json:"format,omitempty"`
+	Mapping   json.RawMessage `gorm:"type:json;comment:情报字段到文件列名的映射" json:"mapping"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ImportColumnMap) TableName() string {
+	return "import_column_maps"
+}
+
+// ColumnMapping 解析保存的列映射
+func (m *ImportColumnMap) ColumnMapping() importer.Mapping {
+	mapping := importer.Mapping{}
+	if len(m.Mapping) > 0 {
+		_ = json.Unmarshal(m.Mapping, &mapping)
+	}
+	return mapping
+}
+
+// ColumnMapRequest 创建/修改列映射请求
+type ColumnMapRequest struct {
+	Name    string            `json:"name" validate:"required,max=100"`
+	Format  string            `json:"format" validate:"omitempty,oneof=csv xlsx ris bibtex"`
+	Mapping map[string]string `json:"mapping" validate:"required,min=1"`
+}
+
+// FileImportRowError 未写入缓冲区的记录及原因
+type FileImportRowError struct {
+	Row    int                `json:"row"` // 表格为行号（含表头），题录为条目序号
+	Reason string             `json:"reason"`
+	Errors []ImportFieldError `json:"errors,omitempty"`
+}
+
+// FileImportResult 文件导入结果
+type FileImportResult struct {
+	Session        *SearchSession       `json:"session,omitempty"` // 试运行时为空
+	Format         string               `json:"format"`
+	Columns        []string             `json:"columns"`                   // 文件中的列名
+	Mapping        importer.Mapping     `json:"mapping"`                   // 实际使用的列映射
+	MissingColumns []string             `json:"missing_columns,omitempty"` // 映射中引用但文件中不存在的列
+	TotalRows      int                  `json:"total_rows"`
+	BufferedCount  int                  `json:"buffered_count"` // 写入缓冲区（或试运行时可写入）的条数
+	SkippedCount   int                  `json:"skipped_count"`
+	Errors         []FileImportRowError `json:"errors"`
+	Summary        *SessionSummary      `json:"summary,omitempty"` // 写入后的查重统计
+	Preview        []importer.Item      `json:"preview,omitempty"` // 试运行时返回前若干条转换结果
+}
@@ -1,11 +1,16 @@
 package search
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"log"
 	"net/http"
 	"policy-backend/intelligence"
+	"policy-backend/llm"
+	"policy-backend/org"
 	"policy-backend/user"
 	"policy-backend/utils"
 	"time"
@@ -18,19 +23,50 @@ import (
 // Handler 搜索处理器
 type Handler struct {
 	db            *gorm.DB
+	cfg           Config
 	pointsService *user.PointsTransactionService
+	providers     *ProviderRegistry
+	fullText      intelligence.FullTextIndex
+	resolver      *org.Resolver
+	pdf           *intelligence.PDFStore
+	pages         *pageFetcher // 为 nil 时不抓取原网页
+	llm           *llm.Client  // 按检索档位精炼结果，为 nil 时不调用大模型
+	jobs          chan searchJob
+	events        *sessionBroker
 }
 
 // NewHandler 创建新的搜索处理器
-func NewHandler(db *gorm.DB, pointsService *user.PointsTransactionService) *Handler {
-	return &Handler{
+// 默认注册内置的 RSS/Atom 数据源，并启动后台检索工作池；pdf 用于导入时抓取 PDF 原文，
+// llmClient 按检索请求的 model 档位过滤结果并生成中文摘要
+func NewHandler(db *gorm.DB, pointsService *user.PointsTransactionService, cfg *Config, pdf *intelligence.PDFStore, llmClient *llm.Client) *Handler {
+	providers := NewProviderRegistry()
+	providers.Register(NewRSSProvider(db, nil))
+
+	h := &Handler{
 		db:            db,
+		cfg:           *cfg,
 		pointsService: pointsService,
+		providers:     providers,
+		fullText:      intelligence.DetectFullTextIndex(db),
+		resolver:      org.NewResolver(db),
+		pdf:           pdf,
+		pages:         newPageFetcher(*cfg),
+		llm:           llmClient,
+		jobs:          make(chan searchJob, searchQueueSize),
+		events:        newSessionBroker(),
 	}
+	h.startWorkers()
+	return h
+}
+
+// RegisterProvider 注册额外的检索数据源
+func (h *Handler) RegisterProvider(p SearchProvider) {
+	h.providers.Register(p)
 }
 
 // GlobalSearch 全网智能检索
 // GET /api/search/global
+// 立即创建搜索会话并返回，抓取在后台执行；scope=local 时转为库内检索
 func (h *Handler) GlobalSearch(c echo.Context) error {
 	var req SearchRequest
 	if err := c.Bind(&req); err != nil {
@@ -47,128 +83,181 @@ func (h *Handler) GlobalSearch(c echo.Context) error {
 		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
 	}
 
-	// 1. 生成会话ID
-	sessionID := uuid.New().String()
+	return h.runSearch(c, currentUser.ID, req)
+}
 
-	// 2. 调用搜索（占位实现，实际应调用爬虫服务）
-	rawResults := h.performSearch(req)
+// runSearch 按检索范围执行检索并记录搜索历史，供检索接口和历史重跑共用
+func (h *Handler) runSearch(c echo.Context, userID uint, req SearchRequest) error {
+	if req.Scope == "" {
+		req.Scope = "global"
+	}
 
-	// 3. 创建搜索会话记录
-	session := SearchSession{
-		ID:         sessionID,
-		UserID:     currentUser.ID,
-		Query:      req.Q,
-		Source:     req.Scope,
-		TotalCount: len(rawResults),
+	// 库内检索：只查询 intelligences 表，不消耗积分、不创建缓冲区
+	if req.Scope == "local" {
+		return h.LocalSearch(c, userID, req)
 	}
-	if err := h.db.Create(&session).Error; err != nil {
-		return utils.Error(c, http.StatusInternalServerError, "Failed to create search session")
+
+	session, err := h.startGlobalSearch(userID, req, 0)
+	if err != nil {
+		return failStartSearch(c, err)
 	}
 
-	// 4. 将结果存入缓冲区
-	bufferIDs := []uint{}
-	for _, raw := range rawResults {
-		bufferID, err := h.saveToBuffer(sessionID, currentUser.ID, raw)
-		if err != nil {
-			return utils.Error(c, http.StatusInternalServerError, "Failed to save search result to buffer")
-		}
-		bufferIDs = append(bufferIDs, bufferID)
+	return utils.Success(c, map[string]interface{}{
+		"session_id":      session.ID,
+		"query":           session.Query,
+		"scope":           session.Source,
+		"model":           session.Model,
+		"state":           session.State,
+		"reserved_points": session.ReservedPoints,
+		"stream_url":      "/api/search/sessions/" + session.ID + "/stream",
+	})
+}
+
+// startGlobalSearch 冻结积分、创建搜索会话并交给后台工作池抓取，返回排队中的会话
+// monitorID 不为 0 时表示由监听任务发起，此时不记录搜索历史
+func (h *Handler) startGlobalSearch(userID uint, req SearchRequest, monitorID uint) (*SearchSession, error) {
+	if req.Scope == "" {
+		req.Scope = "global"
+	}
+	if req.Model == "" {
+		req.Model = ModelBasic
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultGlobalLimit
 	}
 
-	// 5. 只返回预览数据给前端
-	previews := []*SearchBufferPreview{}
-	for _, id := range bufferIDs {
-		var buffer SearchBuffer
-		if err := h.db.First(&buffer, id).Error; err == nil {
-			previews = append(previews, buffer.ToPreview())
-		}
+	// 1. 检查并冻结积分，余额不足时直接拒绝，不进入检索
+	job := searchJob{SessionID: uuid.New().String(), UserID: userID, Request: req, MonitorID: monitorID}
+	reservation, err := h.reserveSearch(job)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errReservePoints, err)
 	}
 
-	// 6. 扣除积分（如果使用高级模型）
-	if req.Model == "advanced" {
-		if err := h.deductPoints(currentUser.ID, 10); err != nil {
-			return utils.Error(c, http.StatusInternalServerError, "Failed to deduct points")
+	// 2. 创建搜索会话记录（排队中）
+	expireAt := time.Now().Add(h.cfg.bufferTTL())
+	session := SearchSession{
+		ID:       job.SessionID,
+		UserID:   userID,
+		Query:    req.Q,
+		Source:   req.Scope,
+		Model:    req.Model,
+		State:    SessionStateQueued,
+		ExpireAt: &expireAt,
+	}
+	if monitorID != 0 {
+		session.MonitorID = &monitorID
+	}
+	if reservation != nil {
+		session.ReservationID = &reservation.ID
+		session.ReservedPoints = reservation.Amount
+		job.ReservationID = reservation.ID
+	}
+	if err := h.db.Create(&session).Error; err != nil {
+		if reservation != nil {
+			_ = h.pointsService.Release(reservation.ID, "检索失败退回", user.PointsMetadata{"session_id": session.ID})
 		}
+		return nil, err
 	}
 
-	return utils.Success(c, map[string]interface{}{
-		"session_id": sessionID,
-		"query":      req.Q,
-		"scope":      req.Scope,
-		"model":      req.Model,
-		"count":      len(previews),
-		"results":    previews,
-	})
+	// 记录搜索历史，结果条数与费用在会话结束后回填
+	if monitorID == 0 {
+		h.recordHistory(userID, session.ID, req, 0, 0)
+	}
+
+	// 3. 交给后台工作池抓取，结果通过 SSE 或缓冲区列表接口获取
+	if err := h.enqueueSearch(job); err != nil {
+		h.failSession(session.ID, err)
+		return nil, err
+	}
+
+	return &session, nil
 }
 
-// performSearch 执行搜索（占位实现）
-// 实际应调用爬虫或搜索服务
-func (h *Handler) performSearch(req SearchRequest) []map[string]interface{} {
-	// 占位：返回模拟的搜索结果
-	results := []map[string]interface{}{
-		{
-			"title":        "量子计算在金融领域的应用",
-			"source":       "科技日报",
-			"url":          "https://example.com/1",
-			"content":      "量子计算正逐步应用于金融领域的风险评估和投资组合优化...",
-			"publish_date": time.Now().Add(-24 * time.Hour).Format(time.RFC3339),
-		},
-		{
-			"title":        "最新量子算法研究进展",
-			"source":       "学术期刊",
-			"url":          "https://example.com/2",
-			"content":      "研究团队开发了一种新的量子算法，可显著提升计算效率...",
-			"publish_date": time.Now().Add(-48 * time.Hour).Format(time.RFC3339),
-		},
-		{
-			"title":        "量子计算机商业化前景分析",
-			"source":       "行业报告",
-			"url":          "https://example.com/3",
-			"content":      "多家科技公司宣布量子计算机商业化计划，预计未来5年将进入实用阶段...",
-			"publish_date": time.Now().Add(-72 * time.Hour).Format(time.RFC3339),
-		},
-	}
-	return results
+// performSearch 执行搜索，分发到已注册的数据源，返回结果与大模型的 token 用量
+func (h *Handler) performSearch(ctx context.Context, req SearchRequest) ([]map[string]interface{}, llm.Usage, error) {
+	results, err := h.providers.Search(ctx, req)
+	if err != nil {
+		return nil, llm.Usage{}, err
+	}
+	// 抓取原网页补全正文、作者与语言
+	if h.pages != nil {
+		h.pages.enrich(ctx, results)
+	}
+	// 按检索档位（model）调用对应的大模型过滤并摘要
+	results, usage := h.refineResults(ctx, req.Model, req.Q, results)
+	return results, usage, nil
 }
 
 // saveToBuffer 将搜索结果存入缓冲区
 func (h *Handler) saveToBuffer(sessionID string, userID uint, rawData map[string]interface{}) (uint, error) {
 	// 1. 计算内容哈希（用于查重）
-	dataHash := h.calculateHash(rawData)
+	canonicalURL, dataHash := h.calculateHash(rawData)
 
-	// 2. 查重检测
+	// 2. 提取预览字段
+	// 抽取过正文的结果以原摘要作为预览摘要，否则 content 本身就是摘要
+	title, _ := rawData["title"].(string)
+	source, _ := rawData["source"].(string)
+	content, _ := rawData["content"].(string)
+	summary, _ := rawData["summary"].(string)
+	if summary == "" {
+		summary = content
+	}
+	publishDateStr, _ := rawData["publish_date"].(string)
+	publishDate, _ := time.Parse(time.RFC3339, publishDateStr)
+	url, _ := rawData["url"].(string)
+	fingerprint := intelligence.NewContentFingerprint(title, content)
+
+	// 识别机构与国家，失败不影响入缓冲区
+	resolution, err := h.resolver.Resolve(url, source, title)
+	if err != nil {
+		log.Printf("Failed to resolve agency for %s: %v\n", url, err)
+	}
+
+	// 3. 查重检测：先按 DataHash 精确查重，未命中再按内容指纹查找近似重复
 	duplicateStatus := "new"
+	var similarJSON json.RawMessage
 	var existingIntelligence intelligence.Intelligence
 	if err := h.db.Where("data_hash = ?", dataHash).First(&existingIntelligence).Error; err == nil {
 		duplicateStatus = "exists"
+	} else {
+		matches, err := intelligence.FindSimilar(h.db, fingerprint)
+		if err != nil {
+			return 0, err
+		}
+		if len(matches) > 0 {
+			duplicateStatus = "similar"
+			if similarJSON, err = json.Marshal(matches); err != nil {
+				return 0, err
+			}
+		}
 	}
 
-	// 3. 序列化原始数据
+	// 4. 序列化原始数据
 	rawJSON, err := json.Marshal(rawData)
 	if err != nil {
 		return 0, err
 	}
 
-	// 4. 提取预览字段
-	title, _ := rawData["title"].(string)
-	source, _ := rawData["source"].(string)
-	summary, _ := rawData["content"].(string)
-	publishDateStr, _ := rawData["publish_date"].(string)
-	publishDate, _ := time.Parse(time.RFC3339, publishDateStr)
-
 	// 5. 创建缓冲区记录
 	buffer := SearchBuffer{
-		SessionID:       sessionID,
-		UserID:          userID,
-		RawData:         rawJSON,
-		PreviewTitle:    title,
-		PreviewSource:   source,
-		PreviewDate:     publishDate,
-		PreviewSummary:  summary,
-		DataHash:        dataHash,
-		DuplicateStatus: duplicateStatus,
-		Status:          "pending",
-		ExpireAt:        time.Now().Add(24 * time.Hour), // 24小时后过期
+		SessionID:          sessionID,
+		UserID:             userID,
+		RawData:            rawJSON,
+		PreviewTitle:       title,
+		PreviewSource:      source,
+		PreviewDate:        publishDate,
+		PreviewSummary:     summary,
+		AgencyID:           resolution.AgencyID,
+		CountryID:          resolution.CountryID,
+		AgencyMatch:        resolution.MatchedBy,
+		CanonicalURL:       canonicalURL,
+		DataHash:           dataHash,
+		HashVersion:        dataHashVersion,
+		ContentFingerprint: fingerprint,
+		DuplicateStatus:    duplicateStatus,
+		SimilarMatches:     similarJSON,
+		Status:             BufferStatusPending,
+		ExpireAt:           h.bufferExpireAt(sessionID),
 	}
 
 	if err := h.db.Create(&buffer).Error; err != nil {
@@ -178,137 +267,69 @@ func (h *Handler) saveToBuffer(sessionID string, userID uint, rawData map[string
 	return buffer.ID, nil
 }
 
-// calculateHash 计算数据哈希
-func (h *Handler) calculateHash(data map[string]interface{}) string {
-	// 使用 URL 或标题计算哈希
+// calculateHash 计算数据哈希，返回规范 URL 与哈希值
+func (h *Handler) calculateHash(data map[string]interface{}) (string, string) {
 	url, _ := data["url"].(string)
-	if url == "" {
-		title, _ := data["title"].(string)
-		url = title
-	}
-	hash := md5.Sum([]byte(url))
-	return hex.EncodeToString(hash[:])
-}
-
-// deductPoints 扣除积分
-func (h *Handler) deductPoints(userID uint, points int64) error {
-	return h.pointsService.AddTransaction(
-		userID,
-		-points,
-		"spend",
-		"使用高级搜索模型",
-		`{"model": "advanced"}`,
-	)
+	title, _ := data["title"].(string)
+	return dataHashFor(url, title)
 }
 
-// ImportIntelligences 从缓冲区导入情报到正式库
-// POST /api/search/import
-func (h *Handler) ImportIntelligences(c echo.Context) error {
-	var req ImportIntelligenceRequest
-	if err := c.Bind(&req); err != nil {
-		return utils.Fail(c, http.StatusBadRequest, "Invalid parameters")
-	}
-
-	if err := utils.ValidateRequest(c, &req); err != nil {
-		return err
+// dataHashFor 使用规范 URL 计算哈希，URL 为空时退化为标题
+func dataHashFor(url, title string) (string, string) {
+	canonicalURL := CanonicalizeURL(url)
+	key := canonicalURL
+	if key == "" {
+		key = title
 	}
+	hash := md5.Sum([]byte(key))
+	return canonicalURL, hex.EncodeToString(hash[:])
+}
 
-	// 获取当前用户
+// GetSearchSessions 获取用户的搜索会话记录
+// GET /api/search/sessions
+// 支持分页，默认每页 20 条
+func (h *Handler) GetSearchSessions(c echo.Context) error {
 	currentUser, ok := c.Get("user").(*user.User)
 	if !ok {
 		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
 	}
 
-	// 1. 验证缓冲区记录是否属于当前用户
-	var buffers []SearchBuffer
-	if err := h.db.Where("id IN ? AND user_id = ?", req.BufferIDs, currentUser.ID).Find(&buffers).Error; err != nil {
-		return utils.Error(c, http.StatusInternalServerError, "Failed to find buffer records")
+	var query PageQuery
+	if err := c.Bind(&query); err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid parameters")
 	}
-
-	if len(buffers) != len(req.BufferIDs) {
-		return utils.Fail(c, http.StatusForbidden, "Some buffer records do not belong to you or do not exist")
+	if err := utils.ValidateRequest(c, &query); err != nil {
+		return err
 	}
+	query.normalize(defaultSessionPageSize)
 
-	// 2. 导入到正式库
-	importedIDs := []uint{}
-	for _, buffer := range buffers {
-		if buffer.Status != "pending" {
-			continue // 跳过已处理的记录
-		}
-
-		// 解析原始数据
-		var rawData map[string]interface{}
-		if err := json.Unmarshal(buffer.RawData, &rawData); err != nil {
-			continue
-		}
-
-		// 创建情报记录
-		intelligence := intelligence.Intelligence{
-			Title:         buffer.PreviewTitle,
-			Content:       rawData["content"].(string),
-			Source:        buffer.PreviewSource,
-			URL:           rawData["url"].(string),
-			Summary:       buffer.PreviewSummary,
-			PublishDate:   buffer.PreviewDate,
-			DataHash:      buffer.DataHash,
-			ContributorID: currentUser.ID,
-			UserID:        currentUser.ID,
-		}
-
-		// 如果目标是团队
-		if req.TargetScope == "team" {
-			if req.TeamID == 0 {
-				return utils.Fail(c, http.StatusBadRequest, "Team ID is required for team scope")
-			}
-			intelligence.TeamID = &req.TeamID
-		}
-
-		if err := h.db.Create(&intelligence).Error; err != nil {
-			return utils.Error(c, http.StatusInternalServerError, "Failed to create intelligence")
-		}
+	db := h.db.Model(&SearchSession{}).Where("user_id = ?", currentUser.ID)
 
-		// 更新缓冲区状态
-		now := time.Now()
-		if err := h.db.Model(&buffer).Updates(map[string]interface{}{
-			"status":      "imported",
-			"imported_at": now,
-		}).Error; err != nil {
-			return utils.Error(c, http.StatusInternalServerError, "Failed to update buffer status")
-		}
-
-		importedIDs = append(importedIDs, intelligence.ID)
-	}
-
-	return utils.Success(c, map[string]interface{}{
-		"imported_count":   len(importedIDs),
-		"intelligence_ids": importedIDs,
-	})
-}
-
-// GetSearchSessions 获取用户的搜索会话记录
-// GET /api/search/sessions
-func (h *Handler) GetSearchSessions(c echo.Context) error {
-	currentUser, ok := c.Get("user").(*user.User)
-	if !ok {
-		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	var total int64
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to count search sessions")
 	}
 
 	var sessions []SearchSession
-	if err := h.db.Where("user_id = ?", currentUser.ID).
-		Order("created_at DESC").
-		Limit(20).
+	if err := db.Order("created_at DESC").
+		Offset(query.offset()).
+		Limit(query.PageSize).
 		Find(&sessions).Error; err != nil {
 		return utils.Error(c, http.StatusInternalServerError, "Failed to get search sessions")
 	}
 
 	return utils.Success(c, map[string]interface{}{
-		"count":    len(sessions),
-		"sessions": sessions,
+		"total":     total,
+		"page":      query.Page,
+		"page_size": query.PageSize,
+		"count":     len(sessions),
+		"sessions":  sessions,
 	})
 }
 
 // GetSessionBuffers 获取某个会话的缓冲区数据
 // GET /api/search/sessions/:id/buffers
+// 支持按状态、查重结果过滤，排序及分页
 func (h *Handler) GetSessionBuffers(c echo.Context) error {
 	sessionID := c.Param("id")
 
@@ -317,19 +338,52 @@ func (h *Handler) GetSessionBuffers(c echo.Context) error {
 		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
 	}
 
+	var query SessionBuffersQuery
+	if err := c.Bind(&query); err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid parameters")
+	}
+	if err := utils.ValidateRequest(c, &query); err != nil {
+		return err
+	}
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 {
+		query.PageSize = defaultBufferPageSize
+	}
+
 	// 验证会话是否属于当前用户
 	var session SearchSession
 	if err := h.db.Where("id = ? AND user_id = ?", sessionID, currentUser.ID).First(&session).Error; err != nil {
 		return utils.Fail(c, http.StatusNotFound, "Session not found")
 	}
 
+	db := h.db.Model(&SearchBuffer{}).Where("session_id = ?", sessionID)
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.DuplicateStatus != "" {
+		db = db.Where("duplicate_status = ?", query.DuplicateStatus)
+	}
+
+	var total int64
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to count buffer records")
+	}
+
 	var buffers []SearchBuffer
-	if err := h.db.Where("session_id = ?", sessionID).
-		Order("created_at ASC").
+	if err := db.Order(bufferOrder(query.Sort)).
+		Offset((query.Page - 1) * query.PageSize).
+		Limit(query.PageSize).
 		Find(&buffers).Error; err != nil {
 		return utils.Error(c, http.StatusInternalServerError, "Failed to get buffer records")
 	}
 
+	summary, err := h.sessionSummary(sessionID)
+	if err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to summarize session")
+	}
+
 	// 转换为预览格式
 	previews := []*SearchBufferPreview{}
 	for _, buffer := range buffers {
@@ -337,9 +391,13 @@ func (h *Handler) GetSessionBuffers(c echo.Context) error {
 	}
 
 	return utils.Success(c, map[string]interface{}{
-		"session": session,
-		"count":   len(previews),
-		"results": previews,
+		"session":   session,
+		"summary":   summary,
+		"total":     total,
+		"page":      query.Page,
+		"page_size": query.PageSize,
+		"count":     len(previews),
+		"results":   previews,
 	})
 }
 
@@ -360,8 +418,7 @@ func (h *Handler) CheckDuplication(c echo.Context) error {
 
 	// 检查URL列表
 	for _, url := range req.URLs {
-		hash := md5.Sum([]byte(url))
-		dataHash := hex.EncodeToString(hash[:])
+		_, dataHash := dataHashFor(url, "")
 
 		var existingIntelligence intelligence.Intelligence
 		if err := h.db.Where("data_hash = ?", dataHash).First(&existingIntelligence).Error; err == nil {
@@ -399,15 +456,35 @@ func (h *Handler) CheckDuplication(c echo.Context) error {
 		}
 	}
 
+	// 检查原文列表（近似重复）
+	for _, text := range req.Texts {
+		matches, err := intelligence.FindSimilar(h.db, intelligence.NewContentFingerprint(text))
+		if err != nil {
+			return utils.Error(c, http.StatusInternalServerError, "Failed to check duplication")
+		}
+
+		result := DuplicationResult{
+			Text:        text,
+			IsDuplicate: len(matches) > 0,
+			Similar:     matches,
+		}
+		if len(matches) > 0 {
+			result.ExistingID = matches[0].IntelligenceID
+			result.Title = matches[0].Title
+		}
+		results = append(results, result)
+	}
+
 	return utils.Success(c, map[string]interface{}{
 		"count":   len(results),
 		"results": results,
 	})
 }
 
-// CleanupExpiredBuffers 清理过期的缓冲区数据
+// CleanupExpiredBuffers 清理过期的缓冲区数据，已归档会话的缓冲区不清理
 // 此方法应通过定时任务调用
 func (h *Handler) CleanupExpiredBuffers() (int64, error) {
-	result := h.db.Where("expire_at < ?", time.Now()).Delete(&SearchBuffer{})
+	archived := h.db.Model(&SearchSession{}).Select("id").Where("archived_at IS NOT NULL")
+	result := h.db.Where("expire_at < ? AND session_id NOT IN (?)", time.Now(), archived).Delete(&SearchBuffer{})
 	return result.RowsAffected, result.Error
 }
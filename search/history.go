@@ -0,0 +1,233 @@
+package search
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"policy-backend/user"
+	"policy-backend/utils"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// 历史记录分页参数
+const (
+	defaultSessionPageSize = 20
+	defaultHistoryPageSize = 20
+	defaultTopQueryLimit   = 10
+	maxTopQueryLimit       = 50
+)
+
+// recordHistory 记录一次检索，失败只记日志，不影响检索本身
+func (h *Handler) recordHistory(userID uint, sessionID string, req SearchRequest, resultCount int, cost int64) {
+	filters, err := json.Marshal(req)
+	if err != nil {
+		log.Printf("Failed to encode search filters: %v\n", err)
+		return
+	}
+
+	history := SearchHistory{
+		UserID:      userID,
+		Query:       req.Q,
+		Scope:       req.Scope,
+		ModelType:   req.Model,
+		Filters:     filters,
+		SessionID:   sessionID,
+		ResultCount: resultCount,
+		Cost:        cost,
+	}
+	if err := h.db.Create(&history).Error; err != nil {
+		log.Printf("Failed to record search history: %v\n", err)
+	}
+}
+
+// updateHistoryResult 全网检索结束后回填结果条数与费用
+func (h *Handler) updateHistoryResult(sessionID string, resultCount int, cost int64) {
+	if err := h.db.Model(&SearchHistory{}).Where("session_id = ?", sessionID).
+		Updates(map[string]interface{}{"result_count": resultCount, "cost": cost}).Error; err != nil {
+		log.Printf("Failed to update search history for session %s: %v\n", sessionID, err)
+	}
+}
+
+// GetSearchHistory 分页获取搜索历史
+// GET /api/search/history
+func (h *Handler) GetSearchHistory(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var query HistoryQuery
+	if err := c.Bind(&query); err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid parameters")
+	}
+	if err := utils.ValidateRequest(c, &query); err != nil {
+		return err
+	}
+	query.normalize(defaultHistoryPageSize)
+
+	db := h.db.Model(&SearchHistory{}).Where("user_id = ?", currentUser.ID)
+	if query.Scope != "" {
+		db = db.Where("scope = ?", query.Scope)
+	}
+	if query.Q != "" {
+		db = db.Where("query LIKE ?", "%"+query.Q+"%")
+	}
+
+	var total int64
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to count search history")
+	}
+
+	var histories []SearchHistory
+	if err := db.Order("created_at DESC, id DESC").
+		Offset(query.offset()).
+		Limit(query.PageSize).
+		Find(&histories).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to get search history")
+	}
+
+	return utils.Success(c, map[string]interface{}{
+		"total":     total,
+		"page":      query.Page,
+		"page_size": query.PageSize,
+		"count":     len(histories),
+		"histories": histories,
+	})
+}
+
+// DeleteSearchHistory 删除单条搜索历史
+// DELETE /api/search/history/:id
+func (h *Handler) DeleteSearchHistory(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	result := h.db.Where("id = ? AND user_id = ?", c.Param("id"), currentUser.ID).Delete(&SearchHistory{})
+	if result.Error != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to delete search history")
+	}
+	if result.RowsAffected == 0 {
+		return utils.Fail(c, http.StatusNotFound, "Search history not found")
+	}
+
+	return utils.Success(c, nil)
+}
+
+// ClearSearchHistory 清空当前用户的搜索历史
+// DELETE /api/search/history
+func (h *Handler) ClearSearchHistory(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	result := h.db.Where("user_id = ?", currentUser.ID).Delete(&SearchHistory{})
+	if result.Error != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to clear search history")
+	}
+
+	return utils.Success(c, map[string]interface{}{
+		"deleted_count": result.RowsAffected,
+	})
+}
+
+// RerunSearchHistory 以相同的参数重新执行一次历史检索
+// POST /api/search/history/:id/rerun
+// 全网检索会重新计费并创建新的会话，库内检索直接返回结果
+func (h *Handler) RerunSearchHistory(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var history SearchHistory
+	if err := h.db.Where("id = ? AND user_id = ?", c.Param("id"), currentUser.ID).First(&history).Error; err != nil {
+		return utils.Fail(c, http.StatusNotFound, "Search history not found")
+	}
+
+	// 早期记录没有保存完整参数，使用关键词、范围和模型重建
+	req := SearchRequest{Q: history.Query, Scope: history.Scope, Model: history.ModelType}
+	if len(history.Filters) > 0 {
+		if err := json.Unmarshal(history.Filters, &req); err != nil {
+			return utils.Error(c, http.StatusInternalServerError, "Failed to decode search filters")
+		}
+	}
+
+	if err := utils.ValidateRequest(c, &req); err != nil {
+		return err
+	}
+
+	return h.runSearch(c, currentUser.ID, req)
+}
+
+// GetTopQueries 获取当前用户最常用的检索词
+// GET /api/search/history/top
+func (h *Handler) GetTopQueries(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit < 1 {
+		limit = defaultTopQueryLimit
+	}
+	if limit > maxTopQueryLimit {
+		limit = maxTopQueryLimit
+	}
+
+	db := h.db.Model(&SearchHistory{}).Where("user_id = ?", currentUser.ID)
+	if scope := c.QueryParam("scope"); scope != "" {
+		db = db.Where("scope = ?", scope)
+	}
+
+	var rows []struct {
+		Query        string
+		Count        int64
+		LastSearched string
+	}
+	if err := db.Select("query, COUNT(*) AS count, MAX(created_at) AS last_searched").
+		Group("query").
+		Order("count DESC, last_searched DESC").
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to get top queries")
+	}
+
+	queries := make([]TopQuery, 0, len(rows))
+	for _, row := range rows {
+		queries = append(queries, TopQuery{
+			Query:        row.Query,
+			Count:        row.Count,
+			LastSearched: parseDBTime(row.LastSearched),
+		})
+	}
+
+	return utils.Success(c, map[string]interface{}{
+		"count":   len(queries),
+		"queries": queries,
+	})
+}
+
+// dbTimeLayouts 聚合查询返回的时间字符串格式（SQLite 为文本，MySQL 为 RFC3339）
+var dbTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05",
+}
+
+// parseDBTime 解析聚合查询返回的时间，无法解析时返回零值
+func parseDBTime(s string) time.Time {
+	for _, layout := range dbTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,436 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"policy-backend/intelligence"
+	"policy-backend/team"
+	"policy-backend/user"
+	"policy-backend/utils"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// 导入模式
+const (
+	ImportModeAtomic  = "atomic"  // 全部成功或全部不导入
+	ImportModePartial = "partial" // 逐条导入，失败的记录不影响其他记录
+)
+
+// 单条缓冲区记录的导入结果
+const (
+	ImportStatusImported = "imported"  // 已导入
+	ImportStatusNotFound = "not_found" // 记录不存在或不属于当前用户
+	ImportStatusSkipped  = "skipped"   // 记录已入库或已丢弃
+	ImportStatusInvalid  = "invalid"   // 原始数据未通过校验
+	ImportStatusFailed   = "failed"    // 写入数据库失败
+	ImportStatusAborted  = "aborted"   // 本条可以导入，但整批导入已取消（atomic 模式）
+)
+
+// errBufferNotPending 写入时发现记录已被其他请求处理
+var errBufferNotPending = errors.New("buffer is no longer pending")
+
+// rawResult 缓冲区原始数据的结构约定，与 SearchProvider 归一化后的字段一致
+type rawResult struct {
+	Title       string `json:"title"`
+	Source      string `json:"source"`
+	URL         string `json:"url"`
+	Content     string `json:"content"`
+	PublishDate string `json:"publish_date"` // RFC3339
+	PDFURL      string `json:"pdf_url"`      // PDF 原文链接，缺失时若 url 指向 PDF 则使用 url
+	Keywords    string `json:"keywords"`     // 逗号分隔的关键词，文件导入时由关键词列带入
+}
+
+// 映射到情报表时各字段的长度上限，与表结构一致
+const (
+	maxImportTitleLen  = 500
+	maxImportSourceLen = 200
+)
+
+// ImportFieldError 原始数据字段的校验错误
+type ImportFieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ImportItemResult 单条缓冲区记录的导入结果
+type ImportItemResult struct {
+	BufferID       uint               `json:"buffer_id"`
+	Status         string             `json:"status"`
+	Reason         string             `json:"reason,omitempty"`
+	Errors         []ImportFieldError `json:"errors,omitempty"` // status 为 invalid 时的字段错误
+	IntelligenceID uint               `json:"intelligence_id,omitempty"`
+}
+
+// ImportResult 导入结果
+type ImportResult struct {
+	Mode            string             `json:"mode"`
+	ImportedCount   int                `json:"imported_count"`
+	FailedCount     int                `json:"failed_count"` // 未导入的记录数（含 aborted）
+	IntelligenceIDs []uint             `json:"intelligence_ids"`
+	Results         []ImportItemResult `json:"results"` // 与请求中 buffer_ids 的顺序一致（已去重）
+}
+
+// importCandidate 通过校验、等待写入的记录
+type importCandidate struct {
+	buffer       *SearchBuffer
+	intelligence *intelligence.Intelligence
+	result       *ImportItemResult
+}
+
+// mapBuffer 校验缓冲区原始数据并映射为情报记录（不含归属字段）
+// 原始数据缺失的标题与发布日期以预览字段补齐
+func mapBuffer(buffer *SearchBuffer) (*intelligence.Intelligence, []ImportFieldError) {
+	if len(buffer.RawData) == 0 {
+		return nil, []ImportFieldError{{Field: "raw_data", Message: "is empty"}}
+	}
+
+	var raw rawResult
+	if err := json.Unmarshal(buffer.RawData, &raw); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return nil, []ImportFieldError{{
+				Field:   typeErr.Field,
+				Message: fmt.Sprintf("must be %s, got %s", typeErr.Type, typeErr.Value),
+			}}
+		}
+		return nil, []ImportFieldError{{Field: "raw_data", Message: "is not a valid JSON object"}}
+	}
+
+	var errs []ImportFieldError
+	addError := func(field, format string, args ...interface{}) {
+		errs = append(errs, ImportFieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	title := strings.TrimSpace(raw.Title)
+	if title == "" {
+		title = strings.TrimSpace(buffer.PreviewTitle)
+	}
+	if title == "" {
+		addError("title", "is required")
+	} else if utf8.RuneCountInString(title) > maxImportTitleLen {
+		addError("title", "must be at most %d characters", maxImportTitleLen)
+	}
+
+	// 链接可为空（如文献管理软件导出的条目），此时按标题查重
+	link := strings.TrimSpace(raw.URL)
+	if link != "" && !isHTTPURL(link) {
+		addError("url", "must be an absolute http(s) URL")
+	}
+
+	pdfURL := strings.TrimSpace(raw.PDFURL)
+	if pdfURL != "" {
+		if !isHTTPURL(pdfURL) {
+			addError("pdf_url", "must be an absolute http(s) URL")
+		}
+	} else if intelligence.IsPDFLink(link) {
+		pdfURL = link
+	}
+
+	source := strings.TrimSpace(raw.Source)
+	if source == "" {
+		source = buffer.PreviewSource
+	}
+	if utf8.RuneCountInString(source) > maxImportSourceLen {
+		addError("source", "must be at most %d characters", maxImportSourceLen)
+	}
+
+	publishDate := buffer.PreviewDate
+	if raw.PublishDate != "" {
+		t, err := time.Parse(time.RFC3339, raw.PublishDate)
+		if err != nil {
+			addError("publish_date", "must be an RFC3339 timestamp")
+		}
+		publishDate = t
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	record := &intelligence.Intelligence{
+		Title:              title,
+		Content:            raw.Content,
+		Source:             source,
+		AgencyID:           buffer.AgencyID,
+		CountryID:          buffer.CountryID,
+		URL:                link,
+		Summary:            buffer.PreviewSummary,
+		Keywords:           strings.TrimSpace(raw.Keywords),
+		PublishDate:        publishDate,
+		CanonicalURL:       buffer.CanonicalURL,
+		DataHash:           buffer.DataHash,
+		HashVersion:        buffer.HashVersion,
+		ContentFingerprint: buffer.ContentFingerprint,
+	}
+	if pdfURL != "" {
+		record.PDFURL = pdfURL
+		record.PDFStatus = intelligence.PDFStatusPending
+	}
+	return record, nil
+}
+
+// isHTTPURL 是否为 http/https 绝对地址
+func isHTTPURL(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// fieldErrorsReason 将字段错误合并为一句说明
+func fieldErrorsReason(errs []ImportFieldError) string {
+	parts := make([]string, 0, len(errs))
+	for _, e := range errs {
+		parts = append(parts, e.Field+" "+e.Message)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// prepareImport 逐条检查请求中的缓冲区记录，返回通过校验的记录及全部记录的结果
+func (h *Handler) prepareImport(userID uint, ids []uint) ([]importCandidate, []ImportItemResult, error) {
+	var buffers []SearchBuffer
+	if err := h.db.Where("id IN ? AND user_id = ?", ids, userID).Find(&buffers).Error; err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[uint]*SearchBuffer, len(buffers))
+	for i := range buffers {
+		byID[buffers[i].ID] = &buffers[i]
+	}
+
+	results := make([]ImportItemResult, len(ids))
+	var candidates []importCandidate
+	for i, id := range ids {
+		result := &results[i]
+		result.BufferID = id
+
+		buffer, ok := byID[id]
+		if !ok {
+			result.Status = ImportStatusNotFound
+			result.Reason = "Buffer record not found"
+			continue
+		}
+		if buffer.Status != BufferStatusPending {
+			result.Status = ImportStatusSkipped
+			result.Reason = "Buffer record is already " + buffer.Status
+			continue
+		}
+
+		record, errs := mapBuffer(buffer)
+		if len(errs) > 0 {
+			result.Status = ImportStatusInvalid
+			result.Reason = fieldErrorsReason(errs)
+			result.Errors = errs
+			continue
+		}
+		candidates = append(candidates, importCandidate{buffer: buffer, intelligence: record, result: result})
+	}
+	return candidates, results, nil
+}
+
+// importBuffer 在事务中写入情报并把缓冲区记录标记为已入库
+// 状态更新以 pending 为条件，防止并发请求重复导入同一条记录；
+// 导入到团队时同时写入团队归属记录与团队动态
+func importBuffer(tx *gorm.DB, candidate importCandidate) error {
+	record := candidate.intelligence
+	if err := tx.Create(record).Error; err != nil {
+		return err
+	}
+
+	if record.TeamID != nil {
+		if err := intelligence.GrantTeamOwnership(tx, record.ID, *record.TeamID, record.UserID); err != nil {
+			return err
+		}
+		if err := team.RecordImport(tx, *record.TeamID, record.UserID, record.ID, record.Title,
+			candidate.buffer.SessionID, candidate.buffer.ID); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	result := tx.Model(&SearchBuffer{}).
+		Where("id = ? AND status = ?", candidate.buffer.ID, BufferStatusPending).
+		Updates(map[string]interface{}{
+			"status":      BufferStatusImported,
+			"imported_at": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errBufferNotPending
+	}
+	return nil
+}
+
+// markImportError 根据写入错误设置单条结果
+func markImportError(result *ImportItemResult, err error) {
+	if errors.Is(err, errBufferNotPending) {
+		result.Status = ImportStatusSkipped
+		result.Reason = "Buffer record was processed by another request"
+		return
+	}
+	log.Printf("Failed to import buffer %d: %v\n", result.BufferID, err)
+	result.Status = ImportStatusFailed
+	result.Reason = "Failed to save intelligence"
+}
+
+// importAtomic 在同一事务中导入全部记录，任一记录无法导入时整批回滚
+func (h *Handler) importAtomic(candidates []importCandidate, results []ImportItemResult) bool {
+	for _, r := range results {
+		if r.Status != "" {
+			abortImport(candidates, "Batch aborted because other buffer records cannot be imported")
+			return false
+		}
+	}
+
+	var failed *importCandidate
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		for i := range candidates {
+			if err := importBuffer(tx, candidates[i]); err != nil {
+				failed = &candidates[i]
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		abortImport(candidates, "Batch aborted because other buffer records cannot be imported")
+		if failed != nil {
+			markImportError(failed.result, err)
+		}
+		return false
+	}
+
+	for _, candidate := range candidates {
+		candidate.result.Status = ImportStatusImported
+		candidate.result.IntelligenceID = candidate.intelligence.ID
+	}
+	return true
+}
+
+// abortImport 把尚未出错的记录标记为已取消，并清除回滚前分配的情报ID
+func abortImport(candidates []importCandidate, reason string) {
+	for _, candidate := range candidates {
+		candidate.intelligence.ID = 0
+		candidate.result.Status = ImportStatusAborted
+		candidate.result.Reason = reason
+	}
+}
+
+// importPartial 每条记录使用独立事务导入，失败的记录不影响其他记录
+func (h *Handler) importPartial(candidates []importCandidate) {
+	for _, candidate := range candidates {
+		err := h.db.Transaction(func(tx *gorm.DB) error {
+			return importBuffer(tx, candidate)
+		})
+		if err != nil {
+			markImportError(candidate.result, err)
+			continue
+		}
+		candidate.result.Status = ImportStatusImported
+		candidate.result.IntelligenceID = candidate.intelligence.ID
+	}
+}
+
+// failTeamCheck 将团队归属检查错误映射为接口响应
+func failTeamCheck(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, team.ErrTeamNotFound):
+		return utils.Fail(c, http.StatusNotFound, "Team not found")
+	case errors.Is(err, team.ErrNotTeamMember):
+		return utils.Fail(c, http.StatusForbidden, "You are not a member of this team")
+	case errors.Is(err, team.ErrTeamRoleRejected):
+		return utils.Fail(c, http.StatusForbidden, "Your team role cannot import intelligence")
+	default:
+		return utils.Error(c, http.StatusInternalServerError, "Failed to check team membership")
+	}
+}
+
+// ImportIntelligences 从缓冲区导入情报到正式库
+// POST /api/search/import
+// mode=atomic（默认）时全部成功或全部不导入；mode=partial 时逐条导入。
+// 响应按 buffer_ids 顺序列出每条记录的结果与原因
+func (h *Handler) ImportIntelligences(c echo.Context) error {
+	var req ImportIntelligenceRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid parameters")
+	}
+
+	if err := utils.ValidateRequest(c, &req); err != nil {
+		return err
+	}
+	if req.Mode == "" {
+		req.Mode = ImportModeAtomic
+	}
+
+	// 获取当前用户
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	// 如果目标是团队，须为团队成员且角色允许导入
+	var teamID *uint
+	if req.TargetScope == "team" {
+		if req.TeamID == 0 {
+			return utils.Fail(c, http.StatusBadRequest, "Team ID is required for team scope")
+		}
+		if err := team.CheckContributor(h.db, req.TeamID, currentUser.ID); err != nil {
+			return failTeamCheck(c, err)
+		}
+		teamID = &req.TeamID
+	}
+
+	// 1. 读取属于当前用户的缓冲区记录并校验原始数据
+	ids := uniqueIDs(req.BufferIDs)
+	candidates, results, err := h.prepareImport(currentUser.ID, ids)
+	if err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to find buffer records")
+	}
+	for _, candidate := range candidates {
+		candidate.intelligence.ContributorID = currentUser.ID
+		candidate.intelligence.UserID = currentUser.ID
+		candidate.intelligence.TeamID = teamID
+	}
+
+	// 2. 按模式导入到正式库
+	committed := true
+	if req.Mode == ImportModeAtomic {
+		committed = h.importAtomic(candidates, results)
+	} else {
+		h.importPartial(candidates)
+	}
+
+	// 3. 已导入且带 PDF 链接的情报在后台抓取原文
+	for _, candidate := range candidates {
+		if candidate.result.Status == ImportStatusImported && candidate.intelligence.PDFURL != "" {
+			h.pdf.CaptureAsync(candidate.intelligence.ID, candidate.intelligence.PDFURL)
+		}
+	}
+
+	result := ImportResult{
+		Mode:            req.Mode,
+		IntelligenceIDs: []uint{},
+		Results:         results,
+	}
+	for _, r := range results {
+		if r.Status == ImportStatusImported {
+			result.ImportedCount++
+			result.IntelligenceIDs = append(result.IntelligenceIDs, r.IntelligenceID)
+		} else {
+			result.FailedCount++
+		}
+	}
+
+	if !committed {
+		return utils.FailWithData(c, http.StatusUnprocessableEntity, "Import aborted, no buffer records were imported", result)
+	}
+	return utils.Success(c, result)
+}
@@ -0,0 +1,61 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"policy-backend/intelligence"
+)
+
+func TestMapBuffer(t *testing.T) {
+	tests := []struct {
+		name       string
+		raw        string
+		wantFields []string
+		check      func(t *testing.T, rec *intelligence.Intelligence)
+	}{
+		{
+			name: "full record",
+			raw:  `{"title":" 量子计算 ","url":"https://www.nsf.gov/news/a.pdf","source":"NSF","publish_date":"2024-03-15T00:00:00Z"}`,
+			check: func(t *testing.T, rec *intelligence.Intelligence) {
+				if rec.Title != "量子计算" || rec.URL != "https://www.nsf.gov/news/a.pdf" || rec.PublishDate.Year() != 2024 {
+					t.Errorf("record = %+v", rec)
+				}
+				if rec.PDFURL != rec.URL || rec.PDFStatus != intelligence.PDFStatusPending {
+					t.Errorf("pdf = %s %s, want url and pending", rec.PDFURL, rec.PDFStatus)
+				}
+			},
+		},
+		{
+			name: "missing url is allowed",
+			raw:  `{"title":"Quantum Information Science: A Review","source":"Nature"}`,
+			check: func(t *testing.T, rec *intelligence.Intelligence) {
+				if rec.URL != "" || rec.PDFURL != "" || rec.PDFStatus != "" {
+					t.Errorf("record = %+v, want no url", rec)
+				}
+			},
+		},
+		{name: "relative url", raw: `{"title":"a","url":"/news/a.html"}`, wantFields: []string{"url"}},
+		{name: "bad pdf url", raw: `{"title":"a","pdf_url":"ftp://a/b.pdf"}`, wantFields: []string{"pdf_url"}},
+		{name: "missing title", raw: `{"url":"https://a.gov/1"}`, wantFields: []string{"title"}},
+		{name: "long title", raw: `{"title":"` + strings.Repeat("长", maxImportTitleLen+1) + `"}`, wantFields: []string{"title"}},
+		{name: "bad date", raw: `{"title":"a","publish_date":"2024-03-15"}`, wantFields: []string{"publish_date"}},
+		{name: "wrong type", raw: `{"title":"a","content":1}`, wantFields: []string{"content"}},
+		{name: "not an object", raw: `[1]`, wantFields: []string{"raw_data"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, errs := mapBuffer(&SearchBuffer{RawData: []byte(tt.raw)})
+			var fields []string
+			for _, e := range errs {
+				fields = append(fields, e.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.wantFields, ",") {
+				t.Fatalf("error fields = %v, want %v (%v)", fields, tt.wantFields, errs)
+			}
+			if tt.check != nil {
+				tt.check(t, rec)
+			}
+		})
+	}
+}
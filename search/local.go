@@ -0,0 +1,141 @@
+package search
+
+import (
+	"errors"
+	"net/http"
+	"policy-backend/intelligence"
+	"policy-backend/query"
+	"policy-backend/utils"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// 库内检索默认分页大小
+const defaultLocalPageSize = 20
+
+// LocalSearch 库内检索
+// 仅查询 intelligences 表，不消耗积分、不创建缓冲区
+func (h *Handler) LocalSearch(c echo.Context, userID uint, req SearchRequest) error {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = defaultLocalPageSize
+	}
+
+	results, total, err := h.localSearch(userID, req)
+	if err != nil {
+		if errors.Is(err, errInvalidDate) {
+			return utils.Fail(c, http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD")
+		}
+		var queryErr *query.Error
+		if errors.As(err, &queryErr) {
+			return utils.FailWithData(c, http.StatusBadRequest, "Invalid query: "+queryErr.Error(), queryErr)
+		}
+		return utils.Error(c, http.StatusInternalServerError, "Failed to search library")
+	}
+
+	h.recordHistory(userID, "", req, int(total), 0)
+
+	return utils.Success(c, map[string]interface{}{
+		"query":   req.Q,
+		"scope":   req.Scope,
+		"count":   len(results),
+		"total":   total,
+		"page":    req.Page,
+		"results": results,
+	})
+}
+
+// errInvalidDate 日期参数格式错误
+var errInvalidDate = errors.New("invalid date format")
+
+// localSearch 构建并执行库内检索查询
+func (h *Handler) localSearch(userID uint, req SearchRequest) ([]SearchResult, int64, error) {
+	base := h.db.Table("intelligences").
+		Joins("LEFT JOIN agencies ON agencies.id = intelligences.agency_id")
+
+	// 1. 权限范围
+	base = intelligence.ApplyLibraryScope(base, userID, req.LibraryScope, req.TeamID)
+
+	// 2. 检索表达式（布尔语法，检索词走全文索引）
+	filter, err := intelligence.CompileQuery(h.db, h.fullText, req.Q)
+	if err != nil {
+		return nil, 0, err
+	}
+	base = filter.Apply(base)
+
+	// 3. 筛选条件
+	if agencyIDs := req.agencyFilter(); len(agencyIDs) > 0 {
+		base = base.Where("intelligences.agency_id IN ?", agencyIDs)
+	}
+	if req.CountryID != 0 {
+		base = base.Where("(intelligences.country_id = ? OR agencies.country_id = ?)", req.CountryID, req.CountryID)
+	}
+	if req.DateFrom != "" {
+		from, err := time.Parse("2006-01-02", req.DateFrom)
+		if err != nil {
+			return nil, 0, errInvalidDate
+		}
+		base = base.Where("intelligences.publish_date >= ?", from)
+	}
+	if req.DateTo != "" {
+		to, err := time.Parse("2006-01-02", req.DateTo)
+		if err != nil {
+			return nil, 0, errInvalidDate
+		}
+		base = base.Where("intelligences.publish_date < ?", to.Add(24*time.Hour))
+	}
+	if req.HasPDF {
+		base = base.Where("intelligences.has_pdf = ?", true)
+	}
+
+	// 4. 统计总数
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 5. 查询结果（附带机构名称、平均评分与相关度）
+	stmt := base.Select(`intelligences.id, intelligences.title, intelligences.summary,
+		intelligences.agency_id, agencies.name AS agency_name, intelligences.keywords,
+		intelligences.url AS original_url, intelligences.publish_date, intelligences.created_at, intelligences.has_pdf,
+		intelligences.content, COALESCE(r.avg_score, 0) AS rating, ` + filter.ScoreColumn() + ` AS score`).
+		Joins("LEFT JOIN (SELECT intelligence_id, AVG(score) AS avg_score FROM ratings WHERE deleted_at IS NULL GROUP BY intelligence_id) r ON r.intelligence_id = intelligences.id")
+
+	switch req.Sort {
+	case "date_desc":
+		stmt = stmt.Order("intelligences.publish_date DESC")
+	case "rating_desc":
+		stmt = stmt.Order("rating DESC").Order("intelligences.publish_date DESC")
+	default:
+		// relevance: 按全文索引相关度，其次按发布日期
+		stmt = stmt.Order("score DESC").Order("intelligences.publish_date DESC")
+	}
+
+	var rows []localSearchRow
+	if err := stmt.Limit(req.Limit).
+		Offset((req.Page - 1) * req.Limit).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+
+	results := make([]SearchResult, 0, len(rows))
+	for _, row := range rows {
+		result := row.SearchResult
+		if filter.HasRank() {
+			result.Highlight = intelligence.BuildHighlight(row.Title, row.Summary, row.Content, filter.HighlightQuery())
+		}
+		results = append(results, result)
+	}
+
+	return results, total, nil
+}
+
+// localSearchRow 库内检索查询行，正文仅用于生成高亮片段，不返回给前端
+type localSearchRow struct {
+	SearchResult
+	Content string
+}
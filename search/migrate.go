@@ -0,0 +1,118 @@
+package search
+
+import (
+	"encoding/json"
+	"policy-backend/intelligence"
+
+	"gorm.io/gorm"
+)
+
+// migrateBatchSize 迁移任务每批处理的记录数
+const migrateBatchSize = 500
+
+// dataHashVersion 当前规范 URL 与 DataHash 的计算规则版本
+// 新写入的缓冲区与由其导入的情报记录此版本；调整规范化规则后加一，启动时的迁移会重算旧版本的记录
+const dataHashVersion = 1
+
+// hashMigrationRow 迁移任务读取的最小字段集
+type hashMigrationRow struct {
+	ID           uint
+	Title        string
+	URL          string
+	Content      string
+	CanonicalURL string
+	DataHash     string
+	HashVersion  int
+	SimHash      string
+	RawData      string
+}
+
+// HashMigrationResult 哈希迁移结果
+type HashMigrationResult struct {
+	Intelligences int64 `json:"intelligences"` // 更新的情报条数
+	Buffers       int64 `json:"buffers"`       // 更新的缓冲区条数
+}
+
+// RecomputeDataHashes 按当前 URL 规范化规则重算已有情报和缓冲区的规范 URL 与 DataHash，
+// 同时为缺少内容指纹的记录补算 SimHash
+// force 为 false 时只处理 hash_version 低于当前版本的记录，处理后写入当前版本，可在每次启动时安全执行；
+// 没有链接（规范 URL 为空）或文本过短无法生成指纹（sim_hash 为空）的记录处理一次后也不再重复扫描。
+// 通过 RegisterDomainRule 调整站点规则不会改变版本，应以 force=true 全量重算
+func (h *Handler) RecomputeDataHashes(force bool) (HashMigrationResult, error) {
+	var result HashMigrationResult
+
+	n, err := h.recomputeTable("intelligences", "id, title, url, content, canonical_url, data_hash, hash_version, sim_hash", force)
+	if err != nil {
+		return result, err
+	}
+	result.Intelligences = n
+
+	n, err = h.recomputeTable("search_buffers",
+		"id, preview_title AS title, raw_data, preview_summary AS content, canonical_url, data_hash, hash_version, sim_hash",
+		force)
+	if err != nil {
+		return result, err
+	}
+	result.Buffers = n
+
+	return result, nil
+}
+
+// recomputeTable 分批重算某张表的规范 URL、DataHash 与内容指纹
+// 使用 Table + map 更新，不触发模型回调（如全文索引同步），这些字段不参与全文检索
+func (h *Handler) recomputeTable(table, columns string, force bool) (int64, error) {
+	query := h.db.Table(table).Select(columns)
+	if table == "search_buffers" {
+		query = query.Where("deleted_at IS NULL")
+	}
+	if !force {
+		query = query.Where("hash_version < ? OR hash_version IS NULL", dataHashVersion)
+	}
+
+	var updated int64
+	var rows []hashMigrationRow
+	err := query.FindInBatches(&rows, migrateBatchSize, func(tx *gorm.DB, batch int) error {
+		for _, row := range rows {
+			fields := map[string]interface{}{}
+
+			// 缓冲区的 URL 保存在原始数据中
+			if row.URL == "" && len(row.RawData) > 0 {
+				var raw map[string]interface{}
+				if err := json.Unmarshal([]byte(row.RawData), &raw); err == nil {
+					row.URL, _ = raw["url"].(string)
+				}
+			}
+
+			canonicalURL, dataHash := dataHashFor(row.URL, row.Title)
+			if canonicalURL != row.CanonicalURL {
+				fields["canonical_url"] = canonicalURL
+			}
+			if dataHash != row.DataHash {
+				fields["data_hash"] = dataHash
+			}
+			if row.HashVersion != dataHashVersion {
+				fields["hash_version"] = dataHashVersion
+			}
+			if row.SimHash == "" {
+				fp := intelligence.NewContentFingerprint(row.Title, row.Content)
+				if !fp.IsEmpty() {
+					fields["sim_hash"] = fp.SimHash
+					fields["sim_band0"] = fp.SimBand0
+					fields["sim_band1"] = fp.SimBand1
+					fields["sim_band2"] = fp.SimBand2
+					fields["sim_band3"] = fp.SimBand3
+				}
+			}
+			if len(fields) == 0 {
+				continue
+			}
+
+			if err := h.db.Table(table).Where("id = ?", row.ID).UpdateColumns(fields).Error; err != nil {
+				return err
+			}
+			updated++
+		}
+		return nil
+	}).Error
+	return updated, err
+}
@@ -0,0 +1,58 @@
+package search
+
+import (
+	"testing"
+
+	"policy-backend/intelligence"
+)
+
+func TestRecomputeDataHashes(t *testing.T) {
+	db := newFeedTestDB(t)
+	if err := db.AutoMigrate(&intelligence.Intelligence{}, &SearchBuffer{}); err != nil {
+		t.Fatal(err)
+	}
+	h := &Handler{db: db}
+
+	// 旧规则写入的记录：有链接、没有链接且文本过短（规范 URL 与指纹都为空）
+	legacy := []intelligence.Intelligence{
+		{Title: "Quantum funding", URL: "http://www.nsf.gov/news/1?utm_source=rss", DataHash: "old", UserID: 1, ContributorID: 1},
+		{Title: "短", UserID: 1, ContributorID: 1},
+	}
+	if err := db.Create(&legacy).Error; err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&SearchBuffer{SessionID: "s1", RawData: []byte(`{"title":"无链接"}`), PreviewTitle: "无链接"})
+
+	result, err := h.RecomputeDataHashes(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Intelligences != 2 || result.Buffers != 1 {
+		t.Errorf("first run = %+v, want 2 intelligences and 1 buffer", result)
+	}
+
+	var got intelligence.Intelligence
+	db.First(&got, legacy[0].ID)
+	wantURL, wantHash := dataHashFor(legacy[0].URL, legacy[0].Title)
+	if got.CanonicalURL != wantURL || got.DataHash != wantHash || got.HashVersion != dataHashVersion {
+		t.Errorf("migrated = %s %s v%d, want %s %s v%d", got.CanonicalURL, got.DataHash, got.HashVersion,
+			wantURL, wantHash, dataHashVersion)
+	}
+
+	// 再次执行不应重复处理没有链接或指纹的记录
+	result, err = h.RecomputeDataHashes(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (HashMigrationResult{}) {
+		t.Errorf("second run = %+v, want nothing updated", result)
+	}
+
+	result, err = h.RecomputeDataHashes(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (HashMigrationResult{}) {
+		t.Errorf("forced run on current rows = %+v, want nothing updated", result)
+	}
+}
@@ -1,6 +1,8 @@
 package search
 
 import (
+	"encoding/json"
+	"policy-backend/intelligence"
 	"time"
 
 	"gorm.io/gorm"
@@ -18,45 +20,109 @@ type SearchResult struct {
 	PublishDate time.Time `json:"publish_date"`
 	CreatedAt   time.Time `json:"created_at"`
 	Rating      float64   `json:"rating"`
+	HasPDF      bool      `json:"has_pdf"`      // 是否已保存 PDF 原文
 	IsDuplicate bool      `json:"is_duplicate"` // 是否重复
 	DuplicateID uint      `json:"duplicate_id"` // 重复记录的ID
+
+	Score     float64                 `json:"score,omitempty"`              // 全文检索相关度
+	Highlight *intelligence.Highlight `json:"highlight,omitempty" gorm:"-"` // 高亮标题与片段
 }
 
 // SearchRequest 搜索请求
 type SearchRequest struct {
-	Q        string `json:"q" validate:"required"`                    // 关键词
-	Scope    string `json:"scope" validate:"omitempty"`               // 全网/库内: global, local
-	AgencyID uint   `json:"agency_id" validate:"omitempty"`           // 机构ID
-	DateFrom string `json:"date_from" validate:"omitempty"`           // 开始日期
-	DateTo   string `json:"date_to" validate:"omitempty"`             // 结束日期
-	Model    string `json:"model" validate:"omitempty"`               // 模型: basic, advanced, pro
-	Limit    int    `json:"limit" validate:"omitempty,min=1,max=100"` // 数量限制
-	Page     int    `json:"page" validate:"omitempty,min=1"`          // 页码
+	Q         string `json:"q" query:"q" validate:"required"`                                     // 关键词
+	Scope     string `json:"scope" query:"scope" validate:"omitempty,oneof=global local"`         // 全网/库内: global, local
+	AgencyID  uint   `json:"agency_id" query:"agency_id" validate:"omitempty"`                    // 机构ID
+	AgencyIDs []uint `json:"agency_ids,omitempty" query:"agency_ids" validate:"omitempty,max=50"` // 多个机构ID，与 agency_id 合并
+	DateFrom  string `json:"date_from" query:"date_from" validate:"omitempty"`                    // 开始日期
+	DateTo    string `json:"date_to" query:"date_to" validate:"omitempty"`                        // 结束日期
+	Model     string `json:"model" query:"model" validate:"omitempty,oneof=basic advanced pro"`   // 模型: basic, advanced, pro
+	Limit     int    `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`              // 数量限制
+	Page      int    `json:"page" query:"page" validate:"omitempty,min=1"`                        // 页码
+
+	// 以下参数仅在库内检索 (scope=local) 时生效
+	CountryID    uint   `json:"country_id" query:"country_id" validate:"omitempty"`                           // 国家ID
+	HasPDF       bool   `json:"has_pdf" query:"has_pdf"`                                                      // 仅看已保存 PDF 原文的情报
+	Sort         string `json:"sort" query:"sort" validate:"omitempty,oneof=relevance date_desc rating_desc"` // 排序方式
+	LibraryScope string `json:"library_scope" query:"library_scope" validate:"omitempty,oneof=all mine team"` // 检索范围: all, mine, team
+	TeamID       uint   `json:"team_id" query:"team_id" validate:"omitempty"`                                 // 限定团队（library_scope=team 时可选）
+}
+
+// agencyFilter 合并 agency_id 与 agency_ids，返回去重后的机构ID列表
+func (r SearchRequest) agencyFilter() []uint {
+	ids := r.AgencyIDs
+	if r.AgencyID != 0 {
+		ids = append([]uint{r.AgencyID}, ids...)
+	}
+	return uniqueIDs(ids)
 }
 
 // CheckDuplicationRequest 查重请求
 type CheckDuplicationRequest struct {
 	URLs   []string `json:"urls" validate:"omitempty,dive,url"`     // URL列表
 	Titles []string `json:"titles" validate:"omitempty,dive,min=1"` // 标题列表
+	Texts  []string `json:"texts" validate:"omitempty,dive,min=1"`  // 原文列表（按内容指纹做近似查重）
 }
 
 // DuplicationResult 查重结果
 type DuplicationResult struct {
-	URL         string `json:"url"`
-	Title       string `json:"title"`
-	IsDuplicate bool   `json:"is_duplicate"`
-	ExistingID  uint   `json:"existing_id,omitempty"` // 已存在的记录ID
+	URL         string                      `json:"url"`
+	Title       string                      `json:"title"`
+	Text        string                      `json:"text,omitempty"`
+	IsDuplicate bool                        `json:"is_duplicate"`
+	ExistingID  uint                        `json:"existing_id,omitempty"` // 已存在的记录ID
+	Similar     []intelligence.SimilarMatch `json:"similar,omitempty"`     // 近似重复的情报（仅原文查重）
 }
 
 // SearchHistory 搜索历史记录
 type SearchHistory struct {
-	gorm.Model
-	UserID      uint      `json:"user_id" gorm:"not null;index;foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	Query       string    `json:"query" gorm:"not null;size:500"`
-	Scope       string    `json:"scope" gorm:"size:20"`
-	ModelType   string    `json:"model_type" gorm:"size:20"` // 避免与 gorm.Model 冲突
-	ResultCount int       `json:"result_count" gorm:"default:0"`
-	CreatedAt   time.Time `json:"created_at" gorm:"index"`
+	ID          uint            `json:"id" gorm:"primaryKey"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt  `json:"-" gorm:"index"`
+	UserID      uint            `json:"user_id" gorm:"not null;index;foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Query       string          `json:"query" gorm:"not null;size:500"`
+	Scope       string          `json:"scope" gorm:"size:20"`
+	ModelType   string          `json:"model_type" gorm:"size:20"`                          // 避免与 gorm.Model 冲突
+	Filters     json.RawMessage `json:"filters" gorm:"type:json"`                           // 完整的检索参数，用于重跑
+	SessionID   string          `json:"session_id,omitempty" gorm:"type:varchar(64);index"` // 全网检索对应的会话
+	ResultCount int             `json:"result_count" gorm:"default:0"`
+	Cost        int64           `json:"cost" gorm:"default:0"` // 消耗的积分
+	CreatedAt   time.Time       `json:"created_at" gorm:"index"`
+}
+
+// PageQuery 通用分页参数
+type PageQuery struct {
+	Page     int `query:"page" validate:"omitempty,min=1"`
+	PageSize int `query:"page_size" validate:"omitempty,min=1,max=100"`
+}
+
+// normalize 填充默认页码与每页数量
+func (q *PageQuery) normalize(defaultSize int) {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 {
+		q.PageSize = defaultSize
+	}
+}
+
+// offset 计算偏移量
+func (q *PageQuery) offset() int {
+	return (q.Page - 1) * q.PageSize
+}
+
+// HistoryQuery 搜索历史列表查询参数
+type HistoryQuery struct {
+	PageQuery
+	Scope string `query:"scope" validate:"omitempty,oneof=global local"` // 按检索范围过滤
+	Q     string `query:"q"`                                             // 按关键词模糊过滤
+}
+
+// TopQuery 常用检索词统计结果
+type TopQuery struct {
+	Query        string    `json:"query"`
+	Count        int64     `json:"count"`
+	LastSearched time.Time `json:"last_searched"`
 }
 
 // TableName 指定表名
@@ -0,0 +1,433 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"policy-backend/user"
+	"policy-backend/utils"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 监听任务参数
+const (
+	defaultMonitorPageSize = 20
+	monitorBatchSize       = 50 // 每次调度最多启动的监听任务数
+)
+
+// findUserMonitor 查找属于当前用户的监听任务
+func (h *Handler) findUserMonitor(c echo.Context) (*Monitor, error) {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return nil, utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var monitor Monitor
+	if err := h.db.Where("id = ? AND user_id = ?", c.Param("id"), currentUser.ID).First(&monitor).Error; err != nil {
+		return nil, utils.Fail(c, http.StatusNotFound, "Monitor not found")
+	}
+	return &monitor, nil
+}
+
+// bindMonitorRequest 解析并校验监听任务请求
+func bindMonitorRequest(c echo.Context) (*MonitorRequest, error) {
+	var req MonitorRequest
+	if err := c.Bind(&req); err != nil {
+		return nil, utils.Fail(c, http.StatusBadRequest, "Invalid parameters")
+	}
+	if err := utils.ValidateRequest(c, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// apply 将请求内容写入监听任务
+func (req *MonitorRequest) apply(m *Monitor) error {
+	agencies, err := json.Marshal(uniqueIDs(req.SourceAgencies))
+	if err != nil {
+		return err
+	}
+
+	m.Name = req.Name
+	if m.Name == "" {
+		m.Name = req.Keywords
+	}
+	m.Keywords = req.Keywords
+	m.SourceAgencies = agencies
+	m.Frequency = req.Frequency
+	m.Model = req.Model
+	if m.Model == "" {
+		m.Model = ModelBasic
+	}
+	m.ResultLimit = req.Limit
+	if m.ResultLimit == 0 {
+		m.ResultLimit = defaultGlobalLimit
+	}
+	return nil
+}
+
+// ListMonitors 分页获取当前用户的监听任务
+// GET /api/monitors
+func (h *Handler) ListMonitors(c echo.Context) error {
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var query PageQuery
+	if err := c.Bind(&query); err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid parameters")
+	}
+	if err := utils.ValidateRequest(c, &query); err != nil {
+		return err
+	}
+	query.normalize(defaultMonitorPageSize)
+
+	db := h.db.Model(&Monitor{}).Where("user_id = ?", currentUser.ID)
+
+	var total int64
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to count monitors")
+	}
+
+	var monitors []Monitor
+	if err := db.Order("created_at DESC, id DESC").
+		Offset(query.offset()).
+		Limit(query.PageSize).
+		Find(&monitors).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to get monitors")
+	}
+
+	return utils.Success(c, map[string]interface{}{
+		"total":     total,
+		"page":      query.Page,
+		"page_size": query.PageSize,
+		"count":     len(monitors),
+		"monitors":  monitors,
+	})
+}
+
+// CreateMonitor 创建监听任务，创建后在下一次调度时立即运行
+// POST /api/monitors
+func (h *Handler) CreateMonitor(c echo.Context) error {
+	req, err := bindMonitorRequest(c)
+	if req == nil {
+		return err
+	}
+
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var count int64
+	if err := h.db.Model(&Monitor{}).Where("user_id = ?", currentUser.ID).Count(&count).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to count monitors")
+	}
+	if count >= int64(h.cfg.MonitorLimit) {
+		return utils.Fail(c, http.StatusForbidden,
+			fmt.Sprintf("Monitor limit reached (%d monitors), delete a monitor first", h.cfg.MonitorLimit))
+	}
+
+	now := time.Now()
+	monitor := Monitor{
+		UserID:    currentUser.ID,
+		Status:    MonitorStatusActive,
+		NextRunAt: &now,
+	}
+	if err := req.apply(&monitor); err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid source agencies")
+	}
+
+	if err := h.db.Create(&monitor).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to create monitor")
+	}
+
+	return utils.Success(c, monitor)
+}
+
+// GetMonitor 获取监听任务详情
+// GET /api/monitors/:id
+func (h *Handler) GetMonitor(c echo.Context) error {
+	monitor, err := h.findUserMonitor(c)
+	if monitor == nil {
+		return err
+	}
+	return utils.Success(c, monitor)
+}
+
+// UpdateMonitor 修改监听任务
+// PUT /api/monitors/:id
+// 修改频率后，下次运行时间按上次运行时间重新计算
+func (h *Handler) UpdateMonitor(c echo.Context) error {
+	req, err := bindMonitorRequest(c)
+	if req == nil {
+		return err
+	}
+
+	monitor, err := h.findUserMonitor(c)
+	if monitor == nil {
+		return err
+	}
+
+	if err := req.apply(monitor); err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid source agencies")
+	}
+	if monitor.Status == MonitorStatusActive && monitor.LastRunAt != nil {
+		next := monitor.LastRunAt.Add(monitor.interval())
+		monitor.NextRunAt = &next
+	}
+
+	if err := h.db.Save(monitor).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to update monitor")
+	}
+
+	return utils.Success(c, monitor)
+}
+
+// DeleteMonitor 删除监听任务，已产生的搜索会话与命中记录保留
+// DELETE /api/monitors/:id
+func (h *Handler) DeleteMonitor(c echo.Context) error {
+	monitor, err := h.findUserMonitor(c)
+	if monitor == nil {
+		return err
+	}
+
+	if err := h.db.Delete(monitor).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to delete monitor")
+	}
+
+	return utils.Success(c, nil)
+}
+
+// PauseMonitor 暂停监听任务
+// POST /api/monitors/:id/pause
+func (h *Handler) PauseMonitor(c echo.Context) error {
+	monitor, err := h.findUserMonitor(c)
+	if monitor == nil {
+		return err
+	}
+
+	if monitor.Status != MonitorStatusPaused {
+		if err := h.db.Model(monitor).Update("status", MonitorStatusPaused).Error; err != nil {
+			return utils.Error(c, http.StatusInternalServerError, "Failed to pause monitor")
+		}
+	}
+
+	return utils.Success(c, monitor)
+}
+
+// ResumeMonitor 恢复监听任务，暂停期间错过的运行在下一次调度时补跑一次
+// POST /api/monitors/:id/resume
+func (h *Handler) ResumeMonitor(c echo.Context) error {
+	monitor, err := h.findUserMonitor(c)
+	if monitor == nil {
+		return err
+	}
+
+	if monitor.Status != MonitorStatusActive {
+		now := time.Now()
+		next := monitor.NextRunAt
+		if next == nil || next.Before(now) {
+			next = &now
+		}
+		if err := h.db.Model(monitor).Updates(map[string]interface{}{
+			"status":      MonitorStatusActive,
+			"next_run_at": next,
+		}).Error; err != nil {
+			return utils.Error(c, http.StatusInternalServerError, "Failed to resume monitor")
+		}
+	}
+
+	return utils.Success(c, monitor)
+}
+
+// GetMonitorRuns 分页获取监听任务的运行记录（即其发起的搜索会话）
+// GET /api/monitors/:id/runs
+func (h *Handler) GetMonitorRuns(c echo.Context) error {
+	monitor, err := h.findUserMonitor(c)
+	if monitor == nil {
+		return err
+	}
+
+	var query PageQuery
+	if err := c.Bind(&query); err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid parameters")
+	}
+	if err := utils.ValidateRequest(c, &query); err != nil {
+		return err
+	}
+	query.normalize(defaultMonitorPageSize)
+
+	db := h.db.Model(&SearchSession{}).Where("monitor_id = ?", monitor.ID)
+
+	var total int64
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to count monitor runs")
+	}
+
+	var sessions []SearchSession
+	if err := db.Order("created_at DESC").
+		Offset(query.offset()).
+		Limit(query.PageSize).
+		Find(&sessions).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to get monitor runs")
+	}
+
+	return utils.Success(c, map[string]interface{}{
+		"total":     total,
+		"page":      query.Page,
+		"page_size": query.PageSize,
+		"count":     len(sessions),
+		"runs":      sessions,
+	})
+}
+
+// GetMonitorHits 分页获取监听任务的新增结果，可按运行（session_id）过滤
+// GET /api/monitors/:id/hits
+func (h *Handler) GetMonitorHits(c echo.Context) error {
+	monitor, err := h.findUserMonitor(c)
+	if monitor == nil {
+		return err
+	}
+
+	var query MonitorHitsQuery
+	if err := c.Bind(&query); err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid parameters")
+	}
+	if err := utils.ValidateRequest(c, &query); err != nil {
+		return err
+	}
+	query.normalize(defaultMonitorPageSize)
+
+	db := h.db.Model(&MonitorHit{}).Where("monitor_id = ?", monitor.ID)
+	if query.SessionID != "" {
+		db = db.Where("session_id = ?", query.SessionID)
+	}
+
+	var total int64
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to count monitor hits")
+	}
+
+	var hits []MonitorHit
+	if err := db.Order("created_at DESC, id DESC").
+		Offset(query.offset()).
+		Limit(query.PageSize).
+		Find(&hits).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to get monitor hits")
+	}
+
+	return utils.Success(c, map[string]interface{}{
+		"total":     total,
+		"page":      query.Page,
+		"page_size": query.PageSize,
+		"count":     len(hits),
+		"hits":      hits,
+	})
+}
+
+// RunDueMonitors 启动所有已到期的监听任务，返回成功启动的数量
+// 每次运行与用户手动检索走相同的流程：按监听任务的模型档位冻结积分、创建搜索会话、交给后台工作池抓取
+func (h *Handler) RunDueMonitors() (int, error) {
+	now := time.Now()
+
+	var monitors []Monitor
+	if err := h.db.Where("status = ? AND next_run_at <= ?", MonitorStatusActive, now).
+		Order("next_run_at ASC").
+		Limit(monitorBatchSize).
+		Find(&monitors).Error; err != nil {
+		return 0, err
+	}
+
+	started := 0
+	for i := range monitors {
+		monitor := &monitors[i]
+
+		// 先推进下次运行时间，条件更新保证同一到期时间只运行一次
+		next := now.Add(monitor.interval())
+		claim := h.db.Model(&Monitor{}).
+			Where("id = ? AND status = ? AND next_run_at <= ?", monitor.ID, MonitorStatusActive, now).
+			Updates(map[string]interface{}{
+				"next_run_at": &next,
+				"last_run_at": &now,
+				"run_count":   gorm.Expr("run_count + 1"),
+			})
+		if claim.Error != nil {
+			log.Printf("Failed to schedule monitor %d: %v\n", monitor.ID, claim.Error)
+			continue
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+
+		fields := map[string]interface{}{"last_error": ""}
+		session, err := h.startGlobalSearch(monitor.UserID, monitor.searchRequest(), monitor.ID)
+		if err != nil {
+			log.Printf("Failed to run monitor %d: %v\n", monitor.ID, err)
+			fields["last_error"] = truncateError(err)
+		} else {
+			fields["last_session_id"] = session.ID
+			started++
+		}
+		if err := h.db.Model(&Monitor{}).Where("id = ?", monitor.ID).Updates(fields).Error; err != nil {
+			log.Printf("Failed to update monitor %d: %v\n", monitor.ID, err)
+		}
+	}
+
+	return started, nil
+}
+
+// recordMonitorHits 记录监听任务本次运行中首次出现的结果，返回新增条数
+// 以 DataHash 判断是否出现过，同一结果只在首次出现的运行中计为新增
+func (h *Handler) recordMonitorHits(monitorID uint, sessionID string) (int, error) {
+	var buffers []SearchBuffer
+	if err := h.db.Select("id, data_hash, preview_title, canonical_url").
+		Where("session_id = ?", sessionID).
+		Order("id ASC").
+		Find(&buffers).Error; err != nil {
+		return 0, err
+	}
+
+	newCount := 0
+	for _, b := range buffers {
+		hit := MonitorHit{
+			MonitorID: monitorID,
+			DataHash:  b.DataHash,
+			SessionID: sessionID,
+			BufferID:  b.ID,
+			Title:     b.PreviewTitle,
+			URL:       b.CanonicalURL,
+		}
+		result := h.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&hit)
+		if result.Error != nil {
+			return newCount, result.Error
+		}
+		if result.RowsAffected > 0 {
+			newCount++
+		}
+	}
+
+	err := h.db.Model(&Monitor{}).Where("id = ?", monitorID).
+		Update("last_new_count", newCount).Error
+	return newCount, err
+}
+
+// failMonitorRun 监听任务发起的会话失败时记录错误信息
+func (h *Handler) failMonitorRun(sessionID string, cause error) {
+	var session SearchSession
+	if err := h.db.Select("id, monitor_id").First(&session, "id = ?", sessionID).Error; err != nil ||
+		session.MonitorID == nil {
+		return
+	}
+
+	if err := h.db.Model(&Monitor{}).Where("id = ?", *session.MonitorID).
+		Update("last_error", truncateError(cause)).Error; err != nil {
+		log.Printf("Failed to update monitor %d: %v\n", *session.MonitorID, err)
+	}
+}
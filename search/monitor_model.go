@@ -0,0 +1,120 @@
+package search
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 监听任务状态
+const (
+	MonitorStatusActive = "active" // 运行中，到期后由定时任务执行
+	MonitorStatusPaused = "paused" // 已暂停
+)
+
+// 监听频率
+const (
+	MonitorFrequencyHourly = "hourly"
+	MonitorFrequencyDaily  = "daily"
+	MonitorFrequencyWeekly = "weekly"
+)
+
+// monitorIntervals 各监听频率对应的运行间隔
+var monitorIntervals = map[string]time.Duration{
+	MonitorFrequencyHourly: time.Hour,
+	MonitorFrequencyDaily:  24 * time.Hour,
+	MonitorFrequencyWeekly: 7 * 24 * time.Hour,
+}
+
+// Monitor 关键词监听任务（智能监听）
+// 按频率定期发起全网检索，每次运行创建一个搜索会话，并记录相对此前运行新增的结果
+type Monitor struct {
+	ID             uint            `gorm:"primaryKey" json:"id"`
+	UserID         uint            `gorm:"index;comment:创建者ID" json:"user_id"`
+	Name           string          `gorm:"type:varchar(100);comment:监听名称" json:"name"`
+	Keywords       string          `gorm:"type:varchar(500);not null;comment:检索关键词" json:"keywords"`
+	SourceAgencies json.RawMessage `gorm:"type:json;comment:限定的来源机构ID列表" json:"source_agencies"`
+	Frequency      string          `gorm:"type:varchar(20);comment:频率:hourly,daily,weekly" json:"frequency"`
+	Model          string          `gorm:"type:varchar(20);comment:检索模型档位，决定每次运行的费用" json:"model"`
+	ResultLimit    int             `gorm:"comment:每次运行的结果上限" json:"limit"`
+	Status         string          `gorm:"type:varchar(20);index;default:'active';comment:状态:active运行中,paused已暂停" json:"status"`
+
+	// 运行情况
+	NextRunAt     *time.Time `gorm:"index;comment:下次运行时间" json:"next_run_at,omitempty"`
+	LastRunAt     *time.Time `gorm:"comment:上次运行时间" json:"last_run_at,omitempty"`
+	LastSessionID string     `gorm:"type:varchar(64);comment:上次运行的搜索会话ID" json:"last_session_id,omitempty"`
+	LastNewCount  int        `gorm:"default:0;comment:上次运行的新增条数" json:"last_new_count"`
+	RunCount      int        `gorm:"default:0;comment:累计运行次数" json:"run_count"`
+	LastError     string     `gorm:"type:varchar(500);comment:上次运行的错误信息" json:"last_error,omitempty"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName 指定表名
+func (Monitor) TableName() string {
+	return "monitors"
+}
+
+// AgencyIDs 解析来源机构ID列表
+func (m *Monitor) AgencyIDs() []uint {
+	var ids []uint
+	if len(m.SourceAgencies) > 0 {
+		_ = json.Unmarshal(m.SourceAgencies, &ids)
+	}
+	return ids
+}
+
+// interval 运行间隔
+func (m *Monitor) interval() time.Duration {
+	if d, ok := monitorIntervals[m.Frequency]; ok {
+		return d
+	}
+	return monitorIntervals[MonitorFrequencyDaily]
+}
+
+// searchRequest 每次运行使用的检索参数
+func (m *Monitor) searchRequest() SearchRequest {
+	return SearchRequest{
+		Q:         m.Keywords,
+		Scope:     "global",
+		AgencyIDs: m.AgencyIDs(),
+		Model:     m.Model,
+		Limit:     m.ResultLimit,
+	}
+}
+
+// MonitorHit 监听任务命中的结果，按 DataHash 去重，记录首次出现的运行
+type MonitorHit struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	MonitorID uint      `gorm:"uniqueIndex:idx_monitor_hit;comment:监听任务ID" json:"monitor_id"`
+	DataHash  string    `gorm:"type:varchar(64);uniqueIndex:idx_monitor_hit;comment:内容哈希" json:"data_hash"`
+	SessionID string    `gorm:"type:varchar(64);index;comment:首次出现的搜索会话ID" json:"session_id"`
+	BufferID  uint      `gorm:"comment:首次出现的缓冲区记录ID" json:"buffer_id"`
+	Title     string    `gorm:"type:varchar(500)" json:"title"`
+	URL       string    `gorm:"type:text" json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (MonitorHit) TableName() string {
+	return "monitor_hits"
+}
+
+// MonitorRequest 创建/修改监听任务请求
+type MonitorRequest struct {
+	Name           string `json:"name" validate:"omitempty,max=100"`
+	Keywords       string `json:"keywords" validate:"required,max=500"`
+	SourceAgencies []uint `json:"source_agencies" validate:"omitempty,max=50"`
+	Frequency      string `json:"frequency" validate:"required,oneof=hourly daily weekly"`
+	Model          string `json:"model" validate:"omitempty,oneof=basic advanced pro"`
+	Limit          int    `json:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// MonitorHitsQuery 监听命中列表查询参数
+type MonitorHitsQuery struct {
+	PageQuery
+	SessionID string `query:"session_id"` // 只看某次运行新增的结果
+}
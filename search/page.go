@@ -0,0 +1,124 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"policy-backend/extract"
+	"policy-backend/intelligence"
+	"policy-backend/safehttp"
+)
+
+// pageFetcher 抓取检索结果的原网页并抽取正文
+// 订阅源通常只提供一小段摘要，抽取后 content 为网页正文，原摘要保留在 summary 中
+type pageFetcher struct {
+	client      *http.Client
+	maxSize     int64
+	concurrency int
+}
+
+// newPageFetcher 按配置创建网页抓取器，未启用时返回 nil
+func newPageFetcher(cfg Config) *pageFetcher {
+	if !cfg.PageFetchEnabled {
+		return nil
+	}
+	concurrency := cfg.PageFetchConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &pageFetcher{
+		client:      safehttp.NewClient(time.Duration(cfg.PageFetchTimeoutSeconds) * time.Second),
+		maxSize:     int64(cfg.PageMaxSizeKB) << 10,
+		concurrency: concurrency,
+	}
+}
+
+// enrich 并发抓取结果对应的网页，把抽取到的字段合并进原始结果
+// 单个网页抓取或抽取失败时保留数据源提供的字段
+func (f *pageFetcher) enrich(ctx context.Context, results []map[string]interface{}) {
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, f.concurrency)
+	)
+	for _, raw := range results {
+		link, _ := raw["url"].(string)
+		if !isHTTPURL(link) || intelligence.IsPDFLink(link) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(raw map[string]interface{}, link string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			article, err := f.fetch(ctx, link)
+			if err != nil {
+				log.Printf("Failed to extract page %s: %v\n", link, err)
+				return
+			}
+			mergeArticle(raw, article)
+		}(raw, link)
+	}
+	wg.Wait()
+}
+
+// fetch 下载网页并抽取正文，只处理 HTML 响应
+func (f *pageFetcher) fetch(ctx context.Context, link string) (*extract.Article, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/html, application/xhtml+xml;q=0.9")
+	req.Header.Set("User-Agent", "policy-backend/1.0 (+page fetcher)")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil &&
+		mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("unsupported content type %q", mediaType)
+	}
+
+	return extract.Extract(io.LimitReader(resp.Body, f.maxSize), contentType)
+}
+
+// mergeArticle 合并抽取结果：正文比数据源提供的内容更完整时替换 content，
+// 原内容作为 summary 保留；标题、发布日期仅在数据源未提供时补全
+func mergeArticle(raw map[string]interface{}, article *extract.Article) {
+	snippet, _ := raw["content"].(string)
+	if utf8.RuneCountInString(article.Content) > utf8.RuneCountInString(snippet) {
+		raw["content"] = article.Content
+		if snippet != "" {
+			raw["summary"] = snippet
+		} else {
+			raw["summary"] = article.Excerpt
+		}
+	}
+	if title, _ := raw["title"].(string); title == "" && article.Title != "" {
+		raw["title"] = article.Title
+	}
+	if date, _ := raw["publish_date"].(string); date == "" && !article.PublishDate.IsZero() {
+		raw["publish_date"] = article.PublishDate.Format(time.RFC3339)
+	}
+	if article.Author != "" {
+		raw["author"] = article.Author
+	}
+	if article.Language != "" {
+		raw["language"] = article.Language
+	}
+}
@@ -0,0 +1,96 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// SearchProvider 检索数据源接口
+// 每个数据源负责抓取并把结果归一化为 saveToBuffer 可直接消费的 map 结构：
+// title, source, url, content, publish_date(RFC3339)
+type SearchProvider interface {
+	// Name 数据源唯一名称
+	Name() string
+	// Search 执行检索，返回归一化后的原始结果
+	Search(ctx context.Context, req SearchRequest) ([]map[string]interface{}, error)
+}
+
+// ProviderRegistry 检索数据源注册表
+type ProviderRegistry struct {
+	mu        sync.RWMutex
+	providers map[string]SearchProvider
+	order     []string // 保持注册顺序，保证结果合并顺序稳定
+}
+
+// NewProviderRegistry 创建新的数据源注册表
+func NewProviderRegistry() *ProviderRegistry {
+	return &ProviderRegistry{
+		providers: make(map[string]SearchProvider),
+	}
+}
+
+// Register 注册数据源，同名数据源会被覆盖
+func (r *ProviderRegistry) Register(p SearchProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.providers[p.Name()]; !exists {
+		r.order = append(r.order, p.Name())
+	}
+	r.providers[p.Name()] = p
+}
+
+// Get 根据名称获取数据源
+func (r *ProviderRegistry) Get(name string) (SearchProvider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Providers 按注册顺序返回所有数据源
+func (r *ProviderRegistry) Providers() []SearchProvider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]SearchProvider, 0, len(r.order))
+	for _, name := range r.order {
+		list = append(list, r.providers[name])
+	}
+	return list
+}
+
+// Search 依次调用所有数据源并合并结果
+// 单个数据源失败不影响其他数据源；全部失败时返回最后一个错误
+func (r *ProviderRegistry) Search(ctx context.Context, req SearchRequest) ([]map[string]interface{}, error) {
+	providers := r.Providers()
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("no search provider registered")
+	}
+
+	var (
+		results []map[string]interface{}
+		lastErr error
+		failed  int
+	)
+	for _, p := range providers {
+		items, err := p.Search(ctx, req)
+		if err != nil {
+			lastErr = fmt.Errorf("provider %s: %w", p.Name(), err)
+			failed++
+			continue
+		}
+		results = append(results, items...)
+	}
+
+	if failed == len(providers) {
+		return nil, lastErr
+	}
+
+	if req.Limit > 0 && len(results) > req.Limit {
+		results = results[:req.Limit]
+	}
+	return results, nil
+}
@@ -0,0 +1,383 @@
+package search
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"policy-backend/org"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RSSProviderName RSS/Atom 数据源名称
+const RSSProviderName = "rss"
+
+// defaultFeedPaths 常见的订阅源路径，按顺序尝试，命中第一个可解析的即停止
+var defaultFeedPaths = []string{
+	"/feed",
+	"/rss",
+	"/rss.xml",
+	"/feed.xml",
+	"/atom.xml",
+	"/index.xml",
+}
+
+const (
+	rssFetchTimeout   = 15 * time.Second
+	rssMaxConcurrency = 8
+	rssMaxBodySize    = 10 << 20 // 单个订阅源最大 10MB
+)
+
+// RSSProvider 从机构官网的 RSS/Atom 订阅源抓取情报
+type RSSProvider struct {
+	db        *gorm.DB
+	client    *http.Client
+	feedPaths []string
+}
+
+// NewRSSProvider 创建 RSS/Atom 数据源
+// client 为 nil 时使用带超时的默认客户端
+func NewRSSProvider(db *gorm.DB, client *http.Client) *RSSProvider {
+	if client == nil {
+		client = &http.Client{Timeout: rssFetchTimeout}
+	}
+	return &RSSProvider{
+		db:        db,
+		client:    client,
+		feedPaths: defaultFeedPaths,
+	}
+}
+
+// Name 实现 SearchProvider 接口
+func (p *RSSProvider) Name() string {
+	return RSSProviderName
+}
+
+// feedSite 一个待抓取的站点（多个机构可能共用同一域名）
+type feedSite struct {
+	Domain     string
+	AgencyName string
+}
+
+// Search 实现 SearchProvider 接口
+func (p *RSSProvider) Search(ctx context.Context, req SearchRequest) ([]map[string]interface{}, error) {
+	sites, err := p.loadSites(req.agencyFilter())
+	if err != nil {
+		return nil, err
+	}
+
+	terms := strings.Fields(strings.ToLower(req.Q))
+	dateFrom, _ := time.Parse("2006-01-02", req.DateFrom)
+	dateTo, _ := time.Parse("2006-01-02", req.DateTo)
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results []map[string]interface{}
+		sem     = make(chan struct{}, rssMaxConcurrency)
+	)
+	for _, site := range sites {
+		wg.Add(1)
+		go func(site feedSite) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			items := p.fetchSite(ctx, site)
+			for _, item := range items {
+				if !matchTerms(item, terms) {
+					continue
+				}
+				if !dateFrom.IsZero() && item.Published.Before(dateFrom) {
+					continue
+				}
+				if !dateTo.IsZero() && item.Published.After(dateTo.Add(24*time.Hour)) {
+					continue
+				}
+				mu.Lock()
+				results = append(results, item.toRaw(site.AgencyName))
+				mu.Unlock()
+			}
+		}(site)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// loadSites 读取机构域名并按域名去重
+func (p *RSSProvider) loadSites(agencyIDs []uint) ([]feedSite, error) {
+	var agencies []org.Agency
+	query := p.db.Where("domain <> ''").Order("id ASC")
+	if len(agencyIDs) > 0 {
+		query = query.Where("id IN ?", agencyIDs)
+	}
+	if err := query.Find(&agencies).Error; err != nil {
+		return nil, err
+	}
+
+	// 多个机构共用同一域名时无法确定条目归属，来源记为域名，交由机构识别按标题消歧
+	index := make(map[string]int)
+	sites := []feedSite{}
+	for _, a := range agencies {
+		domain := strings.TrimRight(strings.TrimSpace(a.Domain), "/")
+		if domain == "" {
+			continue
+		}
+		if i, ok := index[domain]; ok {
+			sites[i].AgencyName = domain
+			continue
+		}
+		index[domain] = len(sites)
+		sites = append(sites, feedSite{Domain: domain, AgencyName: a.Name})
+	}
+	return sites, nil
+}
+
+// siteBaseURL 将机构域名转换为站点根地址
+// 域名中已包含协议时原样使用（便于指向测试服务器）
+func siteBaseURL(domain string) string {
+	if strings.Contains(domain, "://") {
+		return domain
+	}
+	return "https://" + domain
+}
+
+// fetchSite 依次尝试常见订阅源路径，返回第一个可解析订阅源的条目
+func (p *RSSProvider) fetchSite(ctx context.Context, site feedSite) []feedItem {
+	base := siteBaseURL(site.Domain)
+	for _, path := range p.feedPaths {
+		items, err := p.fetchFeed(ctx, base+path)
+		if err == nil {
+			return items
+		}
+	}
+	return nil
+}
+
+// fetchFeed 下载并解析单个订阅源
+func (p *RSSProvider) fetchFeed(ctx context.Context, feedURL string) ([]feedItem, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, text/xml;q=0.8")
+	req.Header.Set("User-Agent", "policy-backend/1.0 (+feed fetcher)")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, rssMaxBodySize))
+	if err != nil {
+		return nil, err
+	}
+	return parseFeed(body)
+}
+
+// feedItem 归一化后的订阅条目
+type feedItem struct {
+	Title     string
+	Link      string
+	Content   string
+	Published time.Time
+	PDFURL    string // 类型为 application/pdf 的附件（RSS enclosure / Atom rel="enclosure"）
+}
+
+// toRaw 转换为 saveToBuffer 消费的原始结构
+func (it feedItem) toRaw(source string) map[string]interface{} {
+	raw := map[string]interface{}{
+		"title":    it.Title,
+		"source":   source,
+		"url":      it.Link,
+		"content":  it.Content,
+		"provider": RSSProviderName,
+	}
+	if !it.Published.IsZero() {
+		raw["publish_date"] = it.Published.Format(time.RFC3339)
+	}
+	if it.PDFURL != "" {
+		raw["pdf_url"] = it.PDFURL
+	}
+	return raw
+}
+
+// matchTerms 判断条目是否包含全部检索词（不区分大小写）
+func matchTerms(it feedItem, terms []string) bool {
+	text := strings.ToLower(it.Title + " " + it.Content)
+	for _, t := range terms {
+		if !strings.Contains(text, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// rssDocument RSS 2.0 文档结构
+type rssDocument struct {
+	XMLName xml.Name `xml:"rss"`
+	Channel struct {
+		Items []struct {
+			Title       string `xml:"title"`
+			Link        string `xml:"link"`
+			Description string `xml:"description"`
+			Encoded     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+			PubDate     string `xml:"pubDate"`
+			Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+			Enclosures  []struct {
+				URL  string `xml:"url,attr"`
+				Type string `xml:"type,attr"`
+			} `xml:"enclosure"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+// atomDocument Atom 文档结构
+type atomDocument struct {
+	XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+	Entries []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+			Type string `xml:"type,attr"`
+		} `xml:"link"`
+		Summary   string `xml:"summary"`
+		Content   string `xml:"content"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+// parseFeed 解析 RSS 2.0 或 Atom 订阅源
+func parseFeed(data []byte) ([]feedItem, error) {
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	switch root.XMLName.Local {
+	case "rss":
+		var doc rssDocument
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		items := make([]feedItem, 0, len(doc.Channel.Items))
+		for _, it := range doc.Channel.Items {
+			content := it.Description
+			if content == "" {
+				content = it.Encoded
+			}
+			date := it.PubDate
+			if date == "" {
+				date = it.Date
+			}
+			pdfURL := ""
+			for _, enc := range it.Enclosures {
+				if isPDFType(enc.Type) {
+					pdfURL = strings.TrimSpace(enc.URL)
+					break
+				}
+			}
+			items = append(items, feedItem{
+				Title:     cleanText(it.Title),
+				Link:      strings.TrimSpace(it.Link),
+				Content:   cleanText(content),
+				Published: parseFeedDate(date),
+				PDFURL:    pdfURL,
+			})
+		}
+		return items, nil
+	case "feed":
+		var doc atomDocument
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		items := make([]feedItem, 0, len(doc.Entries))
+		for _, e := range doc.Entries {
+			link, pdfURL := "", ""
+			for _, l := range e.Links {
+				switch {
+				case (l.Rel == "" || l.Rel == "alternate") && link == "":
+					link = l.Href
+				case l.Rel == "enclosure" && isPDFType(l.Type) && pdfURL == "":
+					pdfURL = strings.TrimSpace(l.Href)
+				}
+			}
+			content := e.Summary
+			if content == "" {
+				content = e.Content
+			}
+			date := e.Published
+			if date == "" {
+				date = e.Updated
+			}
+			items = append(items, feedItem{
+				Title:     cleanText(e.Title),
+				Link:      strings.TrimSpace(link),
+				Content:   cleanText(content),
+				Published: parseFeedDate(date),
+				PDFURL:    pdfURL,
+			})
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unsupported feed root element %q", root.XMLName.Local)
+	}
+}
+
+// isPDFType 附件的 MIME 类型是否为 PDF
+func isPDFType(mimeType string) bool {
+	return strings.EqualFold(strings.TrimSpace(strings.SplitN(mimeType, ";", 2)[0]), "application/pdf")
+}
+
+// feedDateLayouts 订阅源中常见的日期格式
+var feedDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	time.RFC822Z,
+	time.RFC822,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// parseFeedDate 尝试多种格式解析日期，失败返回零值
+func parseFeedDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range feedDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+var (
+	tagPattern   = regexp.MustCompile(`<[^>]*>`)
+	spacePattern = regexp.MustCompile(`\s+`)
+)
+
+// cleanText 去除 HTML 标签、反转义实体并压缩空白
+func cleanText(s string) string {
+	s = tagPattern.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	return strings.TrimSpace(spacePattern.ReplaceAllString(s, " "))
+}
@@ -0,0 +1,257 @@
+package search
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"policy-backend/org"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseFeed(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		want    []feedItem
+	}{
+		{
+			name:    "rss 2.0",
+			fixture: "feed_rss.xml",
+			want: []feedItem{
+				{
+					Title:     "NSF announces quantum computing program",
+					Link:      "https://www.nsf.gov/news/quantum",
+					Content:   "New funding for quantum & AI research.",
+					Published: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+					PDFURL:    "https://www.nsf.gov/files/quantum.pdf",
+				},
+				{
+					Title:     "Budget request for fiscal year 2025",
+					Link:      "https://www.nsf.gov/news/budget",
+					Content:   "Full budget text",
+					Published: time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			name:    "atom",
+			fixture: "feed_atom.xml",
+			want: []feedItem{
+				{
+					Title:     "DARPA quantum benchmarking initiative",
+					Link:      "https://www.darpa.mil/news/qbi",
+					Content:   "Evaluating quantum computers",
+					Published: time.Date(2024, 2, 10, 12, 0, 0, 0, time.UTC),
+					PDFURL:    "https://www.darpa.mil/files/qbi.pdf",
+				},
+				{
+					Title:     "Microelectronics program update",
+					Link:      "https://www.darpa.mil/news/micro",
+					Content:   "Chips and packaging",
+					Published: time.Date(2024, 4, 5, 1, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFeed(readFixture(t, tt.fixture))
+			if err != nil {
+				t.Fatalf("parseFeed() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseFeed() returned %d items, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if !got[i].Published.Equal(tt.want[i].Published) {
+					t.Errorf("item %d published = %v, want %v", i, got[i].Published, tt.want[i].Published)
+				}
+				got[i].Published, tt.want[i].Published = time.Time{}, time.Time{}
+				if got[i] != tt.want[i] {
+					t.Errorf("item %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseFeedInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"not xml", "not a feed"},
+		{"unsupported root", `<?xml version="1.0"?><html><body/></html>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseFeed([]byte(tt.data)); err == nil {
+				t.Error("parseFeed() error = nil, want error")
+			}
+		})
+	}
+}
+
+// feedServer 只在 path 上返回订阅源，其余路径返回 404，用于验证按常见路径依次尝试
+func feedServer(t *testing.T, path string, body []byte) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newFeedTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&org.Country{}, &org.Agency{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestRSSProviderSearch(t *testing.T) {
+	rssSrv := feedServer(t, "/feed", readFixture(t, "feed_rss.xml"))
+	atomSrv := feedServer(t, "/atom.xml", readFixture(t, "feed_atom.xml"))
+
+	db := newFeedTestDB(t)
+	db.Create(&org.Country{Name: "美国", Code: "US"})
+	nsf := org.Agency{Name: "National Science Foundation", CountryID: 1, Domain: rssSrv.URL}
+	darpa := org.Agency{Name: "DARPA", CountryID: 1, Domain: atomSrv.URL + "/"}
+	db.Create(&nsf)
+	db.Create(&darpa)
+	db.Create(&org.Agency{Name: "No Domain", CountryID: 1})
+
+	p := NewRSSProvider(db, rssSrv.Client())
+
+	tests := []struct {
+		name string
+		req  SearchRequest
+		want []string
+	}{
+		{
+			name: "all items",
+			req:  SearchRequest{},
+			want: []string{
+				"Budget request for fiscal year 2025",
+				"DARPA quantum benchmarking initiative",
+				"Microelectronics program update",
+				"NSF announces quantum computing program",
+			},
+		},
+		{
+			name: "terms match title or content case-insensitively",
+			req:  SearchRequest{Q: "QUANTUM"},
+			want: []string{"DARPA quantum benchmarking initiative", "NSF announces quantum computing program"},
+		},
+		{
+			name: "all terms required",
+			req:  SearchRequest{Q: "quantum funding"},
+			want: []string{"NSF announces quantum computing program"},
+		},
+		{
+			name: "agency filter",
+			req:  SearchRequest{AgencyID: darpa.ID},
+			want: []string{"DARPA quantum benchmarking initiative", "Microelectronics program update"},
+		},
+		{
+			name: "date range",
+			req:  SearchRequest{DateFrom: "2024-02-01", DateTo: "2024-03-01"},
+			want: []string{"Budget request for fiscal year 2025", "DARPA quantum benchmarking initiative"},
+		},
+		{
+			name: "no match",
+			req:  SearchRequest{Q: "fusion"},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := p.Search(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			var titles []string
+			for _, r := range results {
+				titles = append(titles, r["title"].(string))
+			}
+			sort.Strings(titles)
+			if !reflect.DeepEqual(titles, tt.want) {
+				t.Errorf("Search() titles = %q, want %q", titles, tt.want)
+			}
+		})
+	}
+
+	t.Run("raw fields", func(t *testing.T) {
+		results, err := p.Search(context.Background(), SearchRequest{Q: "funding"})
+		if err != nil || len(results) != 1 {
+			t.Fatalf("Search() = %v, %v, want one result", results, err)
+		}
+		want := map[string]interface{}{
+			"title":        "NSF announces quantum computing program",
+			"source":       "National Science Foundation",
+			"url":          "https://www.nsf.gov/news/quantum",
+			"content":      "New funding for quantum & AI research.",
+			"provider":     RSSProviderName,
+			"publish_date": "2024-01-15T10:00:00Z",
+			"pdf_url":      "https://www.nsf.gov/files/quantum.pdf",
+		}
+		if !reflect.DeepEqual(results[0], want) {
+			t.Errorf("raw = %v, want %v", results[0], want)
+		}
+	})
+}
+
+func TestRSSProviderSharedDomain(t *testing.T) {
+	srv := feedServer(t, "/rss.xml", readFixture(t, "feed_rss.xml"))
+
+	db := newFeedTestDB(t)
+	db.Create(&org.Agency{Name: "Agency A", CountryID: 1, Domain: srv.URL})
+	db.Create(&org.Agency{Name: "Agency B", CountryID: 1, Domain: srv.URL})
+
+	results, err := NewRSSProvider(db, srv.Client()).Search(context.Background(), SearchRequest{})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	// 同一域名只抓取一次，来源记为域名
+	if len(results) != 2 {
+		t.Fatalf("Search() returned %d results, want 2", len(results))
+	}
+	for _, r := range results {
+		if r["source"] != srv.URL {
+			t.Errorf("source = %v, want %s", r["source"], srv.URL)
+		}
+	}
+}
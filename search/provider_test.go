@@ -0,0 +1,125 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// stubProvider 返回固定结果或错误的数据源
+type stubProvider struct {
+	name  string
+	items []map[string]interface{}
+	err   error
+}
+
+func (p *stubProvider) Name() string { return p.name }
+
+func (p *stubProvider) Search(ctx context.Context, req SearchRequest) ([]map[string]interface{}, error) {
+	return p.items, p.err
+}
+
+func rawTitles(items []map[string]interface{}) []string {
+	titles := make([]string, 0, len(items))
+	for _, it := range items {
+		titles = append(titles, it["title"].(string))
+	}
+	return titles
+}
+
+func TestProviderRegistryLookup(t *testing.T) {
+	r := NewProviderRegistry()
+	rss := &stubProvider{name: RSSProviderName}
+	api := &stubProvider{name: "api"}
+	r.Register(rss)
+	r.Register(api)
+
+	tests := []struct {
+		name   string
+		lookup string
+		want   SearchProvider
+		wantOK bool
+	}{
+		{"registered", RSSProviderName, rss, true},
+		{"second registered", "api", api, true},
+		{"unknown", "google", nil, false},
+		{"empty name", "", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := r.Get(tt.lookup)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Get(%q) = %v, %v, want %v, %v", tt.lookup, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	// 同名数据源覆盖原有实现，注册顺序不变
+	replacement := &stubProvider{name: RSSProviderName}
+	r.Register(replacement)
+	if got, _ := r.Get(RSSProviderName); got != replacement {
+		t.Errorf("Get() after re-register = %v, want replacement", got)
+	}
+	providers := r.Providers()
+	if len(providers) != 2 || providers[0] != replacement || providers[1] != api {
+		t.Errorf("Providers() = %v, want [replacement api]", providers)
+	}
+}
+
+func TestProviderRegistrySearch(t *testing.T) {
+	errDown := errors.New("down")
+	ok1 := &stubProvider{name: "a", items: []map[string]interface{}{{"title": "a1"}, {"title": "a2"}}}
+	ok2 := &stubProvider{name: "b", items: []map[string]interface{}{{"title": "b1"}}}
+	bad := &stubProvider{name: "bad", err: errDown}
+
+	tests := []struct {
+		name      string
+		providers []SearchProvider
+		limit     int
+		want      []string
+		wantErr   bool
+	}{
+		{name: "no providers", wantErr: true},
+		{name: "merged in registration order", providers: []SearchProvider{ok1, ok2}, want: []string{"a1", "a2", "b1"}},
+		{name: "failed provider skipped", providers: []SearchProvider{bad, ok2}, want: []string{"b1"}},
+		{name: "all failed", providers: []SearchProvider{bad}, wantErr: true},
+		{name: "limit", providers: []SearchProvider{ok1, ok2}, limit: 2, want: []string{"a1", "a2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewProviderRegistry()
+			for _, p := range tt.providers {
+				r.Register(p)
+			}
+			got, err := r.Search(context.Background(), SearchRequest{Limit: tt.limit})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Search() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			titles := rawTitles(got)
+			if len(titles) != len(tt.want) {
+				t.Fatalf("Search() = %v, want %v", titles, tt.want)
+			}
+			for i := range titles {
+				if titles[i] != tt.want[i] {
+					t.Errorf("Search() = %v, want %v", titles, tt.want)
+					break
+				}
+			}
+		})
+	}
+
+	t.Run("error names provider", func(t *testing.T) {
+		r := NewProviderRegistry()
+		r.Register(bad)
+		_, err := r.Search(context.Background(), SearchRequest{})
+		if !errors.Is(err, errDown) || err.Error() != "provider bad: down" {
+			t.Errorf("Search() error = %v, want wrapped provider error", err)
+		}
+	})
+}
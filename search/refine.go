@@ -0,0 +1,121 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+
+	"policy-backend/llm"
+)
+
+const (
+	refineConcurrency   = 4    // 同时进行的大模型调用数
+	refineMaxContentLen = 4000 // 发送给大模型的正文最大字符数
+	refineMaxTokens     = 600
+)
+
+// refineSystemPrompt 检索结果精炼的系统提示词
+const refineSystemPrompt = `你是政策情报分析助手。根据用户的检索词判断一条检索结果是否为相关的政策情报，并用简体中文概括。
+只输出 JSON 对象，格式为 {"relevant": true 或 false, "summary": "不超过 150 字的中文摘要", "title_zh": "中文标题，原标题为中文时与原标题相同"}。`
+
+// refineVerdict 大模型对单条结果的判断
+type refineVerdict struct {
+	Relevant *bool  `json:"relevant"`
+	Summary  string `json:"summary"`
+	TitleZh  string `json:"title_zh"`
+}
+
+// refineResults 按检索档位调用大模型：过滤与检索词无关的结果，生成中文摘要并翻译标题
+// 档位未配置大模型时原样返回；单条调用失败或输出无法解析时保留该条结果
+func (h *Handler) refineResults(ctx context.Context, tier, query string, results []map[string]interface{}) ([]map[string]interface{}, llm.Usage) {
+	var total llm.Usage
+	if h.llm == nil || !h.llm.Configured(tier) || len(results) == 0 {
+		return results, total
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, refineConcurrency)
+		keep = make([]bool, len(results))
+	)
+	for i, raw := range results {
+		wg.Add(1)
+		go func(i int, raw map[string]interface{}) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			relevant, usage, err := h.refineResult(ctx, tier, query, raw)
+			if err != nil {
+				log.Printf("Failed to refine search result %v: %v\n", raw["url"], err)
+			}
+			mu.Lock()
+			total.Add(usage)
+			mu.Unlock()
+			keep[i] = relevant
+		}(i, raw)
+	}
+	wg.Wait()
+
+	refined := results[:0]
+	for i, raw := range results {
+		if keep[i] {
+			refined = append(refined, raw)
+		}
+	}
+	return refined, total
+}
+
+// refineResult 精炼单条结果并写回 raw，返回是否保留
+func (h *Handler) refineResult(ctx context.Context, tier, query string, raw map[string]interface{}) (bool, llm.Usage, error) {
+	title, _ := raw["title"].(string)
+	content, _ := raw["content"].(string)
+	if runes := []rune(content); len(runes) > refineMaxContentLen {
+		content = string(runes[:refineMaxContentLen])
+	}
+
+	temperature := 0.0
+	resp, err := h.llm.Chat(ctx, tier, llm.ChatRequest{
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: refineSystemPrompt},
+			{Role: llm.RoleUser, Content: "检索词：" + query + "\n标题：" + title + "\n正文：\n" + content},
+		},
+		Temperature: &temperature,
+		MaxTokens:   refineMaxTokens,
+		JSON:        true,
+	})
+	if err != nil {
+		return true, llm.Usage{}, err
+	}
+
+	var verdict refineVerdict
+	if err := json.Unmarshal([]byte(stripCodeFence(resp.Content)), &verdict); err != nil {
+		return true, resp.Usage, errors.New("unparseable model output")
+	}
+	if verdict.Relevant != nil && !*verdict.Relevant {
+		return false, resp.Usage, nil
+	}
+	if s := strings.TrimSpace(verdict.Summary); s != "" {
+		raw["summary"] = s
+	}
+	if t := strings.TrimSpace(verdict.TitleZh); t != "" && t != title {
+		raw["title_zh"] = t
+	}
+	raw["llm_model"] = resp.Model
+	return true, resp.Usage, nil
+}
+
+// stripCodeFence 去掉模型输出中包裹 JSON 的 ```json 代码块标记
+func stripCodeFence(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "```") {
+		return s
+	}
+	s = strings.TrimPrefix(s, "```")
+	s = strings.TrimPrefix(s, "json")
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "```"))
+}
@@ -0,0 +1,192 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"policy-backend/llm"
+)
+
+// refineReplies 按标题返回固定回复的 Mock：标题出现在用户消息的“标题：”一行
+func refineReplies(replies map[string]string) func(req llm.ChatRequest) (string, error) {
+	return func(req llm.ChatRequest) (string, error) {
+		msg := req.Messages[len(req.Messages)-1].Content
+		for title, reply := range replies {
+			if strings.Contains(msg, "标题："+title+"\n") {
+				if reply == "error" {
+					return "", errors.New("upstream unavailable")
+				}
+				return reply, nil
+			}
+		}
+		return "{}", nil
+	}
+}
+
+// usageRecorder 记录成功调用返回的用量合计
+type usageRecorder struct {
+	llm.Provider
+	mu    sync.Mutex
+	usage llm.Usage
+}
+
+func (r *usageRecorder) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	resp, err := r.Provider.Chat(ctx, req)
+	if err == nil {
+		r.mu.Lock()
+		r.usage.Add(resp.Usage)
+		r.mu.Unlock()
+	}
+	return resp, err
+}
+
+func TestStripCodeFence(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`{"relevant": true}`, `{"relevant": true}`},
+		{"  {\"a\":1}\n", `{"a":1}`},
+		{"```json\n{\"a\":1}\n```", `{"a":1}`},
+		{"```\n{\"a\":1}\n```", `{"a":1}`},
+		{"```json {\"a\":1}```  ", `{"a":1}`},
+		{"```json\n{\"a\":1}", `{"a":1}`},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := stripCodeFence(tt.in); got != tt.want {
+			t.Errorf("stripCodeFence(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRefineResult(t *testing.T) {
+	mock := llm.NewMock("refine-model")
+	mock.Reply = refineReplies(map[string]string{
+		"Quantum":    `{"relevant": true, "summary": " 量子计算资助计划 ", "title_zh": "量子计算"}`,
+		"Fenced":     "```json\n{\"relevant\": true, \"summary\": \"摘要\", \"title_zh\": \"Fenced\"}\n```",
+		"Weather":    `{"relevant": false, "summary": "天气预报"}`,
+		"Broken":     `relevant: yes`,
+		"Down":       "error",
+		"No verdict": `{"summary": "没有判断"}`,
+	})
+	h := &Handler{llm: llm.NewClient(map[string]llm.Provider{llm.TierAdvanced: mock})}
+
+	tests := []struct {
+		title     string
+		wantKeep  bool
+		wantErr   bool
+		wantUsage bool
+		wantRaw   map[string]interface{}
+	}{
+		{
+			title: "Quantum", wantKeep: true, wantUsage: true,
+			wantRaw: map[string]interface{}{"summary": "量子计算资助计划", "title_zh": "量子计算", "llm_model": "refine-model"},
+		},
+		{
+			// 中文标题与原标题相同时不写 title_zh
+			title: "Fenced", wantKeep: true, wantUsage: true,
+			wantRaw: map[string]interface{}{"summary": "摘要", "llm_model": "refine-model"},
+		},
+		{title: "Weather", wantKeep: false, wantUsage: true, wantRaw: map[string]interface{}{}},
+		{title: "Broken", wantKeep: true, wantErr: true, wantUsage: true, wantRaw: map[string]interface{}{}},
+		{title: "Down", wantKeep: true, wantErr: true, wantRaw: map[string]interface{}{}},
+		{
+			title: "No verdict", wantKeep: true, wantUsage: true,
+			wantRaw: map[string]interface{}{"summary": "没有判断", "llm_model": "refine-model"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			raw := map[string]interface{}{"title": tt.title, "content": "body"}
+			keep, usage, err := h.refineResult(context.Background(), llm.TierAdvanced, "quantum", raw)
+			if keep != tt.wantKeep {
+				t.Errorf("keep = %v, want %v", keep, tt.wantKeep)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (usage.TotalTokens > 0) != tt.wantUsage {
+				t.Errorf("usage = %+v, wantUsage %v", usage, tt.wantUsage)
+			}
+			delete(raw, "title")
+			delete(raw, "content")
+			if len(raw) != len(tt.wantRaw) {
+				t.Errorf("raw = %v, want %v", raw, tt.wantRaw)
+			}
+			for k, v := range tt.wantRaw {
+				if raw[k] != v {
+					t.Errorf("raw[%s] = %v, want %v", k, raw[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestRefineResults(t *testing.T) {
+	mock := llm.NewMock("refine-model")
+	mock.Reply = refineReplies(map[string]string{
+		"A": `{"relevant": true, "summary": "a"}`,
+		"B": `{"relevant": false}`,
+		"C": "error",
+		"D": `not json`,
+		"E": `{"relevant": true}`,
+	})
+	counter := &usageRecorder{Provider: mock}
+	h := &Handler{llm: llm.NewClient(map[string]llm.Provider{llm.TierBasic: counter})}
+
+	newResults := func() []map[string]interface{} {
+		var results []map[string]interface{}
+		for _, title := range []string{"A", "B", "C", "D", "E"} {
+			results = append(results, map[string]interface{}{"title": title, "content": strings.Repeat("x", 40)})
+		}
+		return results
+	}
+
+	t.Run("filters irrelevant and keeps failures", func(t *testing.T) {
+		refined, usage := h.refineResults(context.Background(), llm.TierBasic, "q", newResults())
+		var titles []string
+		for _, r := range refined {
+			titles = append(titles, r["title"].(string))
+		}
+		if got := strings.Join(titles, ","); got != "A,C,D,E" {
+			t.Errorf("refined titles = %s, want A,C,D,E", got)
+		}
+
+		// 用量为成功调用的合计（C 调用失败，不计用量）
+		if usage != counter.usage || usage.TotalTokens == 0 {
+			t.Errorf("usage = %+v, want %+v", usage, counter.usage)
+		}
+	})
+
+	tests := []struct {
+		name string
+		h    *Handler
+		tier string
+	}{
+		{name: "unconfigured tier", h: h, tier: llm.TierPro},
+		{name: "unknown tier", h: h, tier: "ultra"},
+		{name: "no llm client", h: &Handler{}, tier: llm.TierBasic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(mock.Requests())
+			results := newResults()
+			refined, usage := tt.h.refineResults(context.Background(), tt.tier, "q", results)
+			if len(refined) != len(results) || usage != (llm.Usage{}) {
+				t.Errorf("refineResults() = %d results, usage %+v, want all results unchanged", len(refined), usage)
+			}
+			for _, r := range refined {
+				if _, ok := r["llm_model"]; ok {
+					t.Errorf("result %v was refined", r["title"])
+				}
+			}
+			if after := len(mock.Requests()); after != before {
+				t.Errorf("model called %d times, want 0", after-before)
+			}
+		})
+	}
+}
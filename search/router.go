@@ -10,9 +10,48 @@ func RegisterRoutes(g *echo.Group, h *Handler) {
 	// 搜索相关接口
 	g.GET("/global", h.GlobalSearch)                 // 全网智能检索
 	g.POST("/check-duplication", h.CheckDuplication) // 查重检测
+	g.GET("/pricing", h.GetPricing)                  // 各模型档位的检索价格
 
 	// 缓冲区相关接口
-	g.POST("/import", h.ImportIntelligences)            // 从缓冲区导入情报到正式库
-	g.GET("/sessions", h.GetSearchSessions)             // 获取搜索会话记录
-	g.GET("/sessions/:id/buffers", h.GetSessionBuffers) // 获取某个会话的缓冲区数据
+	g.POST("/import", h.ImportIntelligences)              // 从缓冲区导入情报到正式库
+	g.POST("/import/file", h.ImportFile)                  // 上传 CSV/XLSX/RIS/BibTeX 文件写入缓冲区
+	g.GET("/sessions", h.GetSearchSessions)               // 获取搜索会话记录
+	g.GET("/sessions/:id/buffers", h.GetSessionBuffers)   // 获取某个会话的缓冲区数据
+	g.GET("/sessions/:id/stream", h.StreamSession)        // 以 SSE 推送会话进度
+	g.GET("/sessions/:id/summary", h.GetSessionSummary)   // 会话缓冲区统计（待处理/已入库/已丢弃）
+	g.POST("/sessions/:id/extend", h.ExtendSession)       // 延长会话缓冲区保留时间
+	g.POST("/sessions/:id/archive", h.ArchiveSession)     // 归档会话（不再自动清理）
+	g.DELETE("/sessions/:id/archive", h.UnarchiveSession) // 取消归档
+
+	// 文件导入列映射接口
+	g.GET("/import/maps", h.ListColumnMaps)         // 获取保存的列映射
+	g.POST("/import/maps", h.CreateColumnMap)       // 保存列映射
+	g.PUT("/import/maps/:id", h.UpdateColumnMap)    // 修改列映射
+	g.DELETE("/import/maps/:id", h.DeleteColumnMap) // 删除列映射
+
+	// 搜索历史接口
+	g.GET("/history", h.GetSearchHistory)              // 分页获取搜索历史
+	g.DELETE("/history", h.ClearSearchHistory)         // 清空搜索历史
+	g.GET("/history/top", h.GetTopQueries)             // 最常用的检索词
+	g.DELETE("/history/:id", h.DeleteSearchHistory)    // 删除单条搜索历史
+	g.POST("/history/:id/rerun", h.RerunSearchHistory) // 以相同参数重新检索
+
+	// 缓冲区分拣接口
+	g.POST("/buffers/:id/discard", h.DiscardBuffer) // 丢弃单条缓冲区记录
+	g.POST("/buffers/:id/restore", h.RestoreBuffer) // 恢复已丢弃的记录
+	g.POST("/buffers/bulk", h.BulkBufferAction)     // 批量丢弃/恢复
+}
+
+// RegisterMonitorRoutes 注册监听任务路由
+// 基础路径: /api/monitors
+func RegisterMonitorRoutes(g *echo.Group, h *Handler) {
+	g.GET("", h.ListMonitors)              // 分页获取监听任务
+	g.POST("", h.CreateMonitor)            // 创建监听任务
+	g.GET("/:id", h.GetMonitor)            // 获取监听任务详情
+	g.PUT("/:id", h.UpdateMonitor)         // 修改监听任务
+	g.DELETE("/:id", h.DeleteMonitor)      // 删除监听任务
+	g.POST("/:id/pause", h.PauseMonitor)   // 暂停监听
+	g.POST("/:id/resume", h.ResumeMonitor) // 恢复监听
+	g.GET("/:id/runs", h.GetMonitorRuns)   // 运行记录（每次运行对应一个搜索会话）
+	g.GET("/:id/hits", h.GetMonitorHits)   // 新增结果
 }
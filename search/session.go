@@ -0,0 +1,246 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// 后台检索任务参数
+const (
+	searchWorkerCount = 4               // 并发执行的检索任务数
+	searchQueueSize   = 100             // 排队中的检索任务上限
+	searchJobTimeout  = 2 * time.Minute // 单个检索任务的超时时间
+)
+
+// errSearchQueueFull 检索队列已满
+var errSearchQueueFull = errors.New("search queue is full")
+
+// searchJob 后台检索任务
+type searchJob struct {
+	SessionID     string
+	UserID        uint
+	Request       SearchRequest
+	ReservationID uint // 积分预授权ID，0 表示免费检索
+	MonitorID     uint // 发起检索的监听任务ID，0 表示用户手动检索
+}
+
+// 会话事件类型（SSE event 字段）
+const (
+	eventProgress = "progress" // 会话状态及计数变化
+	eventBuffer   = "buffer"   // 新的缓冲区记录
+	eventDone     = "done"     // 会话结束（完成或失败）
+)
+
+// sessionEvent 推送给订阅者的会话事件
+type sessionEvent struct {
+	Type string
+	Data interface{}
+}
+
+// sessionBroker 进程内的会话事件分发器
+type sessionBroker struct {
+	mu   sync.Mutex
+	subs map[string]map[chan sessionEvent]struct{}
+}
+
+// newSessionBroker 创建会话事件分发器
+func newSessionBroker() *sessionBroker {
+	return &sessionBroker{
+		subs: make(map[string]map[chan sessionEvent]struct{}),
+	}
+}
+
+// subscribe 订阅某个会话的事件，返回事件通道和取消订阅函数
+func (b *sessionBroker) subscribe(sessionID string) (<-chan sessionEvent, func()) {
+	ch := make(chan sessionEvent, 256)
+
+	b.mu.Lock()
+	if b.subs[sessionID] == nil {
+		b.subs[sessionID] = make(map[chan sessionEvent]struct{})
+	}
+	b.subs[sessionID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subs[sessionID], ch)
+		if len(b.subs[sessionID]) == 0 {
+			delete(b.subs, sessionID)
+		}
+		b.mu.Unlock()
+	}
+}
+
+// publish 向某个会话的所有订阅者推送事件
+// 订阅者处理过慢时丢弃事件，客户端可通过缓冲区列表接口补齐；
+// 结束事件不丢弃，通道已满时挤掉最早的事件，保证订阅者能收到 done 并关闭连接
+func (b *sessionBroker) publish(sessionID string, ev sessionEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs[sessionID] {
+		for !trySend(ch, ev) && ev.Type == eventDone {
+			select {
+			case <-ch:
+			default:
+			}
+		}
+	}
+}
+
+// trySend 非阻塞发送，通道已满时返回 false
+func trySend(ch chan sessionEvent, ev sessionEvent) bool {
+	select {
+	case ch <- ev:
+		return true
+	default:
+		return false
+	}
+}
+
+// startWorkers 启动后台检索工作池
+func (h *Handler) startWorkers() {
+	for i := 0; i < searchWorkerCount; i++ {
+		go func() {
+			for job := range h.jobs {
+				h.runSearchJob(job)
+			}
+		}()
+	}
+}
+
+// enqueueSearch 将检索任务放入队列，队列已满时立即返回错误
+func (h *Handler) enqueueSearch(job searchJob) error {
+	select {
+	case h.jobs <- job:
+		return nil
+	default:
+		return errSearchQueueFull
+	}
+}
+
+// runSearchJob 执行一次后台检索：调用数据源、写入缓冲区并推送进度
+func (h *Handler) runSearchJob(job searchJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), searchJobTimeout)
+	defer cancel()
+
+	h.updateSession(job.SessionID, map[string]interface{}{"state": SessionStateRunning})
+
+	rawResults, usage, err := h.performSearch(ctx, job.Request)
+	if err != nil {
+		h.failSession(job.SessionID, err)
+		return
+	}
+
+	h.updateSession(job.SessionID, map[string]interface{}{
+		"total_count": len(rawResults),
+		"tokens_used": usage.TotalTokens,
+	})
+
+	for i, raw := range rawResults {
+		bufferID, err := h.saveToBuffer(job.SessionID, job.UserID, raw)
+		if err != nil {
+			h.failSession(job.SessionID, err)
+			return
+		}
+
+		h.updateSession(job.SessionID, map[string]interface{}{"fetched_count": i + 1})
+
+		var buffer SearchBuffer
+		if err := h.db.First(&buffer, bufferID).Error; err == nil {
+			h.events.publish(job.SessionID, sessionEvent{Type: eventBuffer, Data: buffer.ToPreview()})
+		}
+	}
+
+	// 按实际结果条数结算积分，多冻结的部分退回
+	cost := h.captureSearch(job, len(rawResults))
+
+	fields := map[string]interface{}{"cost": cost}
+	if job.MonitorID != 0 {
+		newCount, err := h.recordMonitorHits(job.MonitorID, job.SessionID)
+		if err != nil {
+			log.Printf("Failed to record hits for monitor %d: %v\n", job.MonitorID, err)
+		}
+		fields["new_count"] = newCount
+	}
+
+	now := time.Now()
+	fields["state"] = SessionStateDone
+	fields["finished_at"] = &now
+	h.updateSession(job.SessionID, fields)
+	h.updateHistoryResult(job.SessionID, len(rawResults), cost)
+	h.publishFinished(job.SessionID)
+}
+
+// updateSession 更新会话字段并推送进度事件
+func (h *Handler) updateSession(sessionID string, fields map[string]interface{}) {
+	if err := h.db.Model(&SearchSession{}).Where("id = ?", sessionID).Updates(fields).Error; err != nil {
+		log.Printf("Failed to update search session %s: %v\n", sessionID, err)
+		return
+	}
+
+	var session SearchSession
+	if err := h.db.First(&session, "id = ?", sessionID).Error; err == nil {
+		h.events.publish(sessionID, sessionEvent{Type: eventProgress, Data: session})
+	}
+}
+
+// failSession 将会话标记为失败，并全额退回冻结的积分
+func (h *Handler) failSession(sessionID string, cause error) {
+	log.Printf("Search session %s failed: %v\n", sessionID, cause)
+
+	h.releaseSession(sessionID, truncateError(cause))
+	h.failMonitorRun(sessionID, cause)
+
+	now := time.Now()
+	h.updateSession(sessionID, map[string]interface{}{
+		"state":       SessionStateFailed,
+		"error":       truncateError(cause),
+		"finished_at": &now,
+	})
+	h.publishFinished(sessionID)
+}
+
+// publishFinished 推送会话结束事件
+func (h *Handler) publishFinished(sessionID string) {
+	var session SearchSession
+	if err := h.db.First(&session, "id = ?", sessionID).Error; err == nil {
+		h.events.publish(sessionID, sessionEvent{Type: eventDone, Data: session})
+	}
+}
+
+// truncateError 截断错误信息以适配数据库字段长度
+func truncateError(err error) string {
+	msg := []rune(err.Error())
+	if len(msg) > 500 {
+		msg = msg[:500]
+	}
+	return string(msg)
+}
+
+// FailInterruptedSessions 将服务重启前未完成的会话标记为失败，并退回冻结的积分
+// 应在服务启动时调用一次
+func (h *Handler) FailInterruptedSessions() (int64, error) {
+	var interrupted []SearchSession
+	if err := h.db.Select("id").
+		Where("state IN ? AND reservation_id IS NOT NULL", []string{SessionStateQueued, SessionStateRunning}).
+		Find(&interrupted).Error; err != nil {
+		return 0, err
+	}
+	for _, session := range interrupted {
+		h.releaseSession(session.ID, "interrupted by server restart")
+	}
+
+	now := time.Now()
+	result := h.db.Model(&SearchSession{}).
+		Where("state IN ?", []string{SessionStateQueued, SessionStateRunning}).
+		Updates(map[string]interface{}{
+			"state":       SessionStateFailed,
+			"error":       "interrupted by server restart",
+			"finished_at": &now,
+		})
+	return result.RowsAffected, result.Error
+}
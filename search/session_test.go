@@ -0,0 +1,35 @@
+package search
+
+import "testing"
+
+func TestSessionBrokerKeepsDoneWhenFull(t *testing.T) {
+	b := newSessionBroker()
+	events, unsubscribe := b.subscribe("s1")
+	defer unsubscribe()
+
+	for i := 0; i < cap(events)+10; i++ {
+		b.publish("s1", sessionEvent{Type: eventBuffer, Data: i})
+	}
+	if len(events) != cap(events) {
+		t.Fatalf("buffered events = %d, want %d", len(events), cap(events))
+	}
+	b.publish("s1", sessionEvent{Type: eventDone})
+
+	var last sessionEvent
+	for len(events) > 0 {
+		last = <-events
+	}
+	if last.Type != eventDone {
+		t.Errorf("last event = %s, want %s", last.Type, eventDone)
+	}
+}
+
+func TestSessionBrokerUnsubscribe(t *testing.T) {
+	b := newSessionBroker()
+	_, unsubscribe := b.subscribe("s1")
+	unsubscribe()
+	if len(b.subs) != 0 {
+		t.Errorf("subs = %v, want empty", b.subs)
+	}
+	b.publish("s1", sessionEvent{Type: eventDone})
+}
@@ -0,0 +1,118 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"policy-backend/user"
+	"policy-backend/utils"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// sseKeepAliveInterval SSE 心跳间隔，防止代理断开空闲连接
+const sseKeepAliveInterval = 15 * time.Second
+
+// StreamSession 以 Server-Sent Events 推送会话进度
+// GET /api/search/sessions/:id/stream
+// 连接建立后先补发已写入的缓冲区记录，再实时推送新记录，会话结束后发送 done 事件并关闭连接
+func (h *Handler) StreamSession(c echo.Context) error {
+	sessionID := c.Param("id")
+
+	currentUser, ok := c.Get("user").(*user.User)
+	if !ok {
+		return utils.Fail(c, http.StatusUnauthorized, "User not authenticated")
+	}
+
+	var session SearchSession
+	if err := h.db.Where("id = ? AND user_id = ?", sessionID, currentUser.ID).First(&session).Error; err != nil {
+		return utils.Fail(c, http.StatusNotFound, "Session not found")
+	}
+
+	// 先订阅再补发，避免两者之间产生的事件丢失
+	events, unsubscribe := h.events.subscribe(sessionID)
+	defer unsubscribe()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	// 1. 补发当前进度与已写入的缓冲区记录
+	if err := h.db.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil
+	}
+	if err := writeSSE(res, eventProgress, session); err != nil {
+		return nil
+	}
+
+	var buffers []SearchBuffer
+	if err := h.db.Where("session_id = ?", sessionID).Order("id ASC").Find(&buffers).Error; err != nil {
+		return nil
+	}
+	sent := make(map[uint]bool, len(buffers))
+	for _, buffer := range buffers {
+		sent[buffer.ID] = true
+		if err := writeSSE(res, eventBuffer, buffer.ToPreview()); err != nil {
+			return nil
+		}
+	}
+
+	if session.IsFinished() {
+		_ = writeSSE(res, eventDone, session)
+		return nil
+	}
+
+	// 2. 实时推送
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-keepAlive.C:
+			// 兜底：会话已结束且没有待推送的事件（如由其他实例处理）时自行发送 done 并关闭
+			if len(events) == 0 && h.db.First(&session, "id = ?", sessionID).Error == nil && session.IsFinished() {
+				_ = writeSSE(res, eventDone, session)
+				return nil
+			}
+			if _, err := fmt.Fprint(res, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case ev := <-events:
+			if ev.Type == eventBuffer {
+				preview, ok := ev.Data.(*SearchBufferPreview)
+				if ok && sent[preview.ID] {
+					continue
+				}
+				if ok {
+					sent[preview.ID] = true
+				}
+			}
+			if err := writeSSE(res, ev.Type, ev.Data); err != nil {
+				return nil
+			}
+			if ev.Type == eventDone {
+				return nil
+			}
+		}
+	}
+}
+
+// writeSSE 写入一条 SSE 事件并立即刷新
+func writeSSE(res *echo.Response, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	res.Flush()
+	return nil
+}
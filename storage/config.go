@@ -0,0 +1,25 @@
+package storage
+
+// Config 文件存储配置
+type Config struct {
+	Backend  string `koanf:"storage_backend"`   // 存储后端：local 本地目录，s3 兼容 S3 协议的对象存储
+	LocalDir string `koanf:"storage_local_dir"` // 本地目录后端的根目录
+
+	// S3 兼容对象存储（AWS S3、MinIO 等）
+	S3Endpoint  string `koanf:"storage_s3_endpoint"`   // 服务地址，如 https://s3.us-east-1.amazonaws.com 或 http://localhost:9000
+	S3Region    string `koanf:"storage_s3_region"`     // 签名使用的区域
+	S3Bucket    string `koanf:"storage_s3_bucket"`     // 存储桶
+	S3AccessKey string `koanf:"storage_s3_access_key"` // 访问密钥 ID
+	S3SecretKey string `koanf:"storage_s3_secret_key"` // 访问密钥
+	S3PathStyle bool   `koanf:"storage_s3_path_style"` // 使用路径风格地址（endpoint/bucket/key），MinIO 通常需要开启
+}
+
+// DefaultConfig 返回文件存储的默认配置
+func DefaultConfig() Config {
+	return Config{
+		Backend:     BackendLocal,
+		LocalDir:    "data",
+		S3Region:    "us-east-1",
+		S3PathStyle: true,
+	}
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// LocalStorage 本地目录存储，对象键映射为根目录下的相对路径
+type LocalStorage struct {
+	root string
+}
+
+// NewLocal 创建本地目录存储，目录在首次写入时创建
+func NewLocal(root string) *LocalStorage {
+	return &LocalStorage{root: root}
+}
+
+// Backend 实现 Storage 接口
+func (s *LocalStorage) Backend() string {
+	return BackendLocal
+}
+
+// path 对象键对应的文件路径
+func (s *LocalStorage) path(key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put 实现 Storage 接口
+// 先写入同目录下的临时文件再重命名，读取方不会看到写了一半的文件
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p), filepath.Base(p)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+// Get 实现 Storage 接口
+func (s *LocalStorage) Get(ctx context.Context, key string) (Object, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, localError(err)
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &localObject{File: f, info: localInfo(key, stat)}, nil
+}
+
+// Stat 实现 Storage 接口
+func (s *LocalStorage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(p)
+	if err != nil {
+		return nil, localError(err)
+	}
+	info := localInfo(key, stat)
+	return &info, nil
+}
+
+// Delete 实现 Storage 接口
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// PresignedURL 实现 Storage 接口，本地目录不支持
+func (s *LocalStorage) PresignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return "", ErrPresignUnsupported
+}
+
+// localObject 本地文件对象
+type localObject struct {
+	*os.File
+	info ObjectInfo
+}
+
+// Info 实现 Object 接口
+func (o *localObject) Info() ObjectInfo {
+	return o.info
+}
+
+// localInfo 由文件信息构造对象元信息，内容类型按扩展名推断
+func localInfo(key string, stat fs.FileInfo) ObjectInfo {
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return ObjectInfo{
+		Key:         key,
+		Size:        stat.Size(),
+		ContentType: contentType,
+		ModTime:     stat.ModTime(),
+	}
+}
+
+// localError 将文件不存在转换为 ErrNotFound
+func localError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
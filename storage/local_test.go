@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	s := NewLocal(root)
+	content := "%PDF-1.4 hello world"
+
+	if err := s.Put(ctx, "pdf/1024.pdf", strings.NewReader(content), int64(len(content)), "application/pdf"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "pdf", "1024.pdf")); err != nil {
+		t.Fatalf("object file not created: %v", err)
+	}
+
+	info, err := s.Stat(ctx, "pdf/1024.pdf")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Key != "pdf/1024.pdf" || info.Size != int64(len(content)) || info.ContentType != "application/pdf" {
+		t.Errorf("Stat() = %+v", info)
+	}
+
+	obj, err := s.Get(ctx, "pdf/1024.pdf")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	data, err := io.ReadAll(obj)
+	if err != nil || string(data) != content {
+		t.Errorf("read = %q, %v, want %q", data, err, content)
+	}
+	if _, err := obj.Seek(9, io.SeekStart); err != nil {
+		t.Fatalf("Seek() error = %v", err)
+	}
+	data, _ = io.ReadAll(obj)
+	if string(data) != "hello world" {
+		t.Errorf("read after seek = %q", data)
+	}
+	obj.Close()
+
+	// 覆盖写入
+	if err := s.Put(ctx, "pdf/1024.pdf", strings.NewReader("new"), 3, ""); err != nil {
+		t.Fatalf("Put() overwrite error = %v", err)
+	}
+	if info, _ := s.Stat(ctx, "pdf/1024.pdf"); info.Size != 3 {
+		t.Errorf("size after overwrite = %d, want 3", info.Size)
+	}
+
+	if err := s.Delete(ctx, "pdf/1024.pdf"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.Get(ctx, "pdf/1024.pdf"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after delete error = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Stat(ctx, "pdf/1024.pdf"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat() after delete error = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete(ctx, "pdf/1024.pdf"); err != nil {
+		t.Errorf("Delete() missing object error = %v, want nil", err)
+	}
+
+	// 临时文件不应残留
+	entries, _ := os.ReadDir(filepath.Join(root, "pdf"))
+	if len(entries) != 0 {
+		t.Errorf("leftover files: %v", entries)
+	}
+}
+
+func TestLocalInvalidKeys(t *testing.T) {
+	ctx := context.Background()
+	s := NewLocal(t.TempDir())
+	for _, key := range []string{"", "/etc/passwd", "../secret", "pdf/../../x", `pdf\1.pdf`, "."} {
+		if err := s.Put(ctx, key, strings.NewReader("x"), 1, ""); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+		if _, err := s.Get(ctx, key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Get(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
+
+func TestLocalPresignUnsupported(t *testing.T) {
+	s := NewLocal(t.TempDir())
+	if _, err := s.PresignedURL(context.Background(), "a.pdf", time.Minute); !errors.Is(err, ErrPresignUnsupported) {
+		t.Errorf("PresignedURL() error = %v, want ErrPresignUnsupported", err)
+	}
+}
@@ -0,0 +1,305 @@
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// s3RequestTimeout 非读取类请求（HEAD/PUT/DELETE）的超时；读取对象内容不设整体超时，由调用方的 ctx 控制
+const s3RequestTimeout = 5 * time.Minute
+
+// S3Options S3 兼容存储的连接参数
+type S3Options struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool // true: endpoint/bucket/key；false: bucket.endpoint/key
+	Client    *http.Client
+}
+
+// S3Storage S3 兼容的对象存储（AWS S3、MinIO 等），使用 Signature V4 签名
+type S3Storage struct {
+	endpoint  *url.URL
+	bucket    string
+	signer    sigV4Signer
+	pathStyle bool
+	client    *http.Client
+}
+
+// NewS3 创建 S3 兼容存储
+func NewS3(opts S3Options) (*S3Storage, error) {
+	if opts.Endpoint == "" || opts.Bucket == "" {
+		return nil, errors.New("storage: s3 endpoint and bucket are required")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(opts.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("storage: invalid s3 endpoint %q", opts.Endpoint)
+	}
+	region := opts.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &S3Storage{
+		endpoint:  endpoint,
+		bucket:    opts.Bucket,
+		signer:    sigV4Signer{accessKey: opts.AccessKey, secretKey: opts.SecretKey, region: region, service: "s3", now: time.Now},
+		pathStyle: opts.PathStyle,
+		client:    client,
+	}, nil
+}
+
+// Backend 实现 Storage 接口
+func (s *S3Storage) Backend() string {
+	return BackendS3
+}
+
+// objectURL 对象地址，路径按 S3 规则逐段编码
+func (s *S3Storage) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	prefix := strings.TrimRight(u.Path, "/")
+	if s.pathStyle {
+		prefix += "/" + s.bucket
+	} else {
+		u.Host = s.bucket + "." + u.Host
+	}
+	u.Path = prefix + "/" + key
+	u.RawPath = uriEncode(prefix, false) + "/" + uriEncode(key, false)
+	return &u
+}
+
+// newRequest 构造已签名的请求
+func (s *S3Storage) newRequest(ctx context.Context, method, key string, body io.Reader, size int64, header http.Header) (*http.Request, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	payloadHash := emptyPayloadHash
+	if body != nil {
+		// 上传内容不参与签名，避免为计算哈希而缓存整个文件
+		req.ContentLength = size
+		payloadHash = unsignedPayload
+	}
+	s.signer.sign(req, payloadHash)
+	return req, nil
+}
+
+// do 发送请求，非 2xx 响应转换为错误并关闭响应体
+func (s *S3Storage) do(req *http.Request) (*http.Response, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	var apiErr struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if xml.Unmarshal(body, &apiErr) == nil && apiErr.Code != "" {
+		return nil, fmt.Errorf("storage: s3 %s %s: %s: %s", req.Method, req.URL.Path, apiErr.Code, apiErr.Message)
+	}
+	return nil, fmt.Errorf("storage: s3 %s %s: unexpected status %d", req.Method, req.URL.Path, resp.StatusCode)
+}
+
+// Put 实现 Storage 接口
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	ctx, cancel := context.WithTimeout(ctx, s3RequestTimeout)
+	defer cancel()
+
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	// 内容长度为 0 时 http.Client 不会发送 body，显式使用 http.NoBody
+	if size == 0 {
+		r = http.NoBody
+	}
+	req, err := s.newRequest(ctx, http.MethodPut, key, r, size, header)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Stat 实现 Storage 接口
+func (s *S3Storage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, s3RequestTimeout)
+	defer cancel()
+
+	req, err := s.newRequest(ctx, http.MethodHead, key, nil, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	info := s3Info(key, resp)
+	return &info, nil
+}
+
+// Get 实现 Storage 接口
+// 返回的对象按需发起 Range 请求读取，Seek 后从新位置重新请求
+func (s *S3Storage) Get(ctx context.Context, key string) (Object, error) {
+	info, err := s.Stat(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return &s3Object{ctx: ctx, storage: s, info: *info}, nil
+}
+
+// Delete 实现 Storage 接口
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	ctx, cancel := context.WithTimeout(ctx, s3RequestTimeout)
+	defer cancel()
+
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil, 0, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// PresignedURL 实现 Storage 接口，生成 GET 预签名地址（最长 7 天）
+func (s *S3Storage) PresignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	if expires <= 0 || expires > 7*24*time.Hour {
+		return "", fmt.Errorf("storage: presign expiry must be between 1s and 7 days")
+	}
+	return s.signer.presign(http.MethodGet, s.objectURL(key), expires), nil
+}
+
+// s3Info 由响应头构造对象元信息
+func s3Info(key string, resp *http.Response) ObjectInfo {
+	info := ObjectInfo{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+		ETag:        strings.Trim(resp.Header.Get("ETag"), `"`),
+	}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = t
+	}
+	return info
+}
+
+// s3Object S3 对象的可定位读取器，从当前位置发起 Range 请求读取剩余内容
+type s3Object struct {
+	ctx     context.Context
+	storage *S3Storage
+	info    ObjectInfo
+	offset  int64
+	body    io.ReadCloser
+}
+
+// Info 实现 Object 接口
+func (o *s3Object) Info() ObjectInfo {
+	return o.info
+}
+
+// Read 实现 io.Reader
+func (o *s3Object) Read(p []byte) (int, error) {
+	if o.offset >= o.info.Size {
+		return 0, io.EOF
+	}
+	if o.body == nil {
+		header := http.Header{}
+		header.Set("Range", "bytes="+strconv.FormatInt(o.offset, 10)+"-")
+		req, err := o.storage.newRequest(o.ctx, http.MethodGet, o.info.Key, nil, 0, header)
+		if err != nil {
+			return 0, err
+		}
+		resp, err := o.storage.do(req)
+		if err != nil {
+			return 0, err
+		}
+		o.body = resp.Body
+	}
+
+	n, err := o.body.Read(p)
+	o.offset += int64(n)
+	if errors.Is(err, io.EOF) && o.offset < o.info.Size {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// Seek 实现 io.Seeker，位置变化时丢弃当前响应
+func (o *s3Object) Seek(offset int64, whence int) (int64, error) {
+	var pos int64
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = o.offset + offset
+	case io.SeekEnd:
+		pos = o.info.Size + offset
+	default:
+		return 0, errors.New("storage: invalid whence")
+	}
+	if pos < 0 {
+		return 0, errors.New("storage: negative position")
+	}
+	if pos != o.offset && o.body != nil {
+		o.body.Close()
+		o.body = nil
+	}
+	o.offset = pos
+	return pos, nil
+}
+
+// Close 实现 io.Closer
+func (o *s3Object) Close() error {
+	if o.body == nil {
+		return nil
+	}
+	err := o.body.Close()
+	o.body = nil
+	return err
+}
@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	fakeAccessKey = "AKIDEXAMPLE"
+	fakeSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+)
+
+// fakeS3Object 假 S3 服务中保存的对象
+type fakeS3Object struct {
+	data        []byte
+	contentType string
+	modTime     time.Time
+}
+
+// fakeS3 最小的 S3 兼容服务：校验 SigV4 签名，支持 PUT、HEAD、GET（含 Range）与 DELETE
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]fakeS3Object // 键为 host + path
+	hosts   []string
+	ranges  []string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !f.verify(r) {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>SignatureDoesNotMatch</Code><Message>bad signature</Message></Error>`)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.hosts = append(f.hosts, r.Host)
+	id := r.Host + r.URL.EscapedPath()
+
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[id] = fakeS3Object{data: data, contentType: r.Header.Get("Content-Type"), modTime: time.Now()}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodHead, http.MethodGet:
+		obj, ok := f.objects[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		sum := md5.Sum(obj.data)
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+		w.Header().Set("Content-Type", obj.contentType)
+		w.Header().Set("Last-Modified", obj.modTime.UTC().Format(http.TimeFormat))
+		data, status := obj.data, http.StatusOK
+		if rng := r.Header.Get("Range"); rng != "" {
+			f.ranges = append(f.ranges, rng)
+			start, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rng, "bytes="), "-"))
+			data, status = data[start:], http.StatusPartialContent
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.WriteHeader(status)
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	case http.MethodDelete:
+		delete(f.objects, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// verify 用相同的密钥按请求中的时间重新签名，比较 Authorization 头
+func (f *fakeS3) verify(r *http.Request) bool {
+	t, err := time.Parse(sigV4TimeFormat, r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return false
+	}
+	got := r.Header.Get("Authorization")
+	if !strings.Contains(got, "Credential="+fakeAccessKey+"/") {
+		return false
+	}
+
+	clone := r.Clone(context.Background())
+	clone.URL.Host = r.Host
+	signer := sigV4Signer{accessKey: fakeAccessKey, secretKey: fakeSecretKey, region: "us-east-1", service: "s3",
+		now: func() time.Time { return t }}
+	signer.sign(clone, r.Header.Get("X-Amz-Content-Sha256"))
+	return clone.Header.Get("Authorization") == got
+}
+
+// newFakeS3 启动假 S3 服务，返回的客户端把所有主机名都连到该服务，以便测试虚拟主机风格地址
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server, *http.Client) {
+	f := &fakeS3{objects: make(map[string]fakeS3Object)}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	addr := srv.Listener.Addr().String()
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}
+	return f, srv, &http.Client{Transport: transport}
+}
+
+func TestS3Storage(t *testing.T) {
+	for _, pathStyle := range []bool{true, false} {
+		name := "virtual host style"
+		if pathStyle {
+			name = "path style"
+		}
+		t.Run(name, func(t *testing.T) {
+			f, srv, client := newFakeS3(t)
+			s, err := NewS3(S3Options{
+				Endpoint:  srv.URL,
+				Bucket:    "policy",
+				AccessKey: fakeAccessKey,
+				SecretKey: fakeSecretKey,
+				PathStyle: pathStyle,
+				Client:    client,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+			key := "pdf/报告 1.pdf"
+			content := "%PDF-1.4 0123456789"
+
+			// put
+			if err := s.Put(ctx, key, strings.NewReader(content), int64(len(content)), "application/pdf"); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+			if err := s.Put(ctx, "empty.txt", strings.NewReader(""), 0, ""); err != nil {
+				t.Fatalf("Put() empty error = %v", err)
+			}
+
+			// stat
+			info, err := s.Stat(ctx, key)
+			if err != nil {
+				t.Fatalf("Stat() error = %v", err)
+			}
+			if info.Key != key || info.Size != int64(len(content)) || info.ContentType != "application/pdf" ||
+				info.ETag == "" || info.ModTime.IsZero() {
+				t.Errorf("Stat() = %+v", info)
+			}
+
+			// get
+			obj, err := s.Get(ctx, key)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			data, err := io.ReadAll(obj)
+			if err != nil || string(data) != content {
+				t.Errorf("read = %q, %v, want %q", data, err, content)
+			}
+
+			// range get：Seek 后从新位置发起 Range 请求
+			if _, err := obj.Seek(-10, io.SeekEnd); err != nil {
+				t.Fatalf("Seek() error = %v", err)
+			}
+			data, err = io.ReadAll(obj)
+			if err != nil || string(data) != "0123456789" {
+				t.Errorf("read after seek = %q, %v", data, err)
+			}
+			obj.Close()
+			if want := []string{"bytes=0-", "bytes=9-"}; strings.Join(f.ranges, ",") != strings.Join(want, ",") {
+				t.Errorf("ranges = %v, want %v", f.ranges, want)
+			}
+
+			// delete
+			if err := s.Delete(ctx, key); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if _, err := s.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get() after delete error = %v, want ErrNotFound", err)
+			}
+			if err := s.Delete(ctx, key); err != nil {
+				t.Errorf("Delete() missing object error = %v", err)
+			}
+
+			host := srv.Listener.Addr().String()
+			if !pathStyle {
+				host = "policy." + host
+			}
+			for _, h := range f.hosts {
+				if h != host {
+					t.Errorf("request host = %s, want %s", h, host)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestS3StorageErrors(t *testing.T) {
+	_, srv, client := newFakeS3(t)
+	s, err := NewS3(S3Options{Endpoint: srv.URL, Bucket: "policy", AccessKey: fakeAccessKey,
+		SecretKey: "wrong", PathStyle: true, Client: client})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	err = s.Put(ctx, "a.txt", strings.NewReader("x"), 1, "")
+	if err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("Put() with wrong secret error = %v, want SignatureDoesNotMatch", err)
+	}
+	if _, err := s.Get(ctx, "../a.txt"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Get() invalid key error = %v, want ErrInvalidKey", err)
+	}
+	if _, err := s.PresignedURL(ctx, "a.txt", 8*24*time.Hour); err == nil {
+		t.Error("PresignedURL() with expiry over 7 days error = nil")
+	}
+
+	for _, endpoint := range []string{"", "ftp://example.com", "not a url"} {
+		if _, err := NewS3(S3Options{Endpoint: endpoint, Bucket: "policy"}); err == nil {
+			t.Errorf("NewS3(%q) error = nil", endpoint)
+		}
+	}
+}
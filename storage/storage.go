@@ -0,0 +1,98 @@
+// Package storage 文件存储抽象
+//
+// 附件、导出文件等二进制对象通过 Storage 接口读写，按配置选择本地目录或 S3 兼容的对象存储，
+// 业务代码只关心对象键（如 pdf/1024.pdf），不关心文件实际存放在哪里。
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// 存储后端名称
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+var (
+	// ErrNotFound 对象不存在
+	ErrNotFound = errors.New("storage: object not found")
+	// ErrInvalidKey 对象键为空或包含 .. 等非法路径
+	ErrInvalidKey = errors.New("storage: invalid object key")
+	// ErrPresignUnsupported 后端不支持生成预签名 URL（如本地目录）
+	ErrPresignUnsupported = errors.New("storage: presigned url not supported")
+)
+
+// ObjectInfo 对象元信息
+type ObjectInfo struct {
+	Key         string    `json:"key"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type"`
+	ModTime     time.Time `json:"mod_time"`
+	ETag        string    `json:"etag,omitempty"`
+}
+
+// Object 打开的对象，支持 Seek 以便按 Range 分段读取
+type Object interface {
+	io.ReadSeekCloser
+	// Info 对象元信息
+	Info() ObjectInfo
+}
+
+// Storage 对象存储接口
+type Storage interface {
+	// Backend 返回后端名称
+	Backend() string
+	// Put 写入对象，已存在时覆盖；size 为内容字节数
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get 打开对象，调用方负责关闭；对象不存在时返回 ErrNotFound
+	Get(ctx context.Context, key string) (Object, error)
+	// Stat 获取对象元信息；对象不存在时返回 ErrNotFound
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// Delete 删除对象，对象不存在时不报错
+	Delete(ctx context.Context, key string) error
+	// PresignedURL 生成有效期为 expires 的临时下载地址，不支持时返回 ErrPresignUnsupported
+	PresignedURL(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+// New 按配置创建存储后端
+func New(cfg *Config) (Storage, error) {
+	switch cfg.Backend {
+	case "", BackendLocal:
+		return NewLocal(cfg.LocalDir), nil
+	case BackendS3:
+		return NewS3(S3Options{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PathStyle: cfg.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("storage: unknown backend %q", cfg.Backend)
+	}
+}
+
+// cleanKey 校验并规范化对象键：使用 / 分隔，不允许为空、以 / 开头或包含 ..
+func cleanKey(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == ".." {
+			return "", ErrInvalidKey
+		}
+	}
+	cleaned := path.Clean(key)
+	if cleaned == "." {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
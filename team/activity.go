@@ -0,0 +1,153 @@
+package team
+
+import (
+	"errors"
+	"net/http"
+	"policy-backend/user"
+	"policy-backend/utils"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// 团队角色
+const (
+	RoleAdmin  = "admin"  // 管理员
+	RoleMember = "member" // 成员
+)
+
+// contributorRoles 可以向团队情报池导入情报的角色
+var contributorRoles = map[string]bool{
+	RoleAdmin:  true,
+	RoleMember: true,
+}
+
+// 团队归属检查错误
+var (
+	ErrTeamNotFound     = errors.New("team not found")
+	ErrNotTeamMember    = errors.New("not a member of this team")
+	ErrTeamRoleRejected = errors.New("team role cannot contribute intelligence")
+)
+
+// CheckContributor 检查用户是否为团队成员且角色允许向团队情报池导入情报
+func CheckContributor(db *gorm.DB, teamID, userID uint) error {
+	var t user.Team
+	if err := db.Select("id").First(&t, teamID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTeamNotFound
+		}
+		return err
+	}
+
+	var member user.TeamMember
+	if err := db.Where("team_id = ? AND user_id = ?", teamID, userID).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotTeamMember
+		}
+		return err
+	}
+	if !contributorRoles[member.Role] {
+		return ErrTeamRoleRejected
+	}
+	return nil
+}
+
+// 团队动态类型
+const (
+	ActivityImport = "import" // 从检索结果导入情报
+)
+
+// Activity 团队动态
+type Activity struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	TeamID         uint      `json:"team_id" gorm:"not null;index:idx_team_activity"`
+	UserID         uint      `json:"user_id" gorm:"not null;index;comment:操作人ID"`
+	Action         string    `json:"action" gorm:"type:varchar(20);not null;comment:动态类型:import导入"`
+	IntelligenceID uint      `json:"intelligence_id,omitempty" gorm:"index;comment:相关情报ID"`
+	Title          string    `json:"title" gorm:"type:varchar(500);comment:情报标题（冗余，情报删除后仍可显示）"`
+	SessionID      string    `json:"session_id,omitempty" gorm:"type:varchar(64);comment:来源搜索会话ID"`
+	BufferID       uint      `json:"buffer_id,omitempty" gorm:"comment:来源缓冲区记录ID"`
+	CreatedAt      time.Time `json:"created_at" gorm:"index:idx_team_activity"`
+}
+
+// TableName 指定表名
+func (Activity) TableName() string {
+	return "team_activities"
+}
+
+// RecordImport 记录从搜索会话导入情报的团队动态，可在事务中调用
+func RecordImport(tx *gorm.DB, teamID, userID, intelligenceID uint, title, sessionID string, bufferID uint) error {
+	return tx.Create(&Activity{
+		TeamID:         teamID,
+		UserID:         userID,
+		Action:         ActivityImport,
+		IntelligenceID: intelligenceID,
+		Title:          title,
+		SessionID:      sessionID,
+		BufferID:       bufferID,
+	}).Error
+}
+
+// ActivityWithUser 包含操作人信息的团队动态
+type ActivityWithUser struct {
+	Activity
+	Username string `json:"username"`
+	Nickname string `json:"nickname"`
+}
+
+// defaultActivityPageSize 团队动态默认每页数量
+const defaultActivityPageSize = 20
+
+// GetTeamActivities 获取团队动态
+// GET /api/teams/:id/activities
+// 按时间倒序分页返回，可按 action 过滤
+func (h *Handler) GetTeamActivities(c echo.Context) error {
+	teamID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid team ID")
+	}
+
+	// 检查用户是否是团队成员
+	if err := h.checkTeamMembership(c, uint(teamID)); err != nil {
+		return err
+	}
+
+	var query ActivityQuery
+	if err := c.Bind(&query); err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid parameters")
+	}
+	if err := utils.ValidateRequest(c, &query); err != nil {
+		return err
+	}
+	query.normalize(defaultActivityPageSize)
+
+	db := h.db.Model(&Activity{}).Where("team_activities.team_id = ?", teamID)
+	if query.Action != "" {
+		db = db.Where("team_activities.action = ?", query.Action)
+	}
+
+	var total int64
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to count team activities")
+	}
+
+	activities := []ActivityWithUser{}
+	if err := db.Select("team_activities.*, users.username, users.nickname").
+		Joins("LEFT JOIN users ON users.id = team_activities.user_id").
+		Order("team_activities.created_at DESC, team_activities.id DESC").
+		Offset(query.offset()).
+		Limit(query.PageSize).
+		Scan(&activities).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to fetch team activities")
+	}
+
+	return utils.Success(c, map[string]interface{}{
+		"total":      total,
+		"page":       query.Page,
+		"page_size":  query.PageSize,
+		"count":      len(activities),
+		"activities": activities,
+	})
+}
@@ -2,6 +2,7 @@ package team
 
 import (
 	"net/http"
+	"policy-backend/intelligence"
 	"policy-backend/user"
 	"policy-backend/utils"
 	"strconv"
@@ -10,6 +11,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// defaultIntelligencePageSize 团队情报池默认每页数量
+const defaultIntelligencePageSize = 20
+
 // Handler 团队处理器
 type Handler struct {
 	db *gorm.DB
@@ -50,7 +54,7 @@ func (h *Handler) GetMyTeams(c echo.Context) error {
 		teams = append(teams, TeamWithMembers{
 			Team:               &t,
 			MembersCount:       int(membersCount),
-			IntelligencesCount: 0, // TODO: 后续实现
+			IntelligencesCount: h.countTeamIntelligences(t.ID),
 		})
 	}
 
@@ -144,7 +148,7 @@ func (h *Handler) GetTeam(c echo.Context) error {
 	result := TeamWithMembers{
 		Team:               &team,
 		MembersCount:       len(members),
-		IntelligencesCount: 0, // TODO: 后续实现
+		IntelligencesCount: h.countTeamIntelligences(team.ID),
 		Members:            members,
 	}
 
@@ -325,16 +329,63 @@ func (h *Handler) GetTeamIntelligences(c echo.Context) error {
 		return err
 	}
 
-	// TODO: 实现团队情报池查询
-	// 需要查询 permissions 表中 subject_type = 'team' 且 subject_id = teamID 的资源
+	var query PageQuery
+	if err := c.Bind(&query); err != nil {
+		return utils.Fail(c, http.StatusBadRequest, "Invalid parameters")
+	}
+	if err := utils.ValidateRequest(c, &query); err != nil {
+		return err
+	}
+	query.normalize(defaultIntelligencePageSize)
+
+	// 团队情报池即 permissions 表中主体为该团队的情报
+	db := teamIntelligencePermissions(h.db, uint(teamID))
 
-	// 占位返回
+	var total int64
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to count team intelligences")
+	}
+
+	var permissions []intelligence.Permission
+	if err := db.Select("permissions.*").Order("permissions.granted_at DESC, permissions.id DESC").
+		Offset(query.offset()).
+		Limit(query.PageSize).
+		Find(&permissions).Error; err != nil {
+		return utils.Error(c, http.StatusInternalServerError, "Failed to fetch team intelligences")
+	}
+
+	ids := make([]uint, 0, len(permissions))
+	for _, p := range permissions {
+		ids = append(ids, p.ResourceID)
+	}
+	var records []intelligence.Intelligence
+	if len(ids) > 0 {
+		if err := h.db.Where("id IN ?", ids).Find(&records).Error; err != nil {
+			return utils.Error(c, http.StatusInternalServerError, "Failed to fetch team intelligences")
+		}
+	}
+	byID := make(map[uint]*intelligence.Intelligence, len(records))
+	for i := range records {
+		byID[records[i].ID] = &records[i]
+	}
+
+	// 按授权时间排序，已删除的情报不返回
 	intelligences := []TeamIntelligence{}
+	for _, p := range permissions {
+		if record, ok := byID[p.ResourceID]; ok {
+			intelligences = append(intelligences, TeamIntelligence{
+				Intelligence:   record,
+				PermissionType: p.Action,
+			})
+		}
+	}
 
 	return utils.Success(c, map[string]interface{}{
+		"total":         total,
+		"page":          query.Page,
+		"page_size":     query.PageSize,
 		"count":         len(intelligences),
 		"intelligences": intelligences,
-		"message":       "团队情报池功能待实现",
 	})
 }
 
@@ -371,6 +422,22 @@ func (h *Handler) ImportIntelligences(c echo.Context) error {
 	})
 }
 
+// countTeamIntelligences 统计团队情报池中的情报数量
+func (h *Handler) countTeamIntelligences(teamID uint) int {
+	var count int64
+	teamIntelligencePermissions(h.db, teamID).Count(&count)
+	return int(count)
+}
+
+// teamIntelligencePermissions 团队情报池的权限记录，只包含仍然存在的情报
+// 删除情报时会一并删除其权限记录，关联情报表以排除此前遗留的记录
+func teamIntelligencePermissions(db *gorm.DB, teamID uint) *gorm.DB {
+	return db.Model(&intelligence.Permission{}).
+		Joins("JOIN intelligences ON intelligences.id = permissions.resource_id").
+		Where("permissions.resource_type = ? AND permissions.subject_type = ? AND permissions.subject_id = ?",
+			intelligence.ResourceTypeIntelligence, intelligence.SubjectTypeTeam, teamID)
+}
+
 // checkTeamMembership 检查用户是否是团队成员
 func (h *Handler) checkTeamMembership(c echo.Context, teamID uint) error {
 	currentUser, ok := c.Get("user").(*user.User)
@@ -45,3 +45,30 @@ type UpdateMemberRoleRequest struct {
 type ImportIntelligencesRequest struct {
 	IntelligenceIDs []uint `json:"intelligence_ids" validate:"required,min=1,dive,min=1"`
 }
+
+// PageQuery 通用分页参数
+type PageQuery struct {
+	Page     int `query:"page" validate:"omitempty,min=1"`
+	PageSize int `query:"page_size" validate:"omitempty,min=1,max=100"`
+}
+
+// ActivityQuery 团队动态查询参数
+type ActivityQuery struct {
+	PageQuery
+	Action string `query:"action" validate:"omitempty,oneof=import"` // 按动态类型过滤
+}
+
+// normalize 填充默认页码与每页数量
+func (q *PageQuery) normalize(defaultSize int) {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 {
+		q.PageSize = defaultSize
+	}
+}
+
+// offset 计算偏移量
+func (q *PageQuery) offset() int {
+	return (q.Page - 1) * q.PageSize
+}
@@ -17,4 +17,5 @@ func RegisterRoutes(g *echo.Group, h *Handler) {
 	g.PUT("/:id/members/:uid", h.UpdateMemberRole)      // 修改成员角色
 	g.GET("/:id/intelligences", h.GetTeamIntelligences) // 获取团队情报池
 	g.POST("/:id/import", h.ImportIntelligences)        // 批量导入情报到团队
+	g.GET("/:id/activities", h.GetTeamActivities)       // 团队动态（谁从哪个搜索会话导入了什么）
 }
@@ -79,3 +79,28 @@ type PointsTransaction struct {
 func (PointsTransaction) TableName() string {
 	return "points_transactions"
 }
+
+// 积分预授权状态
+const (
+	ReservationHeld     = "held"     // 已冻结，等待结算
+	ReservationCaptured = "captured" // 已结算（多冻结的部分已退回）
+	ReservationReleased = "released" // 已全额退回
+)
+
+// PointsReservation 积分预授权记录
+// 冻结时即扣减用户积分并记一笔 spend 交易，结算或释放时以 refund 交易退回多余部分
+type PointsReservation struct {
+	gorm.Model
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	Amount    int64      `json:"amount" gorm:"not null"`               // 冻结的积分
+	Captured  int64      `json:"captured" gorm:"default:0"`            // 实际结算的积分
+	Status    string     `json:"status" gorm:"not null;size:20;index"` // held, captured, released
+	Reference string     `json:"reference" gorm:"size:100;index"`      // 业务关联标识（如检索会话ID）
+	Metadata  string     `json:"metadata" gorm:"type:text"`            // JSON格式的额外信息
+	SettledAt *time.Time `json:"settled_at,omitempty"`                 // 结算或释放时间
+}
+
+// TableName 指定表名
+func (PointsReservation) TableName() string {
+	return "points_reservations"
+}
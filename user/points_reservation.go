@@ -0,0 +1,143 @@
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrReservationSettled 预授权已结算或已释放
+var ErrReservationSettled = errors.New("points reservation already settled")
+
+// PointsMetadata 积分交易的结构化附加信息，序列化为 JSON 存入 Metadata 字段
+type PointsMetadata map[string]interface{}
+
+// with 返回附加了额外字段的副本
+func (m PointsMetadata) with(key string, value interface{}) PointsMetadata {
+	out := make(PointsMetadata, len(m)+1)
+	for k, v := range m {
+		out[k] = v
+	}
+	out[key] = value
+	return out
+}
+
+// String 序列化为 JSON
+func (m PointsMetadata) String() string {
+	if len(m) == 0 {
+		return "{}"
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+// Reserve 冻结积分：检查余额并立即扣减，返回预授权记录
+// amount 为 0 时同样创建记录（不产生交易），便于调用方统一结算流程
+func (s *PointsTransactionService) Reserve(userID uint, amount int64, reference string, description string, meta PointsMetadata) (*PointsReservation, error) {
+	if amount < 0 {
+		return nil, errors.New("reservation amount must not be negative")
+	}
+
+	reservation := &PointsReservation{
+		UserID:    userID,
+		Amount:    amount,
+		Status:    ReservationHeld,
+		Reference: reference,
+		Metadata:  meta.String(),
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(reservation).Error; err != nil {
+			return err
+		}
+		if amount == 0 {
+			return nil
+		}
+		return addTransaction(tx, userID, -amount, "spend", description,
+			meta.with("stage", "reserve").with("reservation_id", reservation.ID).String())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// Capture 结算预授权：实际费用为 actual，多冻结的部分以 refund 交易退回
+// actual 超过冻结金额时按冻结金额结算
+func (s *PointsTransactionService) Capture(reservationID uint, actual int64, description string, meta PointsMetadata) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		reservation, err := settleReservation(tx, reservationID, ReservationCaptured, func(r *PointsReservation) int64 {
+			if actual < 0 {
+				actual = 0
+			}
+			if actual > r.Amount {
+				actual = r.Amount
+			}
+			return actual
+		})
+		if err != nil {
+			return err
+		}
+
+		refund := reservation.Amount - reservation.Captured
+		if refund == 0 {
+			return nil
+		}
+		return addTransaction(tx, reservation.UserID, refund, "refund", description,
+			meta.with("stage", "capture").with("reservation_id", reservation.ID).
+				with("reserved", reservation.Amount).with("captured", reservation.Captured).String())
+	})
+}
+
+// Release 释放预授权，全额退回冻结的积分
+func (s *PointsTransactionService) Release(reservationID uint, description string, meta PointsMetadata) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		reservation, err := settleReservation(tx, reservationID, ReservationReleased, func(*PointsReservation) int64 {
+			return 0
+		})
+		if err != nil {
+			return err
+		}
+
+		if reservation.Amount == 0 {
+			return nil
+		}
+		return addTransaction(tx, reservation.UserID, reservation.Amount, "refund", description,
+			meta.with("stage", "release").with("reservation_id", reservation.ID).String())
+	})
+}
+
+// settleReservation 将 held 状态的预授权置为终态，并发结算时只有一方成功
+func settleReservation(tx *gorm.DB, reservationID uint, status string, captured func(*PointsReservation) int64) (*PointsReservation, error) {
+	var reservation PointsReservation
+	if err := tx.First(&reservation, reservationID).Error; err != nil {
+		return nil, err
+	}
+	if reservation.Status != ReservationHeld {
+		return nil, ErrReservationSettled
+	}
+
+	now := time.Now()
+	reservation.Captured = captured(&reservation)
+	result := tx.Model(&PointsReservation{}).
+		Where("id = ? AND status = ?", reservationID, ReservationHeld).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"captured":   reservation.Captured,
+			"settled_at": &now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrReservationSettled
+	}
+
+	reservation.Status = status
+	reservation.SettledAt = &now
+	return &reservation, nil
+}
@@ -7,6 +7,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrInsufficientPoints 积分余额不足
+var ErrInsufficientPoints = errors.New("insufficient points")
+
 // PointsTransactionService 积分交易服务
 type PointsTransactionService struct {
 	db *gorm.DB
@@ -22,39 +25,52 @@ func NewPointsTransactionService(db *gorm.DB) *PointsTransactionService {
 // txType: 交易类型 (e.g., "earn", "spend", "refund")
 func (s *PointsTransactionService) AddTransaction(userID uint, amount int64, txType string, description string, metadata string) error {
 	return s.db.Transaction(func(tx *gorm.DB) error {
-		// 1. 如果是扣减积分，先检查余额
-		if amount < 0 {
-			var user User
-			if err := tx.First(&user, userID).Error; err != nil {
-				return err
-			}
-			// 转换为 int64 进行比较
-			if int64(user.Points)+amount < 0 {
-				return errors.New("insufficient points")
-			}
-		}
+		return addTransaction(tx, userID, amount, txType, description, metadata)
+	})
+}
 
-		// 2. 创建交易记录
-		transaction := &PointsTransaction{
-			UserID:      userID,
-			Amount:      amount,
-			Type:        txType,
-			Description: description,
-			Metadata:    metadata,
-			CreatedAt:   time.Now(),
-		}
-		if err := tx.Create(transaction).Error; err != nil {
+// addTransaction 在给定事务内记录积分交易并更新用户积分
+func addTransaction(tx *gorm.DB, userID uint, amount int64, txType string, description string, metadata string) error {
+	// 1. 如果是扣减积分，先检查余额
+	if amount < 0 {
+		var user User
+		if err := tx.First(&user, userID).Error; err != nil {
 			return err
 		}
-
-		// 3. 更新用户积分
-		if err := tx.Model(&User{}).Where("id = ?", userID).
-			Update("points", gorm.Expr("points + ?", amount)).Error; err != nil {
-			return err
+		// 转换为 int64 进行比较
+		if int64(user.Points)+amount < 0 {
+			return ErrInsufficientPoints
 		}
+	}
 
-		return nil
-	})
+	// 2. 创建交易记录
+	transaction := &PointsTransaction{
+		UserID:      userID,
+		Amount:      amount,
+		Type:        txType,
+		Description: description,
+		Metadata:    metadata,
+		CreatedAt:   time.Now(),
+	}
+	if err := tx.Create(transaction).Error; err != nil {
+		return err
+	}
+
+	// 3. 更新用户积分
+	// 扣减时带上余额条件，防止并发扣减导致积分为负
+	update := tx.Model(&User{}).Where("id = ?", userID)
+	if amount < 0 {
+		update = update.Where("points >= ?", -amount)
+	}
+	result := update.Update("points", gorm.Expr("points + ?", amount))
+	if result.Error != nil {
+		return result.Error
+	}
+	if amount < 0 && result.RowsAffected == 0 {
+		return ErrInsufficientPoints
+	}
+
+	return nil
 }
 
 // GetByUser 获取用户的积分交易记录
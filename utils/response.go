@@ -31,6 +31,15 @@ func Fail(c echo.Context, code int, msg string) error {
 	})
 }
 
+// FailWithData 失败返回 (业务错误)，附带错误详情
+func FailWithData(c echo.Context, code int, msg string, data interface{}) error {
+	return c.JSON(http.StatusOK, Response{
+		Code:    code,
+		Message: msg,
+		Data:    data,
+	})
+}
+
 // Error 错误返回 (系统错误) 此时状态码为 httpCode
 func Error(c echo.Context, httpCode int, msg string) error {
 	return c.JSON(httpCode, Response{